@@ -1,16 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/3n0ugh/kalenderium/internal/config"
+	accountpb "github.com/3n0ugh/kalenderium/pkg/account/pb"
 	"github.com/3n0ugh/kalenderium/pkg/calendar"
+	"github.com/3n0ugh/kalenderium/pkg/calendar/blob"
 	"github.com/3n0ugh/kalenderium/pkg/calendar/database"
 	"github.com/3n0ugh/kalenderium/pkg/calendar/pb"
+	"github.com/3n0ugh/kalenderium/pkg/calendar/reminder"
 	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/3n0ugh/kalenderium/pkg/calendar/trash"
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	"github.com/go-kit/log"
 	"github.com/oklog/oklog/pkg/group"
+	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
@@ -18,6 +25,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -38,9 +46,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	blobs, err := newBlobStore(conn, cfg)
+	if err != nil {
+		logger.Log("msg", "failed to create attachment store", "err", err)
+		os.Exit(1)
+	}
+
+	repo, err := repository.NewCalendarRepository(conn, blobs)
+	if err != nil {
+		logger.Log("msg", "failed to create calendar repository", "err", err)
+		os.Exit(1)
+	}
+
 	var (
-		repo         = repository.NewCalendarRepository(conn)
-		service      = calendar.NewService(repo)
+		service      = calendar.NewService(repo, cfg)
 		eps          = calendar.New(service)
 		grpcServer   = calendar.NewGRPCServer(eps)
 		healthServer = health.NewServer()
@@ -48,6 +67,18 @@ func main() {
 
 	var grpcAddr = net.JoinHostPort(cfg.GRPCHost, cfg.GRPCPort)
 
+	scheduler, err := newReminderScheduler(repo, cfg, logger)
+	if err != nil {
+		logger.Log("msg", "failed to create reminder scheduler", "err", err)
+		os.Exit(1)
+	}
+
+	purger, err := newTrashPurger(repo, cfg, logger)
+	if err != nil {
+		logger.Log("msg", "failed to create trash purger", "err", err)
+		os.Exit(1)
+	}
+
 	var g group.Group
 	{
 		// The gRPC listener
@@ -58,7 +89,9 @@ func main() {
 		}
 		g.Add(func() error {
 			logger.Log("transport", "gRPC", "addr", grpcAddr)
-			baseServer := grpc.NewServer(grpc.UnaryInterceptor(kitgrpc.Interceptor))
+			// The attachments are uploaded and downloaded in a single message
+			baseServer := grpc.NewServer(grpc.UnaryInterceptor(kitgrpc.Interceptor),
+				grpc.MaxRecvMsgSize(repository.MaxMessageSize), grpc.MaxSendMsgSize(repository.MaxMessageSize))
 			pb.RegisterCalendarServer(baseServer, grpcServer)
 			healthpb.RegisterHealthServer(baseServer, healthServer)
 			return baseServer.Serve(grpcListener)
@@ -66,6 +99,26 @@ func main() {
 			grpcListener.Close()
 		})
 	}
+	{
+		// The reminder scheduler
+		ctx, cancel := context.WithCancel(context.Background())
+		g.Add(func() error {
+			logger.Log("scheduler", "reminder")
+			return scheduler.Run(ctx)
+		}, func(error) {
+			cancel()
+		})
+	}
+	{
+		// The trash purger
+		ctx, cancel := context.WithCancel(context.Background())
+		g.Add(func() error {
+			logger.Log("purger", "trash")
+			return purger.Run(ctx)
+		}, func(error) {
+			cancel()
+		})
+	}
 	{
 		// This function just sits and waits for ctrl-C.
 		cancelInterrupt := make(chan struct{})
@@ -85,3 +138,85 @@ func main() {
 	logger.Log("exit", g.Run())
 
 }
+
+// newReminderScheduler creates the reminder scheduler with the configured notifiers. The
+// reminders of the methods which are not configured are logged.
+func newReminderScheduler(repo repository.CalendarRepository, cfg config.CalendarServiceConfigurations,
+	logger log.Logger) (*reminder.Scheduler, error) {
+	var opts reminder.Options
+	var err error
+	if cfg.ReminderInterval != "" {
+		if opts.Interval, err = time.ParseDuration(cfg.ReminderInterval); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.ReminderGrace != "" {
+		if opts.Grace, err = time.ParseDuration(cfg.ReminderGrace); err != nil {
+			return nil, err
+		}
+	}
+	opts.MaxAttempts = cfg.ReminderAttempts
+
+	logNotifier := reminder.NewLogNotifier(log.With(logger, "component", "reminder"))
+	notifiers := map[string]reminder.Notifier{
+		repository.ReminderEmail:   logNotifier,
+		repository.ReminderWebhook: logNotifier,
+	}
+
+	if cfg.SMTPHost != "" {
+		// The connection is made on the first request, so the account service may start later
+		accountAddr := net.JoinHostPort(cfg.AccountServiceHost, cfg.AccountServicePort)
+		conn, err := grpc.Dial(accountAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		emails := reminder.NewAccountEmails(accountpb.NewAccountClient(conn))
+
+		notifiers[repository.ReminderEmail] = reminder.NewSMTPNotifier(reminder.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUser,
+			Password: cfg.SMTPPass,
+			From:     cfg.SMTPFrom,
+		}, emails)
+	}
+	if cfg.WebhookURL != "" {
+		notifiers[repository.ReminderWebhook] = reminder.NewWebhookNotifier(cfg.WebhookURL)
+	}
+
+	return reminder.NewScheduler(repo, notifiers, opts, logger), nil
+}
+
+// newBlobStore creates the configured store of the attachment contents, GridFS in the database of
+// the events by default
+func newBlobStore(conn database.Connection, cfg config.CalendarServiceConfigurations) (repository.BlobStore, error) {
+	switch cfg.AttachmentStore {
+	case "", "gridfs":
+		return blob.NewGridFSStore(conn.DB().Database("kalenderium")), nil
+	case "file":
+		if cfg.AttachmentDir == "" {
+			return nil, errors.New("attachment_dir must be set for the file attachment store")
+		}
+		return blob.NewFileStore(cfg.AttachmentDir)
+	}
+	return nil, errors.Errorf("unknown attachment store %q", cfg.AttachmentStore)
+}
+
+// newTrashPurger creates the purger of the trash with the configured retention
+func newTrashPurger(repo repository.CalendarRepository, cfg config.CalendarServiceConfigurations,
+	logger log.Logger) (*trash.Purger, error) {
+	var opts trash.Options
+	var err error
+	if cfg.TrashRetention != "" {
+		if opts.Retention, err = time.ParseDuration(cfg.TrashRetention); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.TrashPurgeInterval != "" {
+		if opts.Interval, err = time.ParseDuration(cfg.TrashPurgeInterval); err != nil {
+			return nil, err
+		}
+	}
+
+	return trash.NewPurger(repo, opts, log.With(logger, "component", "trash")), nil
+}
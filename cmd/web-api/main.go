@@ -42,7 +42,7 @@ func main() {
 
 	service := webapi.NewWebApiService(calendarClient, accountClient)
 	eps := endpoints.New(service)
-	httpHandler := transport.NewHTTPHandler(eps)
+	httpHandler := transport.NewHTTPHandler(eps, cfg)
 
 	var g group.Group
 	{
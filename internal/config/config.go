@@ -30,6 +30,23 @@ type CalendarServiceConfigurations struct {
 	SSLMode      string `mapstructure:"db_ssl_mode"`
 	GRPCPort     string `mapstructure:"grpc_port"`
 	GRPCHost     string `mapstructure:"grpc_host"`
+	MaxPageSize  int    `mapstructure:"max_page_size"`
+
+	AccountServicePort string `mapstructure:"account_service_port"`
+	AccountServiceHost string `mapstructure:"account_service_host"`
+	ReminderInterval   string `mapstructure:"reminder_interval"`
+	ReminderGrace      string `mapstructure:"reminder_grace"`
+	ReminderAttempts   int    `mapstructure:"reminder_attempts"`
+	SMTPHost           string `mapstructure:"smtp_host"`
+	SMTPPort           string `mapstructure:"smtp_port"`
+	SMTPUser           string `mapstructure:"smtp_user"`
+	SMTPPass           string `mapstructure:"smtp_pass"`
+	SMTPFrom           string `mapstructure:"smtp_from"`
+	WebhookURL         string `mapstructure:"webhook_url"`
+	TrashRetention     string `mapstructure:"trash_retention"`
+	TrashPurgeInterval string `mapstructure:"trash_purge_interval"`
+	AttachmentStore    string `mapstructure:"attachment_store"`
+	AttachmentDir      string `mapstructure:"attachment_dir"`
 }
 
 type AccountServiceConfigurations struct {
@@ -56,6 +73,9 @@ type WebApiServiceConfigurations struct {
 	AccountServiceHost  string `mapstructure:"account_service_host"`
 	HTTPPort            string `mapstructure:"http_port"`
 	HTTPHost            string `mapstructure:"http_host"`
+	MaxBatchSize        int    `mapstructure:"max_batch_size"`
+	StreamHeartbeat     string `mapstructure:"stream_heartbeat"`
+	MaxStreamsPerUser   int    `mapstructure:"max_streams_per_user"`
 }
 
 var cfgReader *configReader
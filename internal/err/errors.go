@@ -52,3 +52,9 @@ func AuthenticationRequiredResponse(w http.ResponseWriter) {
 	message := "you must be authenticated to access this resource"
 	errorResponse(w, http.StatusUnauthorized, message)
 }
+
+func InvalidCredentialsResponse(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="kalenderium", charset="UTF-8"`)
+	message := "invalid or missing authentication credentials"
+	errorResponse(w, http.StatusUnauthorized, message)
+}
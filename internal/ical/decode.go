@@ -0,0 +1,296 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxLineBytes bounds the length of an unfolded content line
+const maxLineBytes = 1 << 20
+
+var ErrInvalidCalendar = errors.New("invalid iCalendar object")
+
+// property is a parsed content line
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode reads the VEVENTs of a VCALENDAR object. The VTIMEZONE components give the offsets of
+// the TZIDs which are not known time zones, the other components are skipped. The decoding stops
+// at the first malformed content line, but the VEVENTs with malformed property values are still
+// returned with their Err set.
+func Decode(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	zones := readTimeZones(lines)
+
+	var events []Event
+	var stack []string
+	var current *Event
+	var hasEnd bool
+	for i, line := range lines {
+		p, err := parseLine(line)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidCalendar, "line %d: %v", i+1, err)
+		}
+
+		switch p.name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(p.value))
+			if len(stack) == 1 && stack[0] != "VCALENDAR" {
+				return nil, errors.Wrap(ErrInvalidCalendar, "missing VCALENDAR")
+			}
+			if len(stack) == 2 && stack[1] == "VEVENT" {
+				current, hasEnd = &Event{}, false
+			}
+			continue
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(p.value) {
+				return nil, errors.Wrapf(ErrInvalidCalendar, "line %d: unexpected END:%s", i+1, p.value)
+			}
+			if len(stack) == 2 && current != nil {
+				current.complete(hasEnd)
+				events = append(events, *current)
+				current = nil
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		// The properties of the nested components like VALARM are skipped
+		if current == nil || len(stack) != 2 {
+			continue
+		}
+		if p.name == "DTEND" || p.name == "DURATION" {
+			hasEnd = true
+		}
+		if err = current.set(p, zones); err != nil && current.Err == nil {
+			current.Err = errors.Wrapf(err, "invalid %s", p.name)
+		}
+	}
+
+	if len(stack) != 0 {
+		return nil, errors.Wrap(ErrInvalidCalendar, "missing END:"+stack[len(stack)-1])
+	}
+	return events, nil
+}
+
+// unfold joins the folded content lines
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxLineBytes)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(ErrInvalidCalendar, err.Error())
+	}
+	return lines, nil
+}
+
+// parseLine splits the content line into the name, the parameters and the value
+func parseLine(line string) (property, error) {
+	p := property{params: map[string]string{}}
+
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return p, errors.New("missing property name")
+	}
+	p.name = strings.ToUpper(line[:end])
+
+	rest := line[end:]
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return p, errors.Errorf("invalid parameter of %s", p.name)
+		}
+		key := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			q := strings.IndexByte(rest[1:], '"')
+			if q < 0 {
+				return p, errors.Errorf("unterminated parameter of %s", p.name)
+			}
+			value, rest = rest[1:q+1], rest[q+2:]
+		} else {
+			n := strings.IndexAny(rest, ";:")
+			if n < 0 {
+				return p, errors.Errorf("missing value of %s", p.name)
+			}
+			value, rest = rest[:n], rest[n:]
+		}
+		p.params[key] = value
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return p, errors.Errorf("missing value of %s", p.name)
+	}
+	p.value = rest[1:]
+	return p, nil
+}
+
+// set sets the event property
+func (e *Event) set(p property, zones timeZones) error {
+	var err error
+	switch p.name {
+	case "UID":
+		e.UID = p.value
+	case "SUMMARY":
+		e.Summary = unescape(p.value)
+	case "DESCRIPTION":
+		e.Description = unescape(p.value)
+	case "COLOR":
+		e.Color = unescape(p.value)
+	case "TRANSP":
+		e.Transparent = strings.EqualFold(p.value, "TRANSPARENT")
+	case "CATEGORIES":
+		for _, value := range splitText(p.value) {
+			if category := unescape(value); category != "" {
+				e.Categories = append(e.Categories, category)
+			}
+		}
+	case "DTSTAMP":
+		e.Stamp, _, err = parseTime(p, zones)
+	case "DTSTART":
+		e.Start, e.AllDay, err = parseTime(p, zones)
+		// The times in the offsets of a VTIMEZONE are kept in UTC
+		e.TimeZone, _ = ianaZone(p.params["TZID"])
+	case "DTEND":
+		e.End, _, err = parseTime(p, zones)
+	case "DURATION":
+		e.duration, err = parseDuration(p.value)
+	case "RECURRENCE-ID":
+		e.RecurrenceId, _, err = parseTime(p, zones)
+	case "RRULE":
+		e.RRule = p.value
+	case "EXDATE", "RDATE":
+		var times []time.Time
+		for _, value := range strings.Split(p.value, ",") {
+			var t time.Time
+			if t, _, err = parseTime(property{params: p.params, value: value}, zones); err != nil {
+				return err
+			}
+			times = append(times, t)
+		}
+		if p.name == "EXDATE" {
+			e.ExDates = append(e.ExDates, times...)
+		} else {
+			e.RDates = append(e.RDates, times...)
+		}
+	}
+	return err
+}
+
+// complete fills the end of the event which has no DTEND, and checks the required properties
+func (e *Event) complete(hasEnd bool) {
+	if e.Err != nil {
+		return
+	}
+
+	switch {
+	case e.UID == "":
+		e.Err = errors.New("missing UID")
+	case e.Start.IsZero():
+		e.Err = errors.New("missing DTSTART")
+	case e.duration != 0:
+		e.End = e.Start.Add(e.duration)
+	case !hasEnd && e.AllDay:
+		e.End = e.Start.AddDate(0, 0, 1)
+	case !hasEnd:
+		e.End = e.Start
+	}
+}
+
+// parseTime parses the DATE-TIME or DATE value. The floating times are in UTC. It returns
+// true if the value is a DATE.
+func parseTime(p property, zones timeZones) (time.Time, bool, error) {
+	value := p.value
+	switch {
+	case p.params["VALUE"] == "DATE" || len(value) == 8:
+		t, err := time.ParseInLocation(dateFormat, value, time.UTC)
+		return t, true, err
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse(dateTimeFormat, value)
+		return t, false, err
+	case p.params["TZID"] != "":
+		t, err := parseLocalTime(value, p.params["TZID"], zones)
+		return t, false, err
+	default:
+		t, err := time.ParseInLocation(localDateTimeFormat, value, time.UTC)
+		return t, false, err
+	}
+}
+
+var durationRX = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses the DURATION value like P1W, P1D or PT1H30M
+func parseDuration(value string) (time.Duration, error) {
+	m := durationRX.FindStringSubmatch(value)
+	if m == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, errors.Errorf("invalid duration %q", value)
+	}
+
+	var d time.Duration
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+var unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// splitText splits the list of TEXT values at the commas which are not escaped
+func splitText(s string) []string {
+	var values []string
+	start, escaped := 0, false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case s[i] == ',':
+			values = append(values, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(values, s[start:])
+}
+
+// unescape unescapes the TEXT value
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
@@ -0,0 +1,169 @@
+// Package ical implements the subset of the RFC 5545 iCalendar format which the calendar
+// needs: a VCALENDAR of VEVENTs with their recurrence properties.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ProdId identifies kalenderium as the product which created the iCalendar object
+const ProdId = "-//kalenderium//kalenderium//EN"
+
+// maxLineOctets is the length which the content lines are folded at
+const maxLineOctets = 75
+
+const (
+	dateTimeFormat      = "20060102T150405Z"
+	localDateTimeFormat = "20060102T150405"
+	dateFormat          = "20060102"
+)
+
+// Event is a VEVENT component
+type Event struct {
+	UID          string
+	Stamp        time.Time
+	Summary      string
+	Description  string
+	Color        string
+	Start        time.Time
+	End          time.Time
+	RRule        string
+	ExDates      []time.Time
+	RDates       []time.Time
+	RecurrenceId time.Time
+	// TimeZone is the TZID of DTSTART. The times of the event are written in it as local
+	// times, otherwise they are written in UTC.
+	TimeZone string
+	// AllDay events have DATE values instead of DATE-TIME
+	AllDay bool
+	// Transparent events do not block time, TRANSP:TRANSPARENT
+	Transparent bool
+	// Categories are the CATEGORIES of the event, the values are joined into one property
+	Categories []string
+
+	// Err is set by Decode if the VEVENT is malformed
+	Err error
+
+	duration time.Duration
+}
+
+// Encode writes the events as a VCALENDAR object
+func Encode(w io.Writer, events []Event) error {
+	e := &encoder{w: bufio.NewWriter(w)}
+
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", ProdId)
+	e.line("CALSCALE", "GREGORIAN")
+	for _, event := range events {
+		e.event(event)
+	}
+	e.line("END", "VCALENDAR")
+
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) event(event Event) {
+	e.line("BEGIN", "VEVENT")
+	e.line("UID", event.UID)
+	e.line("DTSTAMP", formatTime(event.Stamp))
+	e.line(event.timeProperty("DTSTART", event.Start))
+	e.line(event.timeProperty("DTEND", event.End))
+	if !event.RecurrenceId.IsZero() {
+		e.line(event.timeProperty("RECURRENCE-ID", event.RecurrenceId))
+	}
+	e.line("SUMMARY", escape(event.Summary))
+	if event.Description != "" {
+		e.line("DESCRIPTION", escape(event.Description))
+	}
+	if event.Color != "" {
+		e.line("COLOR", escape(event.Color))
+	}
+	if event.Transparent {
+		e.line("TRANSP", "TRANSPARENT")
+	}
+	if len(event.Categories) > 0 {
+		values := make([]string, len(event.Categories))
+		for i, category := range event.Categories {
+			values[i] = escape(category)
+		}
+		e.line("CATEGORIES", strings.Join(values, ","))
+	}
+	if event.RRule != "" {
+		e.line("RRULE", strings.TrimPrefix(event.RRule, "RRULE:"))
+	}
+	if len(event.ExDates) > 0 {
+		e.line(event.timeProperty("EXDATE", event.ExDates...))
+	}
+	if len(event.RDates) > 0 {
+		e.line(event.timeProperty("RDATE", event.RDates...))
+	}
+	e.line("END", "VEVENT")
+}
+
+// line writes the content line, folded into lines of at most 75 octets
+func (e *encoder) line(name, value string) {
+	if e.err != nil {
+		return
+	}
+
+	s := name + ":" + value
+	var b strings.Builder
+	for n := 0; len(s) > 0; {
+		_, size := utf8.DecodeRuneInString(s)
+		if n+size > maxLineOctets {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteString(s[:size])
+		s = s[size:]
+		n += size
+	}
+	b.WriteString("\r\n")
+
+	_, e.err = e.w.WriteString(b.String())
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escape escapes the TEXT value
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// timeProperty returns the name with the parameters and the value of the time property. The
+// all-day events have DATE values, and the events with a time zone have local times with a TZID.
+// The VTIMEZONE components are not written, the TZIDs are the IANA time zone names which the
+// clients know.
+func (event Event) timeProperty(name string, times ...time.Time) (string, string) {
+	format := formatTime
+	if event.AllDay {
+		name += ";VALUE=DATE"
+		format = func(t time.Time) string { return t.UTC().Format(dateFormat) }
+	} else if loc, err := time.LoadLocation(event.TimeZone); event.TimeZone != "" && err == nil {
+		name += ";TZID=" + event.TimeZone
+		format = func(t time.Time) string { return t.In(loc).Format(localDateTimeFormat) }
+	}
+
+	values := make([]string, len(times))
+	for i, t := range times {
+		values[i] = format(t)
+	}
+	return name, strings.Join(values, ",")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
@@ -0,0 +1,315 @@
+package ical
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	start := time.Date(2022, time.April, 4, 9, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	err := Encode(&buf, []Event{{
+		UID:         "6285f86bb502f9d335124b04@kalenderium",
+		Stamp:       start,
+		Summary:     "Release; v1, v2",
+		Description: "Back\\slash\nnew line",
+		Color:       "#00FF00",
+		Start:       start,
+		End:         start.Add(time.Hour),
+		RRule:       "FREQ=WEEKLY;COUNT=4",
+		ExDates:     []time.Time{start.AddDate(0, 0, 7), start.AddDate(0, 0, 14)},
+	}})
+	if err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+
+	expected := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + ProdId,
+		"CALSCALE:GREGORIAN",
+		"BEGIN:VEVENT",
+		"UID:6285f86bb502f9d335124b04@kalenderium",
+		"DTSTAMP:20220404T090000Z",
+		"DTSTART:20220404T090000Z",
+		"DTEND:20220404T100000Z",
+		`SUMMARY:Release\; v1\, v2`,
+		`DESCRIPTION:Back\\slash\nnew line`,
+		"COLOR:#00FF00",
+		"RRULE:FREQ=WEEKLY;COUNT=4",
+		"EXDATE:20220411T090000Z,20220418T090000Z",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}
+	if got := buf.String(); got != strings.Join(expected, "\r\n") {
+		t.Errorf("Out -> \nWant: %q;\nGot: %q", strings.Join(expected, "\r\n"), got)
+	}
+}
+
+func TestEncode_Folding(t *testing.T) {
+	summary := strings.Repeat("ğ", 100)
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, []Event{{Summary: summary}}); err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+
+	var unfolded string
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("Out -> Want: at most %d octets; Got: %d", maxLineOctets, len(line))
+		}
+		if strings.HasPrefix(line, " ") {
+			unfolded += line[1:]
+		} else if strings.HasPrefix(line, "SUMMARY:") {
+			unfolded = line
+		}
+	}
+
+	if unfolded != "SUMMARY:"+summary {
+		t.Errorf("Out -> \nWant: %q;\nGot: %q", "SUMMARY:"+summary, unfolded)
+	}
+}
+
+func TestEncode_TimeZone(t *testing.T) {
+	istanbul, err := time.LoadLocation("Europe/Istanbul")
+	if err != nil {
+		t.Skipf("time zone database is not available: %v", err)
+	}
+	start := time.Date(2022, time.April, 4, 9, 0, 0, 0, istanbul)
+	holiday := time.Date(2022, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	err = Encode(&buf, []Event{
+		{
+			UID:      "weekly@example.com",
+			Stamp:    start,
+			Start:    start,
+			End:      start.Add(time.Hour),
+			RRule:    "FREQ=WEEKLY;COUNT=4",
+			ExDates:  []time.Time{start.AddDate(0, 0, 7)},
+			TimeZone: "Europe/Istanbul",
+		},
+		{
+			UID:    "holiday@example.com",
+			Stamp:  start,
+			Start:  holiday,
+			End:    holiday.AddDate(0, 0, 1),
+			AllDay: true,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+
+	for _, line := range []string{
+		"DTSTART;TZID=Europe/Istanbul:20220404T090000",
+		"DTEND;TZID=Europe/Istanbul:20220404T100000",
+		"EXDATE;TZID=Europe/Istanbul:20220411T090000",
+		"DTSTART;VALUE=DATE:20220501",
+		"DTEND;VALUE=DATE:20220502",
+	} {
+		if !strings.Contains(buf.String(), line+"\r\n") {
+			t.Errorf("Out -> Want: %q; Got: %q", line, buf.String())
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//example//example//EN",
+		"BEGIN:VTIMEZONE",
+		"TZID:Europe/Istanbul",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"UID:weekly@example.com",
+		"DTSTART;TZID=Europe/Istanbul:20220404T090000",
+		"DURATION:PT1H30M",
+		`SUMMARY:Release\; v1\, v2`,
+		"DESCRIPTION:a long description which is folded into the next line by the",
+		"  client",
+		"RRULE:FREQ=WEEKLY;COUNT=4",
+		"EXDATE:20220411T060000Z,20220418T060000Z",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:skipped",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:weekly@example.com",
+		"RECURRENCE-ID:20220425T060000Z",
+		"DTSTART:20220425T120000Z",
+		"DTEND:20220425T130000Z",
+		"SUMMARY:Moved",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:holiday@example.com",
+		"DTSTART;VALUE=DATE:20220501",
+		"SUMMARY:Holiday",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:broken@example.com",
+		"DTSTART:2022-05-01",
+		"SUMMARY:Broken",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("Out -> Want: 4 events; Got: %d", len(events))
+	}
+
+	start := time.Date(2022, time.April, 4, 6, 0, 0, 0, time.UTC)
+	weekly := events[0]
+	if weekly.Err != nil || !weekly.Start.Equal(start) || !weekly.End.Equal(start.Add(90*time.Minute)) {
+		t.Errorf("Out -> Want: %v - %v; Got: %v - %v, %v", start, start.Add(90*time.Minute), weekly.Start, weekly.End, weekly.Err)
+	}
+	if weekly.Summary != "Release; v1, v2" || weekly.RRule != "FREQ=WEEKLY;COUNT=4" || len(weekly.ExDates) != 2 ||
+		weekly.TimeZone != "Europe/Istanbul" {
+		t.Errorf("Out -> Got: %+v", weekly)
+	}
+	if weekly.Description != "a long description which is folded into the next line by the client" {
+		t.Errorf("Out -> Got: %q", weekly.Description)
+	}
+
+	if override := events[1]; !override.RecurrenceId.Equal(start.AddDate(0, 0, 21)) {
+		t.Errorf("Out -> Want: %v; Got: %v", start.AddDate(0, 0, 21), override.RecurrenceId)
+	}
+
+	holiday := events[2]
+	if !holiday.AllDay || !holiday.End.Equal(holiday.Start.AddDate(0, 0, 1)) {
+		t.Errorf("Out -> Want: one day; Got: %v - %v", holiday.Start, holiday.End)
+	}
+
+	if events[3].Err == nil {
+		t.Errorf("Err -> Want: invalid DTSTART; Got: nil")
+	}
+}
+
+func TestDecode_TimeZones(t *testing.T) {
+	input := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:windows@example.com",
+		"DTSTART;TZID=W. Europe Standard Time:20220704T090000",
+		"DTEND;TZID=W. Europe Standard Time:20220704T100000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:winter@example.com",
+		"DTSTART;TZID=Custom Berlin:20220110T090000",
+		"DTEND;TZID=Custom Berlin:20220110T100000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:summer@example.com",
+		"DTSTART;TZID=Custom Berlin:20220704T090000",
+		"DTEND;TZID=Custom Berlin:20220704T100000",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:unknown@example.com",
+		"DTSTART;TZID=Nowhere:20220704T090000",
+		"END:VEVENT",
+		"BEGIN:VTIMEZONE",
+		"TZID:Custom Berlin",
+		"BEGIN:STANDARD",
+		"DTSTART:19701025T030000",
+		"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU",
+		"TZOFFSETFROM:+0200",
+		"TZOFFSETTO:+0100",
+		"END:STANDARD",
+		"BEGIN:DAYLIGHT",
+		"DTSTART:19700329T020000",
+		"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU",
+		"TZOFFSETFROM:+0100",
+		"TZOFFSETTO:+0200",
+		"END:DAYLIGHT",
+		"END:VTIMEZONE",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("Out -> Want: 4 events; Got: %d", len(events))
+	}
+
+	tests := []struct {
+		start    time.Time
+		timeZone string
+	}{
+		{time.Date(2022, time.July, 4, 7, 0, 0, 0, time.UTC), "Europe/Berlin"},
+		{time.Date(2022, time.January, 10, 8, 0, 0, 0, time.UTC), ""},
+		{time.Date(2022, time.July, 4, 7, 0, 0, 0, time.UTC), ""},
+	}
+	for i, tt := range tests {
+		event := events[i]
+		if event.Err != nil || !event.Start.Equal(tt.start) || !event.End.Equal(tt.start.Add(time.Hour)) ||
+			event.TimeZone != tt.timeZone {
+			t.Errorf("Out -> Want: %v in %q; Got: %v - %v in %q, %v", tt.start, tt.timeZone, event.Start, event.End,
+				event.TimeZone, event.Err)
+		}
+	}
+
+	if events[3].Err == nil {
+		t.Errorf("Err -> Want: unknown time zone; Got: nil")
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"Not_Calendar":   "BEGIN:VEVENT\r\nEND:VEVENT\r\n",
+		"Missing_End":    "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n",
+		"Malformed_Line": "BEGIN:VCALENDAR\r\nSUMMARY\r\nEND:VCALENDAR\r\n",
+	}
+
+	for scenario, input := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			if _, err := Decode(strings.NewReader(input)); !errors.Is(err, ErrInvalidCalendar) {
+				t.Errorf("Err -> Want: %v; Got: %v", ErrInvalidCalendar, err)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	start := time.Date(2022, time.April, 4, 9, 0, 0, 0, time.UTC)
+	event := Event{
+		UID:         "6285f86bb502f9d335124b04@kalenderium",
+		Summary:     strings.Repeat("Release; v1, v2 ", 10),
+		Description: "line\nline",
+		Color:       "#00FF00",
+		Start:       start,
+		End:         start.Add(time.Hour),
+		Transparent: true,
+		Categories:  []string{"work", "client, acme"},
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, []Event{event}); err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+	events, err := Decode(&buf)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+
+	got := events[0]
+	if got.UID != event.UID || got.Summary != event.Summary || got.Description != event.Description ||
+		got.Color != event.Color || !got.Start.Equal(event.Start) || !got.End.Equal(event.End) ||
+		got.Transparent != event.Transparent || strings.Join(got.Categories, "|") != strings.Join(event.Categories, "|") {
+		t.Errorf("Out -> \nWant: %+v;\nGot: %+v", event, got)
+	}
+}
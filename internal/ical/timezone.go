@@ -0,0 +1,257 @@
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/rrule"
+	"github.com/pkg/errors"
+)
+
+// windowsZones maps the Windows time zone names which Outlook and Exchange write as TZID to the
+// IANA time zones
+var windowsZones = map[string]string{
+	"Dateline Standard Time":          "Etc/GMT+12",
+	"UTC-11":                          "Etc/GMT+11",
+	"Hawaiian Standard Time":          "Pacific/Honolulu",
+	"Alaskan Standard Time":           "America/Anchorage",
+	"Pacific Standard Time":           "America/Los_Angeles",
+	"US Mountain Standard Time":       "America/Phoenix",
+	"Mountain Standard Time":          "America/Denver",
+	"Central America Standard Time":   "America/Guatemala",
+	"Central Standard Time":           "America/Chicago",
+	"Central Standard Time (Mexico)":  "America/Mexico_City",
+	"Canada Central Standard Time":    "America/Regina",
+	"SA Pacific Standard Time":        "America/Bogota",
+	"Eastern Standard Time":           "America/New_York",
+	"US Eastern Standard Time":        "America/Indiana/Indianapolis",
+	"Atlantic Standard Time":          "America/Halifax",
+	"SA Western Standard Time":        "America/La_Paz",
+	"Pacific SA Standard Time":        "America/Santiago",
+	"Newfoundland Standard Time":      "America/St_Johns",
+	"E. South America Standard Time":  "America/Sao_Paulo",
+	"Argentina Standard Time":         "America/Argentina/Buenos_Aires",
+	"UTC-02":                          "Etc/GMT+2",
+	"Azores Standard Time":            "Atlantic/Azores",
+	"Cape Verde Standard Time":        "Atlantic/Cape_Verde",
+	"GMT Standard Time":               "Europe/London",
+	"Greenwich Standard Time":         "Atlantic/Reykjavik",
+	"Morocco Standard Time":           "Africa/Casablanca",
+	"W. Europe Standard Time":         "Europe/Berlin",
+	"Central Europe Standard Time":    "Europe/Budapest",
+	"Romance Standard Time":           "Europe/Paris",
+	"Central European Standard Time":  "Europe/Warsaw",
+	"W. Central Africa Standard Time": "Africa/Lagos",
+	"GTB Standard Time":               "Europe/Bucharest",
+	"E. Europe Standard Time":         "Europe/Chisinau",
+	"FLE Standard Time":               "Europe/Kiev",
+	"Israel Standard Time":            "Asia/Jerusalem",
+	"Egypt Standard Time":             "Africa/Cairo",
+	"South Africa Standard Time":      "Africa/Johannesburg",
+	"Turkey Standard Time":            "Europe/Istanbul",
+	"Arabic Standard Time":            "Asia/Baghdad",
+	"Arab Standard Time":              "Asia/Riyadh",
+	"Russian Standard Time":           "Europe/Moscow",
+	"E. Africa Standard Time":         "Africa/Nairobi",
+	"Iran Standard Time":              "Asia/Tehran",
+	"Arabian Standard Time":           "Asia/Dubai",
+	"Azerbaijan Standard Time":        "Asia/Baku",
+	"Afghanistan Standard Time":       "Asia/Kabul",
+	"Pakistan Standard Time":          "Asia/Karachi",
+	"West Asia Standard Time":         "Asia/Tashkent",
+	"India Standard Time":             "Asia/Kolkata",
+	"Nepal Standard Time":             "Asia/Kathmandu",
+	"Bangladesh Standard Time":        "Asia/Dhaka",
+	"Central Asia Standard Time":      "Asia/Almaty",
+	"Myanmar Standard Time":           "Asia/Yangon",
+	"SE Asia Standard Time":           "Asia/Bangkok",
+	"China Standard Time":             "Asia/Shanghai",
+	"Singapore Standard Time":         "Asia/Singapore",
+	"Taipei Standard Time":            "Asia/Taipei",
+	"W. Australia Standard Time":      "Australia/Perth",
+	"Korea Standard Time":             "Asia/Seoul",
+	"Tokyo Standard Time":             "Asia/Tokyo",
+	"Cen. Australia Standard Time":    "Australia/Adelaide",
+	"AUS Central Standard Time":       "Australia/Darwin",
+	"E. Australia Standard Time":      "Australia/Brisbane",
+	"AUS Eastern Standard Time":       "Australia/Sydney",
+	"Tasmania Standard Time":          "Australia/Hobart",
+	"West Pacific Standard Time":      "Pacific/Port_Moresby",
+	"Vladivostok Standard Time":       "Asia/Vladivostok",
+	"Central Pacific Standard Time":   "Pacific/Guadalcanal",
+	"New Zealand Standard Time":       "Pacific/Auckland",
+	"Fiji Standard Time":              "Pacific/Fiji",
+	"Tonga Standard Time":             "Pacific/Tongatapu",
+	"Samoa Standard Time":             "Pacific/Apia",
+}
+
+// timeZones are the VTIMEZONE components of the calendar by their TZID
+type timeZones map[string]*timeZone
+
+// timeZone holds the STANDARD and DAYLIGHT observances of a VTIMEZONE component
+type timeZone struct {
+	observances []*observance
+}
+
+// observance gives the UTC offset from its onsets on. The onsets are the local times in UTC.
+type observance struct {
+	start  time.Time
+	rule   *rrule.Rule
+	rdates []time.Time
+	offset time.Duration
+}
+
+// ianaZone gets the IANA time zone of the TZID, the Windows names are mapped to the IANA ones
+func ianaZone(tzid string) (string, bool) {
+	if _, err := time.LoadLocation(tzid); err == nil {
+		return tzid, true
+	}
+	name, ok := windowsZones[tzid]
+	return name, ok
+}
+
+// parseLocalTime parses the local DATE-TIME value in the time zone of the TZID. The TZID which
+// is not a known time zone is looked up in the VTIMEZONE components of the calendar.
+func parseLocalTime(value, tzid string, zones timeZones) (time.Time, error) {
+	if name, ok := ianaZone(tzid); ok {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.ParseInLocation(localDateTimeFormat, value, loc)
+	}
+
+	zone, ok := zones[tzid]
+	if !ok {
+		return time.Time{}, errors.Errorf("unknown time zone %q", tzid)
+	}
+	local, err := time.Parse(localDateTimeFormat, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return local.Add(-zone.offset(local)), nil
+}
+
+// offset returns the UTC offset of the local time, it is the offset of the observance which
+// has the last onset before the time
+func (z *timeZone) offset(local time.Time) time.Duration {
+	var last time.Time
+	offset := z.observances[0].offset
+	for _, o := range z.observances {
+		if onset, ok := o.lastOnset(local); ok && (last.IsZero() || onset.After(last)) {
+			last, offset = onset, o.offset
+		}
+	}
+	return offset
+}
+
+// lastOnset returns the last onset of the observance which is not after the local time
+func (o *observance) lastOnset(local time.Time) (time.Time, bool) {
+	onsets := []time.Time{o.start}
+	if o.rule != nil {
+		onsets = o.rule.Between(o.start, time.Time{}, local.Add(time.Nanosecond))
+	}
+	onsets = append(onsets, o.rdates...)
+
+	var last time.Time
+	for _, onset := range onsets {
+		if !onset.After(local) && onset.After(last) {
+			last = onset
+		}
+	}
+	return last, !last.IsZero()
+}
+
+// readTimeZones reads the VTIMEZONE components of the content lines. The malformed lines are
+// skipped, Decode reports them.
+func readTimeZones(lines []string) timeZones {
+	zones := timeZones{}
+
+	var stack []string
+	var tzid string
+	var zone *timeZone
+	var current *observance
+	for _, line := range lines {
+		p, err := parseLine(line)
+		if err != nil {
+			continue
+		}
+
+		switch p.name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(p.value))
+			if len(stack) == 2 && stack[1] == "VTIMEZONE" {
+				tzid, zone = "", &timeZone{}
+			}
+			if len(stack) == 3 && zone != nil && (stack[2] == "STANDARD" || stack[2] == "DAYLIGHT") {
+				current = &observance{}
+			}
+			continue
+		case "END":
+			switch {
+			case len(stack) == 3 && current != nil:
+				if !current.start.IsZero() {
+					zone.observances = append(zone.observances, current)
+				}
+				current = nil
+			case len(stack) == 2 && zone != nil:
+				if tzid != "" && len(zone.observances) > 0 {
+					zones[tzid] = zone
+				}
+				zone = nil
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+
+		switch {
+		case zone != nil && current == nil && len(stack) == 2 && p.name == "TZID":
+			tzid = p.value
+		case current != nil && len(stack) == 3:
+			current.set(p)
+		}
+	}
+	return zones
+}
+
+// set sets the observance property, the malformed values are skipped
+func (o *observance) set(p property) {
+	switch p.name {
+	case "DTSTART":
+		o.start, _ = time.Parse(localDateTimeFormat, p.value)
+	case "TZOFFSETTO":
+		o.offset, _ = parseOffset(p.value)
+	case "RRULE":
+		o.rule, _ = rrule.Parse(p.value)
+	case "RDATE":
+		for _, value := range strings.Split(p.value, ",") {
+			if rdate, err := time.Parse(localDateTimeFormat, value); err == nil {
+				o.rdates = append(o.rdates, rdate)
+			}
+		}
+	}
+}
+
+// parseOffset parses the UTC-OFFSET value like +0100, -0530 or +013000
+func parseOffset(value string) (time.Duration, error) {
+	if (len(value) != 5 && len(value) != 7) || (value[0] != '+' && value[0] != '-') {
+		return 0, errors.Errorf("invalid offset %q", value)
+	}
+
+	var d time.Duration
+	units := []time.Duration{time.Hour, time.Minute, time.Second}
+	for i := 0; 1+2*i < len(value); i++ {
+		n, err := strconv.Atoi(value[1+2*i : 3+2*i])
+		if err != nil {
+			return 0, errors.Errorf("invalid offset %q", value)
+		}
+		d += time.Duration(n) * units[i]
+	}
+	if value[0] == '-' {
+		d = -d
+	}
+	return d, nil
+}
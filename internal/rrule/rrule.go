@@ -0,0 +1,469 @@
+// Package rrule implements the subset of the RFC 5545 recurrence rules which the
+// calendar needs: FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, COUNT, UNTIL,
+// BYDAY, BYMONTHDAY, BYMONTH and WKST.
+package rrule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Frequency int
+
+const (
+	Daily Frequency = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencies = map[string]Frequency{
+	"DAILY":   Daily,
+	"WEEKLY":  Weekly,
+	"MONTHLY": Monthly,
+	"YEARLY":  Yearly,
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// maxPeriods bounds the iteration, so the rules which never match can not loop forever
+const maxPeriods = 100000
+
+// WeekdayNum is a BYDAY value like MO, 2TU or -1FR. N is zero for every weekday of the period.
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Rule is a parsed RRULE value
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+}
+
+// Parse parses the RRULE value, with or without the "RRULE:" prefix
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, errors.New("empty rule")
+	}
+
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	hasFreq := false
+
+	for _, part := range strings.Split(s, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, errors.Errorf("invalid rule part %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq, hasFreq = frequencies[strings.ToUpper(value)]
+			if !hasFreq {
+				return nil, errors.Errorf("unsupported frequency %q", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err != nil || r.Interval < 1 {
+				return nil, errors.Errorf("invalid interval %q", value)
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err != nil || r.Count < 1 {
+				return nil, errors.Errorf("invalid count %q", value)
+			}
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+			if err != nil {
+				return nil, err
+			}
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				wn, err := parseWeekdayNum(day)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wn)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, errors.Errorf("invalid month day %q", day)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, month := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(month)
+				if err != nil || n < 1 || n > 12 {
+					return nil, errors.Errorf("invalid month %q", month)
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			var ok bool
+			if r.WeekStart, ok = weekdays[strings.ToUpper(value)]; !ok {
+				return nil, errors.Errorf("invalid week start %q", value)
+			}
+		default:
+			return nil, errors.Errorf("unsupported rule part %q", key)
+		}
+	}
+
+	if !hasFreq {
+		return nil, errors.New("FREQ must be provided")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, errors.New("COUNT and UNTIL must not be used together")
+	}
+	for _, wn := range r.ByDay {
+		if wn.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return nil, errors.New("BYDAY with a number needs MONTHLY or YEARLY frequency")
+		}
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == Weekly {
+		return nil, errors.New("BYMONTHDAY must not be used with WEEKLY frequency")
+	}
+
+	return r, nil
+}
+
+// parseUntil parses the UTC date-time or the date forms of UNTIL. A date means the
+// whole day is included.
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, errors.Errorf("invalid until %q", value)
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return WeekdayNum{}, errors.Errorf("invalid weekday %q", value)
+	}
+
+	weekday, ok := weekdays[value[len(value)-2:]]
+	if !ok {
+		return WeekdayNum{}, errors.Errorf("invalid weekday %q", value)
+	}
+
+	var n int
+	if prefix := value[:len(value)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, errors.Errorf("invalid weekday %q", value)
+		}
+	}
+	return WeekdayNum{N: n, Weekday: weekday}, nil
+}
+
+// String formats the rule as an RRULE value without the prefix
+func (r *Rule) String() string {
+	var parts []string
+	for name, freq := range frequencies {
+		if freq == r.Freq {
+			parts = append(parts, "FREQ="+name)
+		}
+	}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, wn := range r.ByDay {
+			day := strings.ToUpper(wn.Weekday.String()[:2])
+			if wn.N != 0 {
+				day = strconv.Itoa(wn.N) + day
+			}
+			days = append(days, day)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		var days []string
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonth) > 0 {
+		var months []string
+		for _, m := range r.ByMonth {
+			months = append(months, strconv.Itoa(int(m)))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+strings.ToUpper(r.WeekStart.String()[:2]))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between returns the occurrences which start in [after, before). The first occurrence
+// is always dtstart, and the wall clock time of dtstart is kept in its location.
+func (r *Rule) Between(dtstart, after, before time.Time) []time.Time {
+	var occurrences []time.Time
+	r.iterate(dtstart, func(t time.Time) bool {
+		if !t.Before(before) {
+			return false
+		}
+		if !t.Before(after) {
+			occurrences = append(occurrences, t)
+		}
+		return true
+	})
+	return occurrences
+}
+
+// Last returns the start of the last occurrence. It returns false if the rule repeats forever.
+func (r *Rule) Last(dtstart time.Time) (time.Time, bool) {
+	if r.Count == 0 && r.Until.IsZero() {
+		return time.Time{}, false
+	}
+
+	var last time.Time
+	r.iterate(dtstart, func(t time.Time) bool {
+		last = t
+		return true
+	})
+	return last, true
+}
+
+// iterate calls fn with the occurrences in order until fn returns false or the rule ends
+func (r *Rule) iterate(dtstart time.Time, fn func(time.Time) bool) {
+	if !r.Until.IsZero() && dtstart.After(r.Until) {
+		return
+	}
+	if !fn(dtstart) {
+		return
+	}
+
+	count := 1
+	if r.Count > 0 && count >= r.Count {
+		return
+	}
+
+	hour, min, sec := dtstart.Clock()
+	for period := 0; period < maxPeriods; period++ {
+		for _, day := range r.periodDays(dtstart, period) {
+			t := time.Date(day.Year(), day.Month(), day.Day(), hour, min, sec, dtstart.Nanosecond(), dtstart.Location())
+			if !t.After(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return
+			}
+			if !fn(t) {
+				return
+			}
+			if count++; r.Count > 0 && count >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// periodDays returns the sorted days of the nth period, as UTC midnights
+func (r *Rule) periodDays(dtstart time.Time, n int) []time.Time {
+	start := time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day(), 0, 0, 0, 0, time.UTC)
+
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		day := start.AddDate(0, 0, n*r.Interval)
+		if r.matchMonth(day) && r.matchMonthDay(day) && r.matchWeekday(day) {
+			days = append(days, day)
+		}
+	case Weekly:
+		offset := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := start.AddDate(0, 0, n*r.Interval*7-offset)
+		for i := 0; i < 7; i++ {
+			day := weekStart.AddDate(0, 0, i)
+			if len(r.ByDay) > 0 && !r.matchWeekday(day) {
+				continue
+			}
+			if len(r.ByDay) == 0 && day.Weekday() != start.Weekday() {
+				continue
+			}
+			if r.matchMonth(day) {
+				days = append(days, day)
+			}
+		}
+	case Monthly:
+		month := time.Date(start.Year(), start.Month()+time.Month(n*r.Interval), 1, 0, 0, 0, 0, time.UTC)
+		if r.matchMonth(month) {
+			days = r.monthDays(month, start.Day())
+		}
+	case Yearly:
+		year := start.Year() + n*r.Interval
+		switch {
+		case len(r.ByMonth) > 0:
+			months := append([]time.Month(nil), r.ByMonth...)
+			sort.Slice(months, func(i, j int) bool { return months[i] < months[j] })
+			for _, m := range months {
+				days = append(days, r.monthDays(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC), start.Day())...)
+			}
+		case len(r.ByMonthDay) > 0:
+			for m := time.January; m <= time.December; m++ {
+				days = append(days, r.monthDays(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC), start.Day())...)
+			}
+		case len(r.ByDay) > 0:
+			days = nthWeekdays(time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC), r.ByDay)
+		default:
+			day := time.Date(year, start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+			if day.Month() == start.Month() {
+				days = append(days, day)
+			}
+		}
+	}
+	return days
+}
+
+// monthDays returns the days of the month which match BYMONTHDAY and BYDAY. Without
+// both of them, the day of dtstart is used if the month has it.
+func (r *Rule) monthDays(month time.Time, startDay int) []time.Time {
+	next := month.AddDate(0, 1, 0)
+	daysIn := next.AddDate(0, 0, -1).Day()
+
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if startDay > daysIn {
+			return nil
+		}
+		return []time.Time{month.AddDate(0, 0, startDay-1)}
+	}
+
+	var days []time.Time
+	if len(r.ByDay) > 0 {
+		days = nthWeekdays(month, next, r.ByDay)
+	} else {
+		for d := month; d.Before(next); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	}
+
+	if len(r.ByMonthDay) == 0 {
+		return days
+	}
+
+	var matched []time.Time
+	for _, day := range days {
+		for _, md := range r.ByMonthDay {
+			if md < 0 {
+				md = daysIn + 1 + md
+			}
+			if day.Day() == md {
+				matched = append(matched, day)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// nthWeekdays returns the sorted days in [from, to) which match the BYDAY values.
+// A numbered value like 2MO or -1FR counts the weekdays from the start or the end.
+func nthWeekdays(from, to time.Time, byDay []WeekdayNum) []time.Time {
+	set := make(map[time.Time]bool)
+	for _, wn := range byDay {
+		var matching []time.Time
+		for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == wn.Weekday {
+				matching = append(matching, d)
+			}
+		}
+
+		switch {
+		case wn.N == 0:
+			for _, d := range matching {
+				set[d] = true
+			}
+		case wn.N > 0 && wn.N <= len(matching):
+			set[matching[wn.N-1]] = true
+		case wn.N < 0 && -wn.N <= len(matching):
+			set[matching[len(matching)+wn.N]] = true
+		}
+	}
+
+	days := make([]time.Time, 0, len(set))
+	for d := range set {
+		days = append(days, d)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+func (r *Rule) matchMonth(day time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if day.Month() == m {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	daysIn := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, md := range r.ByMonthDay {
+		if md < 0 {
+			md = daysIn + 1 + md
+		}
+		if day.Day() == md {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wn := range r.ByDay {
+		if day.Weekday() == wn.Weekday {
+			return true
+		}
+	}
+	return false
+}
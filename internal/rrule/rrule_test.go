@@ -0,0 +1,109 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRule_Between(t *testing.T) {
+	dtstart := time.Date(2022, time.January, 31, 9, 30, 0, 0, time.UTC)
+	after := dtstart
+	before := dtstart.AddDate(1, 0, 0)
+
+	tests := map[string]struct {
+		rule     string
+		expected []string
+	}{
+		"Daily_Count": {
+			rule:     "FREQ=DAILY;COUNT=3",
+			expected: []string{"2022-01-31", "2022-02-01", "2022-02-02"},
+		},
+		"Daily_Until_Date": {
+			rule:     "RRULE:FREQ=DAILY;INTERVAL=2;UNTIL=20220204",
+			expected: []string{"2022-01-31", "2022-02-02", "2022-02-04"},
+		},
+		"Weekly_ByDay": {
+			rule:     "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4",
+			expected: []string{"2022-01-31", "2022-02-02", "2022-02-07", "2022-02-09"},
+		},
+		"Weekly_Interval": {
+			rule:     "FREQ=WEEKLY;INTERVAL=2;COUNT=3",
+			expected: []string{"2022-01-31", "2022-02-14", "2022-02-28"},
+		},
+		"Monthly_Skips_Short_Months": {
+			rule:     "FREQ=MONTHLY;COUNT=3",
+			expected: []string{"2022-01-31", "2022-03-31", "2022-05-31"},
+		},
+		"Monthly_Last_Friday": {
+			rule:     "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			expected: []string{"2022-01-31", "2022-02-25", "2022-03-25"},
+		},
+		"Monthly_Negative_Month_Day": {
+			rule:     "FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=3",
+			expected: []string{"2022-01-31", "2022-02-28", "2022-03-31"},
+		},
+		"Yearly_ByMonth_ByDay": {
+			rule:     "FREQ=YEARLY;BYMONTH=2,3;BYDAY=1MO;COUNT=3",
+			expected: []string{"2022-01-31", "2022-02-07", "2022-03-07"},
+		},
+		"Empty_Window": {
+			rule:     "FREQ=DAILY;UNTIL=20210101T000000Z",
+			expected: nil,
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Err -> Want: nil; Got: %v", err)
+			}
+
+			var got []string
+			for _, o := range r.Between(dtstart, after, before) {
+				if o.Hour() != 9 || o.Minute() != 30 {
+					t.Errorf("Out -> Want: 09:30; Got: %v", o)
+				}
+				got = append(got, o.Format("2006-01-02"))
+			}
+
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Out -> \nWant: %v;\nGot: %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("Out -> \nWant: %v;\nGot: %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		rule  string
+		valid bool
+	}{
+		"Valid":            {rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;WKST=SU", valid: true},
+		"Missing_Freq":     {rule: "COUNT=3", valid: false},
+		"Hourly":           {rule: "FREQ=HOURLY", valid: false},
+		"Count_And_Until":  {rule: "FREQ=DAILY;COUNT=3;UNTIL=20220101", valid: false},
+		"Weekly_Numbered":  {rule: "FREQ=WEEKLY;BYDAY=2MO", valid: false},
+		"Invalid_Interval": {rule: "FREQ=DAILY;INTERVAL=0", valid: false},
+		"Empty":            {rule: "", valid: false},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Err -> Want valid: %v; Got: %v", tt.valid, err)
+			}
+			if err == nil {
+				if _, err = Parse(r.String()); err != nil {
+					t.Errorf("Err -> Want: nil for %q; Got: %v", r.String(), err)
+				}
+			}
+		})
+	}
+}
@@ -164,6 +164,56 @@ func (a *accountService) Logout(ctx context.Context, sessionToken token.Token) e
 	return nil
 }
 
+// CheckPassword checks the email and password of the user without creating a session, and
+// returns the user id. It is for the clients which send the credentials with every request.
+func (a *accountService) CheckPassword(ctx context.Context, user repository.User) (uint64, error) {
+	// Validate the user
+	v := validator.New()
+	repository.ValidateEmail(v, user.Email)
+	repository.ValidatePassword(v, user.Password)
+	if !v.Valid() {
+		logger.Log("msg", "failed user data validation")
+		return 0, errors.New("failed user data validation")
+	}
+
+	// Get user from account database
+	usr, err := a.accountRepository.GetUser(ctx, user.Email)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			logger.Log("msg", "user not found")
+			return 0, errors.New("user not found")
+		}
+		return 0, err
+	}
+
+	// Compare password hashes
+	match, err := usr.Matches(user.Password)
+	if err != nil {
+		logger.Log("msg", "failed to compare password and hash")
+		return 0, errors.New("failed to compare password and hash")
+	}
+	if !match {
+		logger.Log("msg", "wrong password")
+		return 0, errors.New("wrong password")
+	}
+
+	return usr.UserID, nil
+}
+
+// GetEmail returns the email address of the user. It is for the other services which send
+// notifications to the users.
+func (a *accountService) GetEmail(ctx context.Context, userId uint64) (string, error) {
+	usr, err := a.accountRepository.GetUserById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			logger.Log("msg", "user not found")
+			return "", errors.New("user not found")
+		}
+		return "", err
+	}
+	return usr.Email, nil
+}
+
 func (a *accountService) ServiceStatus(ctx context.Context) (int, error) {
 	logger.Log("msg", "Checking the Service health...")
 	err := a.accountRepository.ServiceStatus(ctx)
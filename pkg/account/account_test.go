@@ -588,3 +588,131 @@ func TestAccountService_Logout(t *testing.T) {
 		})
 	}
 }
+
+func TestAccountService_CheckPassword(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.CheckPasswordReply
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.CheckPasswordRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.CheckPasswordRequest{
+				User: &pb.User{
+					Email:    mockRepo.User.Email,
+					Password: mockRepo.User.Password,
+				},
+			},
+			expected: expectation{
+				out: &pb.CheckPasswordReply{UserId: mockRepo.User.UserID},
+				err: nil,
+			},
+		},
+		"Wrong_Password": {
+			in: &pb.CheckPasswordRequest{
+				User: &pb.User{
+					Email:    mockRepo.User.Email,
+					Password: "test1234",
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = Unknown desc = wrong password"),
+			},
+		},
+		"Empty_Password": {
+			in: &pb.CheckPasswordRequest{
+				User: &pb.User{
+					Email:    mockRepo.User.Email,
+					Password: "",
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = Unknown desc = failed user data validation"),
+			},
+		},
+		"Unknown_Email": {
+			in: &pb.CheckPasswordRequest{
+				User: &pb.User{
+					Email:    "test@23.com",
+					Password: mockRepo.User.Password,
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = Unknown desc = record not found"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.CheckPassword(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+			} else {
+				if tt.expected.err != nil {
+					t.Errorf("Err -> Want: \n%q\n;Got: nil", tt.expected.err)
+				} else if out.UserId != tt.expected.out.UserId || out.Err != tt.expected.out.Err {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+				}
+			}
+		})
+	}
+}
+
+func TestAccountService_GetEmail(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.GetEmailReply
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.GetEmailRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.GetEmailRequest{UserId: mockRepo.User.UserID},
+			expected: expectation{
+				out: &pb.GetEmailReply{Email: mockRepo.User.Email},
+				err: nil,
+			},
+		},
+		"Unknown_User": {
+			in: &pb.GetEmailRequest{UserId: 23},
+			expected: expectation{
+				err: errors.New("rpc error: code = Unknown desc = record not found"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.GetEmail(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+			} else {
+				if tt.expected.err != nil {
+					t.Errorf("Err -> Want: \n%q\n;Got: nil", tt.expected.err)
+				} else if out.Email != tt.expected.out.Email || out.Err != tt.expected.out.Err {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+				}
+			}
+		})
+	}
+}
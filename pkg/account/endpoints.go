@@ -10,6 +10,8 @@ type Set struct {
 	SignUpEndpoint        endpoint.Endpoint
 	LoginEndpoint         endpoint.Endpoint
 	LogoutEndpoint        endpoint.Endpoint
+	CheckPasswordEndpoint endpoint.Endpoint
+	GetEmailEndpoint      endpoint.Endpoint
 	ServiceStatusEndpoint endpoint.Endpoint
 }
 
@@ -19,6 +21,8 @@ func New(s Service) Set {
 		SignUpEndpoint:        MakeSignUpEndpoint(s),
 		LoginEndpoint:         MakeLoginEndpoint(s),
 		LogoutEndpoint:        MakeLogoutEndpoint(s),
+		CheckPasswordEndpoint: MakeCheckPasswordEndpoint(s),
+		GetEmailEndpoint:      MakeGetEmailEndpoint(s),
 		ServiceStatusEndpoint: MakeServiceStatusEndpoint(s),
 	}
 }
@@ -79,6 +83,34 @@ func MakeLogoutEndpoint(s Service) endpoint.Endpoint {
 	}
 }
 
+// MakeCheckPasswordEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeCheckPasswordEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CheckPasswordRequest)
+
+		userId, err := s.CheckPassword(ctx, req.User)
+		if err != nil {
+			return CheckPasswordResponse{UserId: 0, Err: err.Error()}, err
+		}
+		return CheckPasswordResponse{UserId: userId, Err: ""}, nil
+	}
+}
+
+// MakeGetEmailEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeGetEmailEndpoint(s Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetEmailRequest)
+
+		email, err := s.GetEmail(ctx, req.UserId)
+		if err != nil {
+			return GetEmailResponse{Email: "", Err: err.Error()}, err
+		}
+		return GetEmailResponse{Email: email, Err: ""}, nil
+	}
+}
+
 // MakeServiceStatusEndpoint will receive a request, convert to the desired
 // format, invoke the service and return the response structure
 func MakeServiceStatusEndpoint(s Service) endpoint.Endpoint {
@@ -14,6 +14,8 @@ type gRPCServer struct {
 	signUp        grpcTransport.Handler
 	login         grpcTransport.Handler
 	logout        grpcTransport.Handler
+	checkPassword grpcTransport.Handler
+	getEmail      grpcTransport.Handler
 	serviceStatus grpcTransport.Handler
 }
 
@@ -35,6 +37,14 @@ func NewGRPCServer(ep Set) pb.AccountServer {
 			ep.LogoutEndpoint,
 			decodeLogoutRequest,
 			encodeLogoutResponse),
+		checkPassword: grpcTransport.NewServer(
+			ep.CheckPasswordEndpoint,
+			decodeCheckPasswordRequest,
+			encodeCheckPasswordResponse),
+		getEmail: grpcTransport.NewServer(
+			ep.GetEmailEndpoint,
+			decodeGetEmailRequest,
+			encodeGetEmailResponse),
 		serviceStatus: grpcTransport.NewServer(
 			ep.ServiceStatusEndpoint,
 			decodeServiceStatusRequest,
@@ -74,6 +84,22 @@ func (g *gRPCServer) Logout(ctx context.Context, r *pb.LogoutRequest) (*pb.Logou
 	return resp.(*pb.LogoutReply), nil
 }
 
+func (g *gRPCServer) CheckPassword(ctx context.Context, r *pb.CheckPasswordRequest) (*pb.CheckPasswordReply, error) {
+	_, resp, err := g.checkPassword.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.CheckPasswordReply), nil
+}
+
+func (g *gRPCServer) GetEmail(ctx context.Context, r *pb.GetEmailRequest) (*pb.GetEmailReply, error) {
+	_, resp, err := g.getEmail.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.GetEmailReply), nil
+}
+
 func (g *gRPCServer) ServiceStatus(ctx context.Context, r *pb.ServiceStatusRequest) (*pb.ServiceStatusReply, error) {
 	_, resp, err := g.serviceStatus.ServeGRPC(ctx, r)
 	if err != nil {
@@ -179,6 +205,34 @@ func encodeLogoutResponse(_ context.Context, res interface{}) (interface{}, erro
 	return &pb.LogoutReply{Err: reply.Err}, nil
 }
 
+// decodeCheckPasswordRequest extracts a user-domain request object from a gRPC request
+func decodeCheckPasswordRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.CheckPasswordRequest)
+	user := repository.User{
+		Email:    request.User.GetEmail(),
+		Password: request.User.GetPassword(),
+	}
+	return CheckPasswordRequest{User: user}, nil
+}
+
+// encodeCheckPasswordResponse encodes the passed response object to the gRPC response message.
+func encodeCheckPasswordResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(CheckPasswordResponse)
+	return &pb.CheckPasswordReply{UserId: reply.UserId, Err: reply.Err}, nil
+}
+
+// decodeGetEmailRequest extracts a user-domain request object from a gRPC request
+func decodeGetEmailRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.GetEmailRequest)
+	return GetEmailRequest{UserId: request.GetUserId()}, nil
+}
+
+// encodeGetEmailResponse encodes the passed response object to the gRPC response message.
+func encodeGetEmailResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(GetEmailResponse)
+	return &pb.GetEmailReply{Email: reply.Email, Err: reply.Err}, nil
+}
+
 // decodeServiceStatusRequest extracts a user-domain request object from a gRPC request
 func decodeServiceStatusRequest(_ context.Context, req interface{}) (interface{}, error) {
 	_ = req.(*pb.ServiceStatusRequest)
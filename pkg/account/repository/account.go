@@ -33,6 +33,7 @@ func (u *User) IsAnonymous() bool {
 type AccountRepository interface {
 	CreateUser(ctx context.Context, user *User) error
 	GetUser(ctx context.Context, email string) (*User, error)
+	GetUserById(ctx context.Context, userId uint64) (*User, error)
 	ServiceStatus(ctx context.Context) error
 }
 
@@ -153,6 +154,34 @@ func (a *accountRepository) GetUser(ctx context.Context, email string) (*User, e
 	return &user, nil
 }
 
+// GetUserById gets the user according to given userId
+func (a *accountRepository) GetUserById(ctx context.Context, userId uint64) (*User, error) {
+	query := `
+		SELECT id, email, password
+        FROM users
+        WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var user User
+	err := a.db.QueryRowContext(ctx, query, userId).Scan(
+		&user.UserID,
+		&user.Email,
+		&user.PasswordHash,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 // ServiceStatus a health-check mechanism
 func (a *accountRepository) ServiceStatus(ctx context.Context) error {
 	return a.db.PingContext(ctx)
@@ -14,6 +14,7 @@ var (
 type AccountRepository interface {
 	CreateUser(ctx context.Context, user *repository.User) error
 	GetUser(ctx context.Context, email string) (*repository.User, error)
+	GetUserById(ctx context.Context, userId uint64) (*repository.User, error)
 	ServiceStatus(ctx context.Context) error
 }
 
@@ -46,6 +47,13 @@ func (a *accountRepository) GetUser(_ context.Context, email string) (*repositor
 	return User, nil
 }
 
+func (a *accountRepository) GetUserById(_ context.Context, userId uint64) (*repository.User, error) {
+	if userId != User.UserID {
+		return nil, ErrRecordNotFound
+	}
+	return User, nil
+}
+
 func (a *accountRepository) ServiceStatus(_ context.Context) error {
 	return nil
 }
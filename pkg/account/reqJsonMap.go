@@ -50,6 +50,28 @@ type LogoutResponse struct {
 	Err string `json:"err,omitempty"`
 }
 
+// CheckPasswordRequest -> CheckPassword endpoint's  input structures
+type CheckPasswordRequest struct {
+	User repository.User `json:"user"`
+}
+
+// CheckPasswordResponse -> CheckPassword endpoint's output structure
+type CheckPasswordResponse struct {
+	UserId uint64 `json:"userId,omitempty"`
+	Err    string `json:"err,omitempty"`
+}
+
+// GetEmailRequest -> GetEmail endpoint's  input structures
+type GetEmailRequest struct {
+	UserId uint64 `json:"userId"`
+}
+
+// GetEmailResponse -> GetEmail endpoint's output structure
+type GetEmailResponse struct {
+	Email string `json:"email,omitempty"`
+	Err   string `json:"err,omitempty"`
+}
+
 // ServiceStatusRequest -> ServiceStatus endpoint's  input structures
 type ServiceStatusRequest struct{}
 
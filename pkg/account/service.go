@@ -11,5 +11,7 @@ type Service interface {
 	SignUp(ctx context.Context, user repository.User) (uint64, token.Token, error)
 	Login(ctx context.Context, user repository.User) (uint64, token.Token, error)
 	Logout(ctx context.Context, token token.Token) error
+	CheckPassword(ctx context.Context, user repository.User) (uint64, error)
+	GetEmail(ctx context.Context, userId uint64) (string, error)
 	ServiceStatus(ctx context.Context) (int, error)
 }
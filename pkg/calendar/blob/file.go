@@ -0,0 +1,90 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/pkg/errors"
+)
+
+// FileStore keeps the contents in the files of a directory, the name of a file is the id of its
+// content. It suits the single node deployments, the replicas need a shared directory.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates the store in the directory, the directory is created if it does not exist
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, errors.Wrap(err, "failed to create blob directory")
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Put writes the content to a temporary file which is renamed to the id when it is complete, so
+// the partial contents are never opened
+func (s *FileStore) Put(_ context.Context, id string, content io.Reader) error {
+	path, ok := s.path(id)
+	if !ok {
+		return errors.Errorf("invalid blob id %q", id)
+	}
+
+	f, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create blob")
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = io.Copy(f, content); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to write blob")
+	}
+	if err = f.Close(); err != nil {
+		return errors.Wrap(err, "failed to write blob")
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return errors.Wrap(err, "failed to write blob")
+	}
+	return nil
+}
+
+// Open opens the file of the content
+func (s *FileStore) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	path, ok := s.path(id)
+	if !ok {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, repository.ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to open blob")
+	}
+	return f, nil
+}
+
+// Delete removes the file of the content
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	path, ok := s.path(id)
+	if !ok {
+		return nil
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to delete blob")
+	}
+	return nil
+}
+
+// path returns the path of the file of the content, it is false if the id is not a plain file name
+func (s *FileStore) path(id string) (string, bool) {
+	if id == "" || id[0] == '.' || filepath.Base(id) != id {
+		return "", false
+	}
+	return filepath.Join(s.dir, id), true
+}
@@ -0,0 +1,109 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/pkg/errors"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.Put(ctx, "62b0f1a2c3d4e5f601234567", strings.NewReader("agenda")); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := s.Open(ctx, "62b0f1a2c3d4e5f601234567")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "agenda" {
+		t.Fatalf("expected agenda, got %q", content)
+	}
+
+	// The temporary files are not left behind
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(files))
+	}
+
+	if err = s.Delete(ctx, "62b0f1a2c3d4e5f601234567"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Open(ctx, "62b0f1a2c3d4e5f601234567"); !errors.Is(err, repository.ErrRecordNotFound) {
+		t.Fatalf("expected %v, got %v", repository.ErrRecordNotFound, err)
+	}
+	// The missing contents are deleted already
+	if err = s.Delete(ctx, "62b0f1a2c3d4e5f601234567"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// failingReader fails after the first read
+type failingReader struct {
+	read bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.read {
+		return 0, errors.New("connection reset")
+	}
+	r.read = true
+	return copy(p, "partial"), nil
+}
+
+func TestFileStore_PutFailed(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.Put(ctx, "62b0f1a2c3d4e5f601234567", &failingReader{}); err == nil {
+		t.Fatal("expected the put to fail")
+	}
+	if _, err = s.Open(ctx, "62b0f1a2c3d4e5f601234567"); !errors.Is(err, repository.ErrRecordNotFound) {
+		t.Fatalf("expected %v, got %v", repository.ErrRecordNotFound, err)
+	}
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Fatalf("expected no files, got %d", len(files))
+	}
+}
+
+func TestFileStore_InvalidId(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"", "../secret", ".upload-1", "a/b"} {
+		if err = s.Put(ctx, id, strings.NewReader("x")); err == nil {
+			t.Fatalf("expected the put of %q to fail", id)
+		}
+		if _, err = s.Open(ctx, id); !errors.Is(err, repository.ErrRecordNotFound) {
+			t.Fatalf("expected %v for %q, got %v", repository.ErrRecordNotFound, id, err)
+		}
+	}
+}
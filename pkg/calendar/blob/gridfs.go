@@ -0,0 +1,88 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// bucketName is the name of the GridFS bucket which the contents are kept in
+const bucketName = "attachments"
+
+// GridFSStore keeps the contents in GridFS, so they are in the database of the events and the
+// replicas share them
+type GridFSStore struct {
+	db *mongo.Database
+}
+
+// NewGridFSStore creates the store in the database
+func NewGridFSStore(db *mongo.Database) *GridFSStore {
+	return &GridFSStore{db: db}
+}
+
+// bucket opens the bucket with the deadline of the context. The deadlines are set on the bucket,
+// so every call has its own one.
+func (s *GridFSStore) bucket(ctx context.Context) (*gridfs.Bucket, error) {
+	bucket, err := gridfs.NewBucket(s.db, options.GridFSBucket().SetName(bucketName))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open gridfs bucket")
+	}
+
+	var deadline time.Time
+	if d, ok := ctx.Deadline(); ok {
+		deadline = d
+	}
+	if err = bucket.SetReadDeadline(deadline); err != nil {
+		return nil, errors.Wrap(err, "failed to open gridfs bucket")
+	}
+	if err = bucket.SetWriteDeadline(deadline); err != nil {
+		return nil, errors.Wrap(err, "failed to open gridfs bucket")
+	}
+	return bucket, nil
+}
+
+// Put uploads the content as a file with the id
+func (s *GridFSStore) Put(ctx context.Context, id string, content io.Reader) error {
+	bucket, err := s.bucket(ctx)
+	if err != nil {
+		return err
+	}
+	if err = bucket.UploadFromStreamWithID(id, id, content); err != nil {
+		return errors.Wrap(err, "failed to upload blob")
+	}
+	return nil
+}
+
+// Open opens the download stream of the file with the id
+func (s *GridFSStore) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	bucket, err := s.bucket(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := bucket.OpenDownloadStream(id)
+	if err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return nil, repository.ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to download blob")
+	}
+	return stream, nil
+}
+
+// Delete deletes the file with the id and its chunks
+func (s *GridFSStore) Delete(ctx context.Context, id string) error {
+	bucket, err := s.bucket(ctx)
+	if err != nil {
+		return err
+	}
+	if err = bucket.Delete(id); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		return errors.Wrap(err, "failed to delete blob")
+	}
+	return nil
+}
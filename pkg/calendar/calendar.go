@@ -1,70 +1,1837 @@
 package calendar
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"github.com/3n0ugh/kalenderium/internal/config"
 	"github.com/3n0ugh/kalenderium/internal/validator"
 	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
 	"github.com/go-kit/log"
 	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"mime"
 	"net/http"
 	"os"
+	"sort"
+	"strings"
+	"time"
 )
 
+// defaultMaxPageSize is used when the max page size is not configured
+const defaultMaxPageSize = 500
+
 type calendarService struct {
 	calendarRepository repository.CalendarRepository
+	maxPageSize        int
 }
 
-func NewService(calendarRepository repository.CalendarRepository) Service {
+func NewService(calendarRepository repository.CalendarRepository, cfg config.CalendarServiceConfigurations) Service {
+	maxPageSize := cfg.MaxPageSize
+	if maxPageSize <= 0 {
+		maxPageSize = defaultMaxPageSize
+	}
+
 	return &calendarService{
 		calendarRepository: calendarRepository,
+		maxPageSize:        maxPageSize,
 	}
 }
 
-// CreateEvent -> Add the given event to calendar database and returns the eventId
-func (c *calendarService) CreateEvent(ctx context.Context, event repository.Event) (string, error) {
+// CreateEvent -> Add the given event to calendar database and returns the eventId. The events
+// of the organizer which the event overlaps are returned with the warn policy (the default),
+// and the event is not added with the reject policy if it overlaps any.
+func (c *calendarService) CreateEvent(ctx context.Context, event repository.Event,
+	policy string) (string, []repository.Event, error) {
+	event.Tags = repository.NormalizeTags(event.Tags)
+
 	v := validator.New()
-	if repository.ValidateEvent(v, event); !v.Valid() {
+	repository.ValidateEvent(v, event)
+	repository.ValidateConflictPolicy(v, policy)
+	if !v.Valid() {
 		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
-		return "", errors.New(fmt.Sprintf("%v", v.Errors))
+		return "", nil, validationError(v)
+	}
+
+	// Occurrences are created through UpdateEvent of the recurring event, and the attachments
+	// are added through AddAttachment
+	event.RecurringEventId, event.RecurrenceId, event.Attachments = nil, nil, nil
+	// The attendees respond to the invitation by themselves
+	for i := range event.Attendees {
+		event.Attendees[i].Status = repository.NeedsAction
+	}
+
+	userId := event.UserId
+	if !event.CalendarId.IsZero() {
+		// The editors add the events to a shared calendar on behalf of its owner
+		calendar, err := c.authorize(ctx, event.CalendarId.Hex(), event.UserId, repository.RoleEditor)
+		if err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				return "", nil, errUnknownCalendar
+			}
+			return "", nil, err
+		}
+		event.UserId = calendar.UserId
 	}
 
-	err := c.calendarRepository.CreateEvent(ctx, &event)
+	var err error
+	if event.CalendarId, err = c.eventCalendar(ctx, event.UserId, event.CalendarId); errors.Is(err, errUnknownCalendar) {
+		return "", nil, err
+	}
+	if err == nil {
+		err = event.SetRecurrenceEnd()
+	}
+
+	var conflicts []repository.Event
+	if err == nil && policy != repository.ConflictAllow {
+		if conflicts, err = c.conflicts(ctx, event); err == nil && userId != event.UserId {
+			// The editors only see when the other events of the owner are
+			for i := range conflicts {
+				conflicts[i] = conflicts[i].Busy()
+			}
+		}
+	}
+	if err == nil && policy == repository.ConflictReject && len(conflicts) > 0 {
+		return "", nil, &repository.ConflictError{Conflicts: conflicts}
+	}
+
+	if err == nil {
+		err = c.calendarRepository.CreateEvent(ctx, &event)
+	}
 	if err != nil {
 		logger.Log("msg", "failed to create event", "err", err)
-		return "", errors.New("failed to create event")
+		return "", nil, errors.New("failed to create event")
+	}
+
+	c.record(ctx, userId, repository.HistoryCreate, nil, &event)
+	return event.Id.Hex(), conflicts, nil
+}
+
+// conflicts gets the events of the organizer which overlap the event. The occurrences of a
+// recurring event are checked up to a year after its start.
+func (c *calendarService) conflicts(ctx context.Context, event repository.Event) ([]repository.Event, error) {
+	if event.Transparent || !event.Start.Before(event.End) {
+		return nil, nil
+	}
+
+	occurrences := []repository.Event{event}
+	filter := repository.EventFilter{From: event.Start, To: event.End}
+	if event.IsRecurring() {
+		filter.To = event.Start.Add(repository.MaxFreeBusyWindow)
+		if last := event.Recurrence.Last; last != nil && last.Before(filter.To) {
+			filter.To = *last
+		}
+
+		var err error
+		if occurrences, err = event.Expand(nil, filter.From, filter.To); err != nil {
+			return nil, err
+		}
+	}
+
+	events, err := c.busyEvents(ctx, event.UserId, filter)
+	if err != nil {
+		return nil, err
+	}
+	return repository.Conflicts(event.UserId, occurrences, events), nil
+}
+
+// BatchCreateEvents -> Add the events of the user at once and return the result of each event.
+// The invalid events are reported in their results, and the valid ones are added. The events are
+// added to the calendars of the user without checking the conflicts.
+func (c *calendarService) BatchCreateEvents(ctx context.Context, userId uint64,
+	events []repository.Event) ([]repository.BatchResult, error) {
+	v := validator.New()
+	repository.ValidateBatchSize(v, "events", len(events))
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return nil, validationError(v)
+	}
+
+	results := make([]repository.BatchResult, len(events))
+	calendars := map[primitive.ObjectID]primitive.ObjectID{}
+	var valid []*repository.Event
+	var indexes []int
+	for i := range events {
+		event := &events[i]
+		event.Id, event.UserId, event.Tags = primitive.NilObjectID, userId, repository.NormalizeTags(event.Tags)
+		event.RecurringEventId, event.RecurrenceId, event.DeletedAt, event.Attachments = nil, nil, nil, nil
+		for j := range event.Attendees {
+			event.Attendees[j].Status = repository.NeedsAction
+		}
+
+		v := validator.New()
+		if repository.ValidateEvent(v, *event); !v.Valid() {
+			results[i].Err = fmt.Sprintf("%v", v.Errors)
+			continue
+		}
+
+		// The calendars are looked up once for the batch
+		calendarId, ok := calendars[event.CalendarId]
+		if !ok {
+			var err error
+			calendarId, err = c.eventCalendar(ctx, userId, event.CalendarId)
+			if err != nil && !errors.Is(err, errUnknownCalendar) {
+				logger.Log("msg", "failed to get calendar", "err", err)
+				return nil, errors.New("failed to create events")
+			}
+			calendars[event.CalendarId] = calendarId
+		}
+		if calendarId.IsZero() {
+			results[i].Err = errUnknownCalendar.Error()
+			continue
+		}
+		event.CalendarId = calendarId
+
+		if err := event.SetRecurrenceEnd(); err != nil {
+			results[i].Err = err.Error()
+			continue
+		}
+		valid = append(valid, event)
+		indexes = append(indexes, i)
+	}
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	failed, err := c.calendarRepository.CreateEvents(ctx, valid)
+	if err != nil {
+		logger.Log("msg", "failed to create events", "err", err)
+		return nil, errors.New("failed to create events")
+	}
+
+	created := make([]repository.Event, 0, len(valid))
+	for k, event := range valid {
+		if writeErr, ok := failed[k]; ok {
+			logger.Log("msg", "failed to create event", "err", writeErr)
+			results[indexes[k]].Err = "failed to create event"
+			continue
+		}
+		results[indexes[k]].EventId = event.Id.Hex()
+		created = append(created, *event)
+	}
+
+	c.recordAll(ctx, userId, repository.HistoryCreate, created)
+	return results, nil
+}
+
+// ListEvent -> Get a page of events from database according to userId and filter, and
+// return events with the next page token. The page size is limited by the max page size.
+// If the filter has an end, the recurring events are expanded into their occurrences in
+// the window, and the pages are taken from the expanded events. Otherwise, or if the filter
+// asks not to expand them, the recurring events are followed by their overridden instances. The calendars of the filter can be
+// the ones which are shared with the user.
+func (c *calendarService) ListEvent(ctx context.Context, userId uint64, filter repository.EventFilter,
+	page repository.Page) ([]repository.Event, string, error) {
+	filter.Tags = repository.NormalizeTags(filter.Tags)
+
+	v := validator.New()
+	repository.ValidateEventFilter(v, filter)
+	v.Check(page.Size >= 0, "page_size", "must not be negative")
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return nil, "", validationError(v)
+	}
+
+	if page.Size == 0 || page.Size > c.maxPageSize {
+		page.Size = c.maxPageSize
+	}
+
+	var busy map[primitive.ObjectID]bool
+	var err error
+	if filter.SharedCalendarIds, busy, err = c.sharedCalendars(ctx, userId, filter.CalendarIds); err != nil {
+		return nil, "", err
+	}
+
+	var events []repository.Event
+	var nextPageToken string
+	if filter.To.IsZero() || filter.NoExpand {
+		events, nextPageToken, err = c.calendarRepository.ListEvent(ctx, userId, filter, page)
+		if err == nil {
+			events, err = c.expandEvents(ctx, userId, events, filter)
+		}
+	} else {
+		// The occurrences of a recurring event are spread over the window, so the whole
+		// window is expanded before it is paged
+		if events, err = c.busyEvents(ctx, userId, filter); err == nil {
+			events, nextPageToken, err = repository.PageOccurrences(events, page)
+		}
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidPageToken) {
+			return nil, "", repository.ErrInvalidPageToken
+		}
+		logger.Log("msg", "failed to get events", "err", err)
+		return nil, "", errors.New("failed to get events")
+	}
+	for i := range events {
+		if busy[events[i].CalendarId] {
+			events[i] = events[i].Busy()
+		}
+	}
+	return events, nextPageToken, nil
+}
+
+// SearchEvents -> Search the names and the details of the events of the user for the query, and
+// return a page of the matching events ranked by the relevance with the next page token. The
+// page size is limited by the max page size. The recurring events are not expanded, and their
+// overridden instances are matched on their own.
+func (c *calendarService) SearchEvents(ctx context.Context, userId uint64, query string, filter repository.EventFilter,
+	page repository.Page) ([]repository.Event, string, error) {
+	query = strings.TrimSpace(query)
+	filter.Tags = repository.NormalizeTags(filter.Tags)
+
+	v := validator.New()
+	repository.ValidateSearchQuery(v, query)
+	repository.ValidateEventFilter(v, filter)
+	v.Check(page.Size >= 0, "page_size", "must not be negative")
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return nil, "", validationError(v)
+	}
+
+	if page.Size == 0 || page.Size > c.maxPageSize {
+		page.Size = c.maxPageSize
+	}
+
+	events, nextPageToken, err := c.calendarRepository.SearchEvents(ctx, userId, query, filter, page)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidPageToken) {
+			return nil, "", repository.ErrInvalidPageToken
+		}
+		logger.Log("msg", "failed to search events", "err", err)
+		return nil, "", errors.New("failed to search events")
+	}
+	return events, nextPageToken, nil
+}
+
+// ListTags -> Get the tags of the events which the user organizes with their usage counts, the
+// most used tag is the first
+func (c *calendarService) ListTags(ctx context.Context, userId uint64) ([]repository.TagCount, error) {
+	tags, err := c.calendarRepository.ListTags(ctx, userId)
+	if err != nil {
+		logger.Log("msg", "failed to get tags", "err", err)
+		return nil, errors.New("failed to get tags")
+	}
+	return tags, nil
+}
+
+// sharedCalendars checks the access of the user to the calendars, and returns the ones which
+// are shared with the user, and the ones which only their free/busy is shared
+func (c *calendarService) sharedCalendars(ctx context.Context, userId uint64,
+	calendarIds []primitive.ObjectID) ([]primitive.ObjectID, map[primitive.ObjectID]bool, error) {
+	var shared []primitive.ObjectID
+	busy := make(map[primitive.ObjectID]bool)
+	for _, id := range calendarIds {
+		calendar, err := c.authorize(ctx, id.Hex(), userId, repository.RoleFreeBusy)
+		if err != nil {
+			return nil, nil, err
+		}
+		if calendar.UserId == userId {
+			continue
+		}
+		shared = append(shared, id)
+		if calendar.Role == repository.RoleFreeBusy {
+			busy[id] = true
+		}
+	}
+	return shared, busy, nil
+}
+
+// expandEvents replaces the recurring events with their occurrences in the filter window
+func (c *calendarService) expandEvents(ctx context.Context, userId uint64, events []repository.Event,
+	filter repository.EventFilter) ([]repository.Event, error) {
+	// The overridden instances of the events in the shared calendars belong to their organizers
+	ids := make(map[uint64][]primitive.ObjectID)
+	for _, event := range events {
+		if event.IsRecurring() {
+			organizerId := userId
+			if event.UserId != userId && !event.IsAttendee(userId) {
+				organizerId = event.UserId
+			}
+			ids[organizerId] = append(ids[organizerId], event.Id)
+		}
+	}
+	if len(ids) == 0 {
+		return events, nil
+	}
+
+	var overrides []repository.Event
+	for organizerId, eventIds := range ids {
+		own, err := c.calendarRepository.ListOverrides(ctx, organizerId, eventIds)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, own...)
+	}
+
+	var expanded []repository.Event
+	for _, event := range events {
+		if !event.IsRecurring() {
+			expanded = append(expanded, event)
+			continue
+		}
+
+		var own []repository.Event
+		for _, override := range overrides {
+			if *override.RecurringEventId == event.Id {
+				own = append(own, override)
+			}
+		}
+
+		if filter.To.IsZero() || filter.NoExpand {
+			expanded = append(expanded, event)
+			expanded = append(expanded, own...)
+			continue
+		}
+
+		occurrences, err := event.Expand(own, filter.From, filter.To)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, occurrences...)
+	}
+
+	if !filter.To.IsZero() && !filter.NoExpand {
+		sort.SliceStable(expanded, func(i, j int) bool { return expanded[i].Start.Before(expanded[j].Start) })
+	}
+	return expanded, nil
+}
+
+// GetEvent -> Get the event from database according to eventId and userId. The user is
+// the organizer or an attendee of the event, or the calendar of the event is shared with the user.
+func (c *calendarService) GetEvent(ctx context.Context, eventId string, userId uint64) (repository.Event, error) {
+	event, err := c.calendarRepository.GetEvent(ctx, eventId, userId)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		event, err = c.calendarRepository.GetInvitedEvent(ctx, eventId, userId)
+	}
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		event, err = c.sharedEvent(ctx, eventId, userId)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to get event", "err", err)
+		return repository.Event{}, errors.New("failed to get event")
+	}
+	return *event, nil
+}
+
+// sharedEvent gets the event in a calendar which is shared with the user. Only the free/busy
+// information of the event is given if the user can only see the free/busy of the calendar.
+func (c *calendarService) sharedEvent(ctx context.Context, eventId string, userId uint64) (*repository.Event, error) {
+	event, err := c.calendarRepository.FindEvent(ctx, eventId)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := c.authorize(ctx, event.CalendarId.Hex(), userId, repository.RoleFreeBusy)
+	if err != nil {
+		return nil, err
+	}
+	if calendar.Role == repository.RoleFreeBusy {
+		busy := event.Busy()
+		return &busy, nil
+	}
+	return event, nil
+}
+
+// organizer gets the user which the event is changed on behalf of. The organizer changes the own
+// events, and the editors of a shared calendar change the events in it on behalf of its owner.
+func (c *calendarService) organizer(ctx context.Context, event repository.Event, userId uint64) (uint64, error) {
+	if event.UserId == userId {
+		return userId, nil
+	}
+	_, err := c.authorize(ctx, event.CalendarId.Hex(), userId, repository.RoleEditor)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		// The attendees see the event without an access to its calendar
+		return 0, repository.ErrNotOrganizer
+	}
+	if err != nil {
+		return 0, err
+	}
+	return event.UserId, nil
+}
+
+// UpdateEvent -> Update the given fields of the event and return the updated event.
+// If no field is given, all the updatable fields are replaced. The target selects the
+// occurrences of a recurring event which are updated. Only the organizer and the editors of
+// the event's calendar can update the event. If the event has a version, it is updated only if
+// it has not been changed since that version.
+func (c *calendarService) UpdateEvent(ctx context.Context, event repository.Event, fields []string,
+	target repository.Target) (repository.Event, error) {
+	return c.updateEvent(ctx, event, fields, target, repository.HistoryUpdate)
+}
+
+// updateEvent updates the event and records the change in its history with the action
+func (c *calendarService) updateEvent(ctx context.Context, event repository.Event, fields []string,
+	target repository.Target, action string) (repository.Event, error) {
+	if len(fields) == 0 {
+		fields = repository.UpdatableFields
+	}
+
+	for _, field := range fields {
+		if !validator.In(field, repository.UpdatableFields...) {
+			logger.Log("msg", "unknown field in update mask", "field", field)
+			return repository.Event{}, repository.NewValidationError("unknown field in update mask: " + field)
+		}
+	}
+
+	event.Tags = repository.NormalizeTags(event.Tags)
+
+	// Only the fields in the mask are written, so only their errors matter
+	v := validator.New()
+	repository.ValidateEvent(v, event)
+	for key := range v.Errors {
+		if !validator.In(key, fields...) {
+			delete(v.Errors, key)
+		}
+	}
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return repository.Event{}, validationError(v)
+	}
+
+	current, err := c.GetEvent(ctx, event.Id.Hex(), event.UserId)
+	if err != nil {
+		return repository.Event{}, err
+	}
+	if event.Version != 0 && event.Version != current.Version {
+		return repository.Event{}, repository.ErrVersionMismatch
+	}
+	userId := event.UserId
+	if event.UserId, err = c.organizer(ctx, current, userId); err != nil {
+		return repository.Event{}, err
+	}
+	if err = c.checkEventCalendar(ctx, current, &event, fields, target, userId); err != nil {
+		return repository.Event{}, err
+	}
+
+	if target.Scope != repository.AllOccurrences && !current.HasOccurrence(target.RecurrenceId) {
+		return repository.Event{}, repository.ErrNotOccurrence
+	}
+	if target.Scope == repository.ThisAndFollowing && target.RecurrenceId.Equal(current.Start) {
+		target.Scope = repository.AllOccurrences
+	}
+
+	ch := change{userId: userId, action: action}
+	var updated repository.Event
+	switch target.Scope {
+	case repository.ThisOccurrence:
+		updated, err = c.updateOccurrence(ctx, current, event, fields, target.RecurrenceId, ch)
+	case repository.ThisAndFollowing:
+		updated, err = c.updateFollowing(ctx, current, event, fields, target.RecurrenceId, ch)
+	default:
+		updated, err = c.updateAll(ctx, current, event, fields, ch)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		if errors.Is(err, repository.ErrValidation) {
+			return repository.Event{}, err
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return repository.Event{}, repository.ErrVersionMismatch
+		}
+		logger.Log("msg", "failed to update event", "err", err)
+		return repository.Event{}, errors.New("failed to update event")
+	}
+
+	return updated, nil
+}
+
+// updateAll updates the event, or all the occurrences of a recurring event
+func (c *calendarService) updateAll(ctx context.Context, current, event repository.Event,
+	fields []string, ch change) (repository.Event, error) {
+	updated := current.Clone()
+	repository.MergeEvent(&updated, event, fields)
+	if err := validateEvent(updated); err != nil {
+		return repository.Event{}, err
+	}
+
+	// The end of the recurrence depends on the start and the end of the event
+	if updated.IsRecurring() && !validator.In("recurrence", fields...) {
+		fields = append(fields[:len(fields):len(fields)], "recurrence")
+	}
+	if err := updated.SetRecurrenceEnd(); err != nil {
+		return repository.Event{}, err
+	}
+
+	if err := c.calendarRepository.UpdateEvent(ctx, &updated, fields); err != nil {
+		return repository.Event{}, err
+	}
+
+	if current.IsRecurring() && !updated.IsRecurring() {
+		err := c.calendarRepository.DeleteOverrides(ctx, updated.UserId, updated.Id, time.Time{})
+		if err != nil {
+			return repository.Event{}, err
+		}
+	}
+	if updated.IsRecurring() && updated.CalendarId != current.CalendarId {
+		err := c.calendarRepository.SetOverridesCalendar(ctx, updated.UserId, updated.Id, updated.CalendarId)
+		if err != nil {
+			return repository.Event{}, err
+		}
+	}
+
+	c.record(ctx, ch.userId, ch.action, &current, &updated)
+	return updated, nil
+}
+
+// checkEventCalendar checks the calendar which the event is moved to by the user. The calendar is kept if
+// it is not given. The occurrences of a recurring event are in the calendar of the recurring event.
+// The editors move the events between the calendars of the owner which they can edit.
+func (c *calendarService) checkEventCalendar(ctx context.Context, current repository.Event, event *repository.Event,
+	fields []string, target repository.Target, userId uint64) error {
+	if !validator.In("calendar_id", fields...) {
+		return nil
+	}
+	if event.CalendarId.IsZero() || event.CalendarId == current.CalendarId {
+		event.CalendarId = current.CalendarId
+		return nil
+	}
+
+	if target.Scope == repository.ThisOccurrence || current.RecurringEventId != nil {
+		v := validator.New()
+		v.AddError("calendar_id", "must not be changed for an occurrence")
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return validationError(v)
+	}
+
+	_, err := c.eventCalendar(ctx, event.UserId, event.CalendarId)
+	if err == nil && userId != event.UserId {
+		_, err = c.authorize(ctx, event.CalendarId.Hex(), userId, repository.RoleEditor)
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return errUnknownCalendar
+		}
+		if errors.Is(err, repository.ErrAccessDenied) {
+			return err
+		}
+	}
+	if err != nil && !errors.Is(err, errUnknownCalendar) {
+		logger.Log("msg", "failed to get calendar", "err", err)
+		return errors.New("failed to update event")
+	}
+	return err
+}
+
+// updateOccurrence creates or updates the overridden instance of the occurrence
+func (c *calendarService) updateOccurrence(ctx context.Context, current, event repository.Event, fields []string,
+	recurrenceId time.Time, ch change) (repository.Event, error) {
+	overrides, err := c.calendarRepository.ListOverrides(ctx, current.UserId, []primitive.ObjectID{current.Id})
+	if err != nil {
+		return repository.Event{}, err
 	}
 
-	return event.Id.Hex(), nil
+	override := repository.FindOverride(overrides, recurrenceId)
+	if override == nil {
+		occurrence := current.Occurrence(recurrenceId)
+		// The attachments stay on the recurring event, their contents are deleted with it
+		occurrence.Attachments = nil
+		override = &occurrence
+	}
+
+	// The history of a new overridden instance starts with the occurrence
+	before := override.Clone()
+	repository.MergeEvent(override, event, fields)
+	if err = validateEvent(*override); err != nil {
+		return repository.Event{}, err
+	}
+
+	if override.Id.IsZero() {
+		err = c.calendarRepository.CreateEvent(ctx, override)
+	} else {
+		err = c.calendarRepository.UpdateEvent(ctx, override, fields)
+	}
+	if err != nil {
+		return repository.Event{}, err
+	}
+
+	c.record(ctx, ch.userId, ch.action, &before, override)
+	return *override, nil
 }
 
-// ListEvent -> Get events from database according to userId and return events
-func (c *calendarService) ListEvent(ctx context.Context, userId uint64) ([]repository.Event, error) {
-	events, err := c.calendarRepository.ListEvent(ctx, userId)
+// updateFollowing ends the recurring event before the occurrence, and creates a new
+// recurring event with the updated fields for the occurrence and the following ones.
+func (c *calendarService) updateFollowing(ctx context.Context, current, event repository.Event, fields []string,
+	recurrenceId time.Time, ch change) (repository.Event, error) {
+	before := current
+	current = current.Clone()
+	following := current.Clone()
+	// The attachments stay on the current recurring event, their contents are deleted with it
+	following.Id, following.Attachments = primitive.NilObjectID, nil
+	following.Start = recurrenceId
+	following.End = recurrenceId.Add(current.End.Sub(current.Start))
+
+	var err error
+	if following.Recurrence, err = current.SplitRecurrence(recurrenceId); err != nil {
+		return repository.Event{}, err
+	}
+
+	repository.MergeEvent(&following, event, fields)
+	if err = validateEvent(following); err != nil {
+		return repository.Event{}, err
+	}
+	if err = following.SetRecurrenceEnd(); err != nil {
+		return repository.Event{}, err
+	}
+	if err = current.SetRecurrenceEnd(); err != nil {
+		return repository.Event{}, err
+	}
+
+	if err = c.calendarRepository.CreateEvent(ctx, &following); err != nil {
+		return repository.Event{}, err
+	}
+	if err = c.calendarRepository.UpdateEvent(ctx, &current, []string{"recurrence"}); err != nil {
+		return repository.Event{}, err
+	}
+
+	err = c.calendarRepository.MoveOverrides(ctx, current.UserId, current.Id, following.Id, recurrenceId)
 	if err != nil {
-		logger.Log("msg", "failed to get events")
-		return nil, errors.New("failed to get events")
+		return repository.Event{}, err
 	}
-	return events, nil
+	if following.CalendarId != current.CalendarId {
+		err = c.calendarRepository.SetOverridesCalendar(ctx, following.UserId, following.Id, following.CalendarId)
+		if err != nil {
+			return repository.Event{}, err
+		}
+	}
+
+	// The following occurrences are a new recurring event, the history of the current one ends before them
+	c.record(ctx, ch.userId, ch.action, &before, &current)
+	c.record(ctx, ch.userId, repository.HistoryCreate, nil, &following)
+	return following, nil
 }
 
-// DeleteEvent -> Delete event from database according to eventId
-func (c *calendarService) DeleteEvent(ctx context.Context, eventId string, userId uint64) error {
-	err := c.calendarRepository.DeleteEvent(ctx, eventId, userId)
+// DeleteEvent -> Move the event to the trash of its organizer according to eventId. The target
+// selects the occurrences of a recurring event which are deleted, the deleted occurrences are
+// excluded from the recurring event instead. Only the organizer and the editors of the event's
+// calendar can delete the event. If the version is not zero, the event is deleted only if it has
+// not been changed since that version.
+func (c *calendarService) DeleteEvent(ctx context.Context, eventId string, userId uint64, target repository.Target,
+	version int64) error {
+	err := c.deleteEvent(ctx, eventId, userId, target, version, userId)
+	if err != nil && !errors.Is(err, repository.ErrNotOccurrence) && !errors.Is(err, repository.ErrVersionMismatch) {
+		// The event is not the user's own, the editors delete it on behalf of its organizer
+		event, gerr := c.GetEvent(ctx, eventId, userId)
+		if gerr == nil {
+			var organizerId uint64
+			if organizerId, gerr = c.organizer(ctx, event, userId); gerr != nil {
+				return gerr
+			}
+			if organizerId != userId {
+				err = c.deleteEvent(ctx, eventId, organizerId, target, version, userId)
+			}
+		}
+	}
+
 	if err != nil {
+		if errors.Is(err, repository.ErrNotOccurrence) {
+			return repository.ErrNotOccurrence
+		}
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			return repository.ErrVersionMismatch
+		}
 		logger.Log("msg", "failed to delete event", "err", err)
 		return errors.New("failed to delete event")
 	}
 	return nil
 }
 
-// ServiceStatus -> A health-check mechanism
-func (c *calendarService) ServiceStatus(ctx context.Context) (int, error) {
-	if err := c.calendarRepository.ServiceStatus(ctx); err != nil {
-		logger.Log("msg", "calendar service status error")
-		return http.StatusInternalServerError, err
+// deleteEvent deletes the event of the organizer, or its occurrences which the target selects.
+// The actor is the user who deletes the event, the organizer or an editor of its calendar.
+func (c *calendarService) deleteEvent(ctx context.Context, eventId string, userId uint64, target repository.Target,
+	version int64, actorId uint64) error {
+	if target.Scope == repository.AllOccurrences {
+		return c.trashEvent(ctx, eventId, userId, version, actorId)
 	}
-	return http.StatusOK, nil
+	return c.deleteOccurrences(ctx, eventId, userId, target, version, actorId)
+}
+
+// trashEvent moves the event of the organizer at the version to the trash and records the deletion
+func (c *calendarService) trashEvent(ctx context.Context, eventId string, userId uint64, version int64,
+	actorId uint64) error {
+	event, err := c.calendarRepository.DeleteEvent(ctx, eventId, userId, version)
+	if err != nil {
+		return err
+	}
+	c.record(ctx, actorId, repository.HistoryDelete, event, nil)
+	return nil
+}
+
+// deleteOccurrences excludes the occurrence, or the occurrence and the following ones,
+// from the recurring event and deletes their overridden instances.
+func (c *calendarService) deleteOccurrences(ctx context.Context, eventId string, userId uint64,
+	target repository.Target, version int64, actorId uint64) error {
+	event, err := c.calendarRepository.GetEvent(ctx, eventId, userId)
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != event.Version {
+		return repository.ErrVersionMismatch
+	}
+	if !event.HasOccurrence(target.RecurrenceId) {
+		return repository.ErrNotOccurrence
+	}
+	before := event.Clone()
+
+	if target.Scope == repository.ThisAndFollowing {
+		if target.RecurrenceId.Equal(event.Start) {
+			return c.trashEvent(ctx, eventId, userId, version, actorId)
+		}
+
+		if _, err = event.SplitRecurrence(target.RecurrenceId); err != nil {
+			return err
+		}
+		if err = event.SetRecurrenceEnd(); err != nil {
+			return err
+		}
+		if err = c.calendarRepository.UpdateEvent(ctx, event, []string{"recurrence"}); err != nil {
+			return err
+		}
+		c.record(ctx, actorId, repository.HistoryUpdate, &before, event)
+		return c.calendarRepository.DeleteOverrides(ctx, userId, event.Id, target.RecurrenceId)
+	}
+
+	event.Recurrence.ExDates = append(event.Recurrence.ExDates, target.RecurrenceId)
+	if err = event.SetRecurrenceEnd(); err != nil {
+		return err
+	}
+	if err = c.calendarRepository.UpdateEvent(ctx, event, []string{"recurrence"}); err != nil {
+		return err
+	}
+	c.record(ctx, actorId, repository.HistoryUpdate, &before, event)
+
+	overrides, err := c.calendarRepository.ListOverrides(ctx, userId, []primitive.ObjectID{event.Id})
+	if err != nil {
+		return err
+	}
+	if override := repository.FindOverride(overrides, target.RecurrenceId); override != nil {
+		return c.trashEvent(ctx, override.Id.Hex(), userId, 0, actorId)
+	}
+	return nil
+}
+
+// BatchDeleteEvents -> Move the events of the user to the trash at once according to eventIds, and
+// return the result of each event. The overridden instances of the recurring events go with them.
+// Unlike DeleteEvent, only the events which the user organizes are deleted.
+func (c *calendarService) BatchDeleteEvents(ctx context.Context, userId uint64, eventIds []string) ([]repository.BatchResult, error) {
+	v := validator.New()
+	repository.ValidateBatchSize(v, "event_ids", len(eventIds))
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return nil, validationError(v)
+	}
+
+	results := make([]repository.BatchResult, len(eventIds))
+	var ids []primitive.ObjectID
+	for i, eventId := range eventIds {
+		id, err := primitive.ObjectIDFromHex(eventId)
+		if err != nil {
+			results[i].Err = "invalid event id"
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	var events []repository.Event
+	if len(ids) > 0 {
+		var err error
+		if events, err = c.calendarRepository.DeleteEvents(ctx, userId, ids); err != nil {
+			logger.Log("msg", "failed to delete events", "err", err)
+			return nil, errors.New("failed to delete events")
+		}
+	}
+
+	deleted := make(map[string]bool, len(events))
+	for _, event := range events {
+		deleted[event.Id.Hex()] = true
+	}
+	for i, eventId := range eventIds {
+		if results[i].Err != "" {
+			continue
+		}
+		if deleted[eventId] {
+			results[i].EventId = eventId
+		} else {
+			results[i].Err = repository.ErrRecordNotFound.Error()
+		}
+	}
+
+	c.recordAll(ctx, userId, repository.HistoryDelete, events)
+	return results, nil
+}
+
+// ListTrash -> Get a page of the trashed events of the user, the last deleted event is the
+// first, and return the events with the next page token. The page size is limited by the max
+// page size.
+func (c *calendarService) ListTrash(ctx context.Context, userId uint64, page repository.Page) ([]repository.Event,
+	string, error) {
+	v := validator.New()
+	v.Check(page.Size >= 0, "page_size", "must not be negative")
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return nil, "", validationError(v)
+	}
+
+	if page.Size == 0 || page.Size > c.maxPageSize {
+		page.Size = c.maxPageSize
+	}
+
+	events, nextPageToken, err := c.calendarRepository.ListTrash(ctx, userId, page)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidPageToken) {
+			return nil, "", repository.ErrInvalidPageToken
+		}
+		logger.Log("msg", "failed to get trash", "err", err)
+		return nil, "", errors.New("failed to get trash")
+	}
+	return events, nextPageToken, nil
+}
+
+// RestoreEvent -> Take the event of the user out of the trash and return it. The overridden
+// instances of a recurring event are restored with it.
+func (c *calendarService) RestoreEvent(ctx context.Context, eventId string, userId uint64) (repository.Event, error) {
+	event, err := c.calendarRepository.RestoreEvent(ctx, eventId, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to restore event", "err", err)
+		return repository.Event{}, errors.New("failed to restore event")
+	}
+
+	c.record(ctx, userId, repository.HistoryRestore, nil, event)
+	return *event, nil
+}
+
+// PurgeEvent -> Permanently delete the event of the user which is in the trash
+func (c *calendarService) PurgeEvent(ctx context.Context, eventId string, userId uint64) error {
+	if err := c.calendarRepository.PurgeEvent(ctx, eventId, userId); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to purge event", "err", err)
+		return errors.New("failed to purge event")
+	}
+	return nil
+}
+
+// ListHistory -> Get a page of the history of the event, the last change is the first, and return
+// the entries with the next page token. The history of a deleted event is kept, it is read by
+// the organizer and the users who can see the event's calendar. The page size is limited by the
+// max page size.
+func (c *calendarService) ListHistory(ctx context.Context, eventId string, userId uint64,
+	page repository.Page) ([]repository.HistoryEntry, string, error) {
+	v := validator.New()
+	v.Check(page.Size >= 0, "page_size", "must not be negative")
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return nil, "", validationError(v)
+	}
+
+	if page.Size == 0 || page.Size > c.maxPageSize {
+		page.Size = c.maxPageSize
+	}
+
+	// The access is checked on the last state of the event, which the last entry holds
+	last, _, err := c.calendarRepository.ListHistory(ctx, eventId, repository.Page{Size: 1})
+	if err == nil && len(last) == 0 {
+		err = repository.ErrRecordNotFound
+	}
+	if err == nil && last[0].Event.UserId != userId {
+		_, err = c.authorize(ctx, last[0].Event.CalendarId.Hex(), userId, repository.RoleViewer)
+	}
+
+	var entries []repository.HistoryEntry
+	var nextPageToken string
+	if err == nil {
+		entries, nextPageToken, err = c.calendarRepository.ListHistory(ctx, eventId, page)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil, "", repository.ErrRecordNotFound
+		}
+		if errors.Is(err, repository.ErrInvalidPageToken) {
+			return nil, "", repository.ErrInvalidPageToken
+		}
+		if errors.Is(err, repository.ErrAccessDenied) {
+			return nil, "", repository.ErrAccessDenied
+		}
+		logger.Log("msg", "failed to get history", "err", err)
+		return nil, "", errors.New("failed to get history")
+	}
+	return entries, nextPageToken, nil
+}
+
+// RevertEvent -> Set the updatable fields of the event back to their values after the change of
+// the history entry, and return the updated event. The attendees are kept. The reverts are
+// recorded in the history too. Only the organizer and the editors of the event's calendar can
+// revert the event.
+func (c *calendarService) RevertEvent(ctx context.Context, eventId, entryId string, userId uint64) (repository.Event, error) {
+	entry, err := c.calendarRepository.GetHistoryEntry(ctx, eventId, entryId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to get history entry", "err", err)
+		return repository.Event{}, errors.New("failed to revert event")
+	}
+
+	// The version of the entry is an old one, the revert applies to the current version
+	event := entry.Event
+	event.UserId, event.Version = userId, 0
+	return c.updateEvent(ctx, event, repository.UpdatableFields, repository.Target{}, repository.HistoryRevert)
+}
+
+// WatchEvents -> Send the changes of the events which the user organizes or attends until the
+// context is done. The changes after the resume token are sent first, and the watch is started
+// from now without it. The token of every change is sent with it, so the watch can be resumed
+// from the last change which the client got, on any replica.
+func (c *calendarService) WatchEvents(ctx context.Context, userId uint64, resumeToken string,
+	send func(repository.Notification) error) error {
+	var sendErr error
+	err := c.calendarRepository.WatchChanges(ctx, userId, resumeToken,
+		func(entry repository.HistoryEntry, token string) error {
+			sendErr = send(repository.NewNotification(entry, token))
+			return sendErr
+		})
+	switch {
+	case err == nil || sendErr != nil:
+		return err
+	case errors.Is(err, repository.ErrInvalidPageToken):
+		return repository.ErrInvalidPageToken
+	}
+	logger.Log("msg", "failed to watch changes", "err", err)
+	return errors.New("failed to watch events")
+}
+
+// change is the user who changes an event and the action which is recorded in its history
+type change struct {
+	userId uint64
+	action string
+}
+
+// record appends the change of the event to its history, the watches get the change from the
+// history. The event is nil before it is created and after it is deleted. The change is already
+// made, so a failure is only logged.
+func (c *calendarService) record(ctx context.Context, userId uint64, action string, before, after *repository.Event) {
+	entry, ok := historyEntry(userId, action, before, after)
+	if !ok {
+		return
+	}
+	if err := c.calendarRepository.AddHistory(ctx, &entry); err != nil {
+		logger.Log("msg", "failed to add history", "event_id", entry.EventId.Hex(), "err", err)
+	}
+}
+
+// recordAll appends the creations or the deletions of the events to their histories at once
+func (c *calendarService) recordAll(ctx context.Context, userId uint64, action string, events []repository.Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	entries := make([]*repository.HistoryEntry, len(events))
+	for i := range events {
+		before, after := &events[i], (*repository.Event)(nil)
+		if action == repository.HistoryCreate {
+			before, after = nil, &events[i]
+		}
+		entry, _ := historyEntry(userId, action, before, after)
+		entries[i] = &entry
+	}
+	if err := c.calendarRepository.AddHistories(ctx, entries); err != nil {
+		logger.Log("msg", "failed to add histories", "err", err)
+	}
+}
+
+// historyEntry creates the history entry of the change, it is false if nothing is changed
+func historyEntry(userId uint64, action string, before, after *repository.Event) (repository.HistoryEntry, bool) {
+	entry := repository.HistoryEntry{
+		UserId:    userId,
+		Action:    action,
+		Changes:   repository.Diff(before, after),
+		CreatedAt: time.Now().UTC(),
+	}
+	if after != nil {
+		entry.Event = *after
+	} else {
+		entry.Event = *before
+	}
+	entry.EventId = entry.Event.Id
+	entry.Event.DeletedAt = nil
+
+	changed := len(entry.Changes) > 0 || (action != repository.HistoryUpdate && action != repository.HistoryRevert)
+	return entry, changed
+}
+
+// InviteAttendees -> Add the attendees to the event and return the updated event. The
+// attendees which are invited before keep their status. The attendees of a recurring event
+// are set on its overridden instances too. Only the organizer and the editors of the event's
+// calendar can invite the attendees.
+func (c *calendarService) InviteAttendees(ctx context.Context, eventId string, userId uint64,
+	attendees []repository.Attendee) (repository.Event, error) {
+	v := validator.New()
+	v.Check(len(attendees) > 0, "attendees", "must be provided")
+	if repository.ValidateAttendees(v, userId, attendees); !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return repository.Event{}, validationError(v)
+	}
+
+	event, err := c.GetEvent(ctx, eventId, userId)
+	if err != nil {
+		return repository.Event{}, err
+	}
+	actorId := userId
+	if userId, err = c.organizer(ctx, event, userId); err != nil {
+		return repository.Event{}, err
+	}
+
+	invited := make(map[string]bool, len(event.Attendees))
+	merged := append([]repository.Attendee(nil), event.Attendees...)
+	for _, attendee := range event.Attendees {
+		invited[attendee.Key()] = true
+	}
+	for _, attendee := range attendees {
+		if !invited[attendee.Key()] {
+			attendee.Status = repository.NeedsAction
+			merged = append(merged, attendee)
+		}
+	}
+
+	v = validator.New()
+	if repository.ValidateAttendees(v, userId, merged); !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return repository.Event{}, validationError(v)
+	}
+
+	if err = c.calendarRepository.SetAttendees(ctx, userId, event.Id, merged); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to invite attendees", "err", err)
+		return repository.Event{}, errors.New("failed to invite attendees")
+	}
+
+	before := event
+	event.Attendees = merged
+	c.record(ctx, actorId, repository.HistoryUpdate, &before, &event)
+	return event, nil
+}
+
+// RespondEvent -> Set the participation status of the attendee on the event which the user
+// is invited to
+func (c *calendarService) RespondEvent(ctx context.Context, eventId string, userId uint64, status string) error {
+	v := validator.New()
+	v.Check(validator.In(status, repository.Responses...), "status", "must be accepted, declined or tentative")
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return validationError(v)
+	}
+
+	event, err := c.calendarRepository.GetInvitedEvent(ctx, eventId, userId)
+	if err == nil {
+		err = c.calendarRepository.SetAttendeeStatus(ctx, event.Id, userId, status)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to respond event", "err", err)
+		return errors.New("failed to respond event")
+	}
+
+	before := event.Clone()
+	for i := range event.Attendees {
+		if event.Attendees[i].UserId == userId {
+			event.Attendees[i].Status = status
+		}
+	}
+	c.record(ctx, userId, repository.HistoryUpdate, &before, event)
+	return nil
+}
+
+// AddAttachment -> Attach the file with the content to the event and return the updated event.
+// The attachments are added to the recurring events, not to their overridden instances. Only the
+// organizer and the editors of the event's calendar can add the attachments.
+func (c *calendarService) AddAttachment(ctx context.Context, eventId string, userId uint64,
+	attachment repository.Attachment, content []byte) (repository.Event, error) {
+	if mediaType, _, err := mime.ParseMediaType(attachment.ContentType); err == nil {
+		attachment.ContentType = mediaType
+	}
+	attachment.Size = int64(len(content))
+
+	v := validator.New()
+	if repository.ValidateAttachment(v, attachment); !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return repository.Event{}, validationError(v)
+	}
+
+	event, err := c.GetEvent(ctx, eventId, userId)
+	if err != nil {
+		return repository.Event{}, err
+	}
+	actorId := userId
+	if userId, err = c.organizer(ctx, event, userId); err != nil {
+		return repository.Event{}, err
+	}
+
+	v.Check(event.RecurringEventId == nil, "event_id", "must not be an occurrence, attach to the recurring event")
+	v.Check(len(event.Attachments) < repository.MaxAttachments, "attachments", "must not be more than 10")
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return repository.Event{}, validationError(v)
+	}
+
+	updated, err := c.calendarRepository.AddAttachment(ctx, eventId, userId, &attachment, bytes.NewReader(content))
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to add attachment", "err", err)
+		return repository.Event{}, errors.New("failed to add attachment")
+	}
+
+	c.record(ctx, actorId, repository.HistoryUpdate, &event, updated)
+	return *updated, nil
+}
+
+// GetAttachment -> Get the attachment of the event with its content. The users who can see the
+// event can get its attachments, except the ones who only see the free/busy of its calendar.
+func (c *calendarService) GetAttachment(ctx context.Context, eventId string, userId uint64,
+	attachmentId string) (repository.Attachment, []byte, error) {
+	event, err := c.GetEvent(ctx, eventId, userId)
+	if err != nil {
+		return repository.Attachment{}, nil, err
+	}
+	attachment := event.FindAttachment(attachmentId)
+	if attachment == nil {
+		return repository.Attachment{}, nil, repository.ErrRecordNotFound
+	}
+
+	var content []byte
+	r, err := c.calendarRepository.OpenAttachment(ctx, attachmentId)
+	if err == nil {
+		content, err = io.ReadAll(io.LimitReader(r, repository.MaxAttachmentSize))
+		r.Close()
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.Attachment{}, nil, repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to get attachment", "err", err)
+		return repository.Attachment{}, nil, errors.New("failed to get attachment")
+	}
+	return *attachment, content, nil
+}
+
+// DeleteAttachment -> Remove the attachment from the event, delete its content and return the
+// updated event. Only the organizer and the editors of the event's calendar can delete the
+// attachments.
+func (c *calendarService) DeleteAttachment(ctx context.Context, eventId string, userId uint64,
+	attachmentId string) (repository.Event, error) {
+	event, err := c.GetEvent(ctx, eventId, userId)
+	if err != nil {
+		return repository.Event{}, err
+	}
+	actorId := userId
+	if userId, err = c.organizer(ctx, event, userId); err != nil {
+		return repository.Event{}, err
+	}
+
+	updated, err := c.calendarRepository.DeleteAttachment(ctx, eventId, userId, attachmentId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to delete attachment", "err", err)
+		return repository.Event{}, errors.New("failed to delete attachment")
+	}
+
+	c.record(ctx, actorId, repository.HistoryUpdate, &event, updated)
+	return *updated, nil
+}
+
+// ImportEvents -> Create the events, or update the ones imported before with the same UID,
+// and return the result of each event. The recurring events are imported before their
+// overridden instances.
+func (c *calendarService) ImportEvents(ctx context.Context, userId uint64, events []repository.Event) ([]repository.ImportResult, error) {
+	if len(events) > repository.MaxImportEvents {
+		return nil, repository.NewValidationError(fmt.Sprintf("must not import more than %d events at once", repository.MaxImportEvents))
+	}
+
+	order := make([]int, len(events))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return events[order[i]].RecurrenceId == nil && events[order[j]].RecurrenceId != nil
+	})
+
+	results := make([]repository.ImportResult, len(events))
+	for _, i := range order {
+		results[i] = c.importEvent(ctx, userId, events[i])
+	}
+	return results, nil
+}
+
+// importEvent imports the event and reports the result
+func (c *calendarService) importEvent(ctx context.Context, userId uint64, event repository.Event) repository.ImportResult {
+	result := repository.ImportResult{UID: event.UID, RecurrenceId: event.RecurrenceId}
+
+	event.Id, event.UserId, event.RecurringEventId = primitive.NilObjectID, userId, nil
+	event.CalendarId = primitive.NilObjectID
+	if event.RecurrenceId != nil {
+		// The overridden instances are in the resource of their recurring event
+		event.Recurrence, event.Resource = nil, ""
+	}
+
+	v := validator.New()
+	repository.ValidateEvent(v, event)
+	v.Check(event.UID != "", "uid", "must be provided")
+	if !v.Valid() {
+		result.Err = fmt.Sprintf("%v", v.Errors)
+		return result
+	}
+
+	existing, err := c.findImportedEvent(ctx, userId, event.UID)
+	if err == nil && existing.DeletedAt != nil {
+		// The re-imported events come back from the trash
+		if existing, err = c.calendarRepository.RestoreEvent(ctx, existing.Id.Hex(), userId); err == nil {
+			c.record(ctx, userId, repository.HistoryRestore, nil, existing)
+		}
+	}
+	if event.RecurrenceId != nil {
+		if err == nil {
+			existing, err = c.findImportedOverride(ctx, existing, &event)
+		} else if errors.Is(err, repository.ErrRecordNotFound) {
+			result.Err = "recurring event is not found"
+			return result
+		}
+	}
+	if err != nil && !errors.Is(err, repository.ErrRecordNotFound) {
+		if errors.Is(err, repository.ErrNotOccurrence) {
+			result.Err = err.Error()
+			return result
+		}
+		logger.Log("msg", "failed to import event", "err", err)
+		result.Err = "failed to import event"
+		return result
+	}
+
+	if err = event.SetRecurrenceEnd(); err == nil {
+		if existing == nil {
+			// The new recurring and single events are added to the default calendar
+			if event.CalendarId, err = c.eventCalendar(ctx, userId, event.CalendarId); err == nil {
+				err = c.calendarRepository.CreateEvent(ctx, &event)
+				result.Created = true
+			}
+		} else {
+			// The iCalendar objects do not carry the reminders and the calendar, they are kept
+			event.Id, event.UID, event.Reminders = existing.Id, existing.UID, existing.Reminders
+			event.CalendarId, event.Resource = existing.CalendarId, existing.Resource
+			err = c.calendarRepository.UpdateEvent(ctx, &event, repository.UpdatableFields)
+		}
+	}
+	if err == nil && existing != nil && existing.IsRecurring() && !event.IsRecurring() {
+		err = c.calendarRepository.DeleteOverrides(ctx, userId, existing.Id, time.Time{})
+	}
+	if err != nil {
+		logger.Log("msg", "failed to import event", "err", err)
+		result.Created, result.Err = false, "failed to import event"
+		return result
+	}
+
+	if existing == nil {
+		c.record(ctx, userId, repository.HistoryCreate, nil, &event)
+	} else {
+		c.record(ctx, userId, repository.HistoryUpdate, existing, &event)
+	}
+	result.EventId = event.Id.Hex()
+	return result
+}
+
+// findImportedEvent gets the recurring or single event with the UID. The events which are
+// exported by kalenderium are found by the event id in their UID.
+func (c *calendarService) findImportedEvent(ctx context.Context, userId uint64, uid string) (*repository.Event, error) {
+	event, err := c.calendarRepository.GetEventByUID(ctx, userId, uid)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		if id, ok := repository.EventIdFromUID(uid); ok {
+			return c.calendarRepository.GetEvent(ctx, id.Hex(), userId)
+		}
+	}
+	return event, err
+}
+
+// findImportedOverride links the overridden instance to the recurring event, and gets the
+// instance which is imported before, if any
+func (c *calendarService) findImportedOverride(ctx context.Context, master *repository.Event,
+	event *repository.Event) (*repository.Event, error) {
+	if !master.HasOccurrence(*event.RecurrenceId) {
+		return nil, repository.ErrNotOccurrence
+	}
+	event.RecurringEventId, event.UID, event.CalendarId = &master.Id, master.UID, master.CalendarId
+
+	overrides, err := c.calendarRepository.ListOverrides(ctx, master.UserId, []primitive.ObjectID{master.Id})
+	if err != nil {
+		return nil, err
+	}
+	if override := repository.FindOverride(overrides, *event.RecurrenceId); override != nil {
+		return override, nil
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+// GetEventByUID -> Get the event with the iCalendar UID together with its overridden instances
+func (c *calendarService) GetEventByUID(ctx context.Context, uid string, userId uint64) (repository.Event,
+	[]repository.Event, error) {
+	event, err := c.findImportedEvent(ctx, userId, uid)
+	if err == nil && (event.RecurringEventId != nil || event.DeletedAt != nil) {
+		err = repository.ErrRecordNotFound
+	}
+	return c.withOverrides(ctx, userId, event, err)
+}
+
+// GetEventByResource -> Get the event of the CalDAV resource together with its overridden
+// instances. The resources which are not named by the clients are named after the UID.
+func (c *calendarService) GetEventByResource(ctx context.Context, name string, userId uint64) (repository.Event,
+	[]repository.Event, error) {
+	event, err := c.calendarRepository.GetEventByResource(ctx, userId, name)
+	if errors.Is(err, repository.ErrRecordNotFound) {
+		event, err = c.findImportedEvent(ctx, userId, name)
+		if err == nil && (event.RecurringEventId != nil || event.DeletedAt != nil || event.Resource != "") {
+			err = repository.ErrRecordNotFound
+		}
+	}
+	return c.withOverrides(ctx, userId, event, err)
+}
+
+// withOverrides gets the overridden instances of the event which is found, if it is a recurring
+// event
+func (c *calendarService) withOverrides(ctx context.Context, userId uint64, event *repository.Event,
+	err error) (repository.Event, []repository.Event, error) {
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.Event{}, nil, repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to get event", "err", err)
+		return repository.Event{}, nil, errors.New("failed to get event")
+	}
+
+	if !event.IsRecurring() {
+		return *event, nil, nil
+	}
+	overrides, err := c.calendarRepository.ListOverrides(ctx, userId, []primitive.ObjectID{event.Id})
+	if err != nil {
+		logger.Log("msg", "failed to list overrides", "err", err)
+		return repository.Event{}, nil, errors.New("failed to get event")
+	}
+	return *event, overrides, nil
+}
+
+// CreateCalendar -> Add the calendar of the user and return it. The calendar is private if
+// the visibility is not given.
+func (c *calendarService) CreateCalendar(ctx context.Context, calendar repository.Calendar) (repository.Calendar, error) {
+	calendar.Id, calendar.Default = primitive.NilObjectID, false
+	if calendar.Visibility == "" {
+		calendar.Visibility = repository.VisibilityPrivate
+	}
+
+	v := validator.New()
+	if repository.ValidateCalendar(v, calendar); !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return repository.Calendar{}, validationError(v)
+	}
+
+	// The default calendar is the first calendar of the user
+	_, err := c.calendarRepository.DefaultCalendar(ctx, calendar.UserId)
+	if err == nil {
+		err = c.calendarRepository.CreateCalendar(ctx, &calendar)
+	}
+	if err != nil {
+		logger.Log("msg", "failed to create calendar", "err", err)
+		return repository.Calendar{}, errors.New("failed to create calendar")
+	}
+	return calendar, nil
+}
+
+// ListCalendars -> Get the calendars of the user, starting with the default calendar, and
+// the calendars which are shared with the user
+func (c *calendarService) ListCalendars(ctx context.Context, userId uint64) ([]repository.Calendar, error) {
+	_, err := c.calendarRepository.DefaultCalendar(ctx, userId)
+	if err != nil {
+		logger.Log("msg", "failed to get default calendar", "err", err)
+		return nil, errors.New("failed to get calendars")
+	}
+
+	calendars, err := c.calendarRepository.ListCalendars(ctx, userId)
+	if err != nil {
+		logger.Log("msg", "failed to get calendars", "err", err)
+		return nil, errors.New("failed to get calendars")
+	}
+
+	shared, err := c.calendarRepository.ListSharedCalendars(ctx, userId)
+	if err != nil {
+		logger.Log("msg", "failed to get shared calendars", "err", err)
+		return nil, errors.New("failed to get calendars")
+	}
+	return append(calendars, shared...), nil
+}
+
+// UpdateCalendar -> Update the given fields of the calendar, e.g. rename it, and return the
+// updated calendar. If no field is given, all the updatable fields are replaced. The calendar
+// is updated by its owners.
+func (c *calendarService) UpdateCalendar(ctx context.Context, calendar repository.Calendar,
+	fields []string) (repository.Calendar, error) {
+	if len(fields) == 0 {
+		fields = repository.UpdatableCalendarFields
+	}
+	for _, field := range fields {
+		if !validator.In(field, repository.UpdatableCalendarFields...) {
+			logger.Log("msg", "unknown field in update mask", "field", field)
+			return repository.Calendar{}, repository.NewValidationError("unknown field in update mask: " + field)
+		}
+	}
+
+	current, err := c.authorize(ctx, calendar.Id.Hex(), calendar.UserId, repository.RoleOwner)
+	if err != nil {
+		return repository.Calendar{}, err
+	}
+
+	repository.MergeCalendar(current, calendar, fields)
+	v := validator.New()
+	if repository.ValidateCalendar(v, *current); !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return repository.Calendar{}, validationError(v)
+	}
+
+	role := current.Role
+	if err = c.calendarRepository.UpdateCalendar(ctx, current, fields); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.Calendar{}, repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to update calendar", "err", err)
+		return repository.Calendar{}, errors.New("failed to update calendar")
+	}
+	current.Role = role
+	return *current, nil
+}
+
+// DeleteCalendar -> Delete the calendar of the user together with its grants, and move its events
+// to the trash. The default calendar can not be deleted, and the shared calendars are only deleted
+// by their users.
+func (c *calendarService) DeleteCalendar(ctx context.Context, calendarId string, userId uint64) error {
+	calendar, err := c.authorize(ctx, calendarId, userId, repository.RoleOwner)
+	if err != nil {
+		return err
+	}
+	if calendar.UserId != userId {
+		return repository.ErrAccessDenied
+	}
+	if calendar.Default {
+		return repository.ErrDefaultCalendar
+	}
+
+	events, err := c.calendarRepository.DeleteCalendar(ctx, calendar.Id, userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to delete calendar", "err", err)
+		return errors.New("failed to delete calendar")
+	}
+
+	c.recordAll(ctx, userId, repository.HistoryDelete, events)
+	return nil
+}
+
+// GrantAccess -> Share the calendar with the user of the grant in the role, or change the role
+// of the user if the calendar is already shared with the user. Only the owners of the calendar
+// can share it.
+func (c *calendarService) GrantAccess(ctx context.Context, grant repository.Grant, userId uint64) (repository.Grant, error) {
+	v := validator.New()
+	if repository.ValidateGrant(v, grant); !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return repository.Grant{}, validationError(v)
+	}
+
+	calendar, err := c.authorize(ctx, grant.CalendarId.Hex(), userId, repository.RoleOwner)
+	if err != nil {
+		return repository.Grant{}, err
+	}
+	if grant.UserId == calendar.UserId {
+		v.AddError("user_id", "must not be the user of the calendar")
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return repository.Grant{}, validationError(v)
+	}
+
+	if err = c.calendarRepository.SetGrant(ctx, &grant); err != nil {
+		logger.Log("msg", "failed to grant access", "err", err)
+		return repository.Grant{}, errors.New("failed to grant access")
+	}
+	return grant, nil
+}
+
+// ListGrants -> Get the grants of the calendar. Only the owners of the calendar can see them.
+func (c *calendarService) ListGrants(ctx context.Context, calendarId string, userId uint64) ([]repository.Grant, error) {
+	calendar, err := c.authorize(ctx, calendarId, userId, repository.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := c.calendarRepository.ListGrants(ctx, calendar.Id)
+	if err != nil {
+		logger.Log("msg", "failed to get grants", "err", err)
+		return nil, errors.New("failed to get grants")
+	}
+	return grants, nil
+}
+
+// RevokeAccess -> Stop sharing the calendar with the grantee. The owners of the calendar revoke
+// the grants, and the users can leave the calendars which are shared with them.
+func (c *calendarService) RevokeAccess(ctx context.Context, calendarId string, userId, granteeId uint64) error {
+	role := repository.RoleOwner
+	if granteeId == userId {
+		role = repository.RoleFreeBusy
+	}
+
+	calendar, err := c.authorize(ctx, calendarId, userId, role)
+	if err != nil {
+		return err
+	}
+
+	if err = c.calendarRepository.DeleteGrant(ctx, calendar.Id, granteeId); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to revoke access", "err", err)
+		return errors.New("failed to revoke access")
+	}
+	return nil
+}
+
+// FreeBusy -> Get the merged busy intervals of the users in the window without the contents of
+// their events. The user sees the own busy time, and the busy time of the other users in their
+// public calendars and the calendars which are shared with the user.
+func (c *calendarService) FreeBusy(ctx context.Context, userId uint64, userIds []uint64,
+	from, to time.Time) ([]repository.FreeBusy, error) {
+	v := validator.New()
+	repository.ValidateFreeBusy(v, userIds, from, to)
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return nil, validationError(v)
+	}
+
+	shared, err := c.sharedCalendarIds(ctx, userId)
+	if err != nil {
+		logger.Log("msg", "failed to get shared calendars", "err", err)
+		return nil, errors.New("failed to get free/busy")
+	}
+
+	var freeBusy []repository.FreeBusy
+	for _, id := range uniqueIds(userIds) {
+		participant, err := c.participant(ctx, userId, id, shared, from, to)
+		if errors.Is(err, repository.ErrAccessDenied) {
+			freeBusy = append(freeBusy, repository.FreeBusy{UserId: id, Busy: []repository.Interval{}, Err: err.Error()})
+			continue
+		}
+		if err != nil {
+			logger.Log("msg", "failed to get busy events", "err", err)
+			return nil, errors.New("failed to get free/busy")
+		}
+		freeBusy = append(freeBusy, repository.FreeBusy{UserId: id, Busy: participant.Busy})
+	}
+	return freeBusy, nil
+}
+
+// FindMeetingSlots -> Get the ranked slots in the window which the user and the other users are
+// all free in, and which are in the working hours of everyone in their own time zones. The busy
+// time of the other users has to be visible to the user.
+func (c *calendarService) FindMeetingSlots(ctx context.Context, userId uint64,
+	query repository.SlotQuery) ([]repository.Interval, error) {
+	v := validator.New()
+	repository.ValidateSlotQuery(v, query)
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return nil, validationError(v)
+	}
+
+	shared, err := c.sharedCalendarIds(ctx, userId)
+	if err != nil {
+		logger.Log("msg", "failed to get shared calendars", "err", err)
+		return nil, errors.New("failed to find meeting slots")
+	}
+
+	var participants []repository.Participant
+	for _, id := range uniqueIds(append([]uint64{userId}, query.UserIds...)) {
+		participant, err := c.participant(ctx, userId, id, shared, query.From, query.To)
+		if err != nil {
+			if errors.Is(err, repository.ErrAccessDenied) {
+				return nil, repository.ErrAccessDenied
+			}
+			logger.Log("msg", "failed to get busy events", "err", err)
+			return nil, errors.New("failed to find meeting slots")
+		}
+		participants = append(participants, participant)
+	}
+	return repository.FindSlots(participants, query), nil
+}
+
+// sharedCalendarIds gets the ids of the calendars which are shared with the user
+func (c *calendarService) sharedCalendarIds(ctx context.Context, userId uint64) (map[primitive.ObjectID]bool, error) {
+	calendars, err := c.calendarRepository.ListSharedCalendars(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[primitive.ObjectID]bool, len(calendars))
+	for _, calendar := range calendars {
+		ids[calendar.Id] = true
+	}
+	return ids, nil
+}
+
+// participant gets the busy intervals of the user in the window which the requester can see,
+// and the time zone of the user. The requester sees the own events, and the events in the public
+// calendars and the shared calendars of the other users, ErrAccessDenied if there is none of them.
+func (c *calendarService) participant(ctx context.Context, requesterId, userId uint64,
+	shared map[primitive.ObjectID]bool, from, to time.Time) (repository.Participant, error) {
+	calendars, err := c.calendarRepository.ListCalendars(ctx, userId)
+	if err != nil {
+		return repository.Participant{}, err
+	}
+
+	participant := repository.Participant{UserId: userId, Location: time.UTC}
+	filter := repository.EventFilter{From: from, To: to}
+	for _, calendar := range calendars {
+		if calendar.Default {
+			participant.Location = calendar.Location()
+		}
+		if userId != requesterId && (calendar.Visibility == repository.VisibilityPublic || shared[calendar.Id]) {
+			filter.CalendarIds = append(filter.CalendarIds, calendar.Id)
+		}
+	}
+	if userId != requesterId && len(filter.CalendarIds) == 0 {
+		return repository.Participant{}, repository.ErrAccessDenied
+	}
+
+	events, err := c.busyEvents(ctx, userId, filter)
+	if err != nil {
+		return repository.Participant{}, err
+	}
+	participant.Busy = repository.BusyIntervals(userId, events, from, to)
+	return participant, nil
+}
+
+// uniqueIds removes the repeated ids keeping the order
+func uniqueIds(ids []uint64) []uint64 {
+	seen := make(map[uint64]bool, len(ids))
+	var unique []uint64
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// busyEvents gets all the events of the user in the filter, the recurring events are expanded
+func (c *calendarService) busyEvents(ctx context.Context, userId uint64,
+	filter repository.EventFilter) ([]repository.Event, error) {
+	var events []repository.Event
+	page := repository.Page{Size: c.maxPageSize}
+	for {
+		list, nextPageToken, err := c.calendarRepository.ListEvent(ctx, userId, filter, page)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, list...)
+		if nextPageToken == "" {
+			break
+		}
+		page.Token = nextPageToken
+	}
+	return c.expandEvents(ctx, userId, events, filter)
+}
+
+// authorize gets the calendar if the user has it, or it is shared with the user with the role or
+// a higher one. The role of the user is set on the shared calendar. The calendars which are not
+// shared with the user are not found.
+func (c *calendarService) authorize(ctx context.Context, calendarId string, userId uint64,
+	role string) (*repository.Calendar, error) {
+	calendar, err := c.calendarRepository.FindCalendar(ctx, calendarId)
+	if err == nil && calendar.UserId != userId {
+		var grant *repository.Grant
+		if grant, err = c.calendarRepository.GetGrant(ctx, calendar.Id, userId); err == nil {
+			calendar.Role = grant.Role
+		}
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil, repository.ErrRecordNotFound
+		}
+		logger.Log("msg", "failed to get calendar access", "err", err)
+		return nil, errors.New("failed to get calendar access")
+	}
+
+	if calendar.Role != "" && !repository.Allows(calendar.Role, role) {
+		return nil, repository.ErrAccessDenied
+	}
+	return calendar, nil
+}
+
+// errUnknownCalendar is the validation error of the events which are added to a calendar
+// the organizer does not have
+var errUnknownCalendar = repository.NewValidationError(fmt.Sprintf("%v", map[string]string{"calendar_id": "must be a calendar of the user"}))
+
+// eventCalendar gets the calendar which the event of the user is added to. The events without
+// a calendar are added to the default calendar.
+func (c *calendarService) eventCalendar(ctx context.Context, userId uint64,
+	calendarId primitive.ObjectID) (primitive.ObjectID, error) {
+	if calendarId.IsZero() {
+		calendar, err := c.calendarRepository.DefaultCalendar(ctx, userId)
+		if err != nil {
+			return primitive.NilObjectID, err
+		}
+		return calendar.Id, nil
+	}
+
+	_, err := c.calendarRepository.GetCalendar(ctx, calendarId.Hex(), userId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return primitive.NilObjectID, errUnknownCalendar
+		}
+		return primitive.NilObjectID, err
+	}
+	return calendarId, nil
+}
+
+// ServiceStatus -> A health-check mechanism
+func (c *calendarService) ServiceStatus(ctx context.Context) (int, error) {
+	if err := c.calendarRepository.ServiceStatus(ctx); err != nil {
+		logger.Log("msg", "calendar service status error")
+		return http.StatusInternalServerError, err
+	}
+	return http.StatusOK, nil
+}
+
+// validationError creates the error of the failed checks of the validator, it is returned as an
+// invalid argument
+func validationError(v *validator.Validator) error {
+	return repository.NewValidationError(fmt.Sprintf("%v", v.Errors))
+}
+
+// validateEvent validates the whole event before it is written
+func validateEvent(event repository.Event) error {
+	v := validator.New()
+	repository.ValidateEvent(v, event)
+	v.Check(event.RecurringEventId == nil || event.Recurrence == nil, "recurrence", "must not be set on an occurrence")
+	if !v.Valid() {
+		logger.Log(fmt.Sprintf("validation error: %v", v.Errors))
+		return validationError(v)
+	}
+	return nil
 }
 
 var logger log.Logger
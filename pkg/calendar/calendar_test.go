@@ -3,16 +3,23 @@ package calendar
 import (
 	"context"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/3n0ugh/kalenderium/internal/config"
 	"github.com/3n0ugh/kalenderium/pkg/calendar/pb"
+	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
 	"github.com/3n0ugh/kalenderium/pkg/calendar/repository/mock"
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -22,7 +29,7 @@ func Server(ctx context.Context) (pb.CalendarClient, func()) {
 	listener := bufconn.Listen(buffer)
 
 	repo := mock.NewCalendarRepository()
-	svc := NewService(repo)
+	svc := NewService(repo, config.CalendarServiceConfigurations{MaxPageSize: 50})
 	ep := New(svc)
 
 	baseServer := grpc.NewServer(grpc.UnaryInterceptor(kitgrpc.Interceptor))
@@ -48,19 +55,44 @@ func Server(ctx context.Context) (pb.CalendarClient, func()) {
 	return client, closer
 }
 
+// occurrence returns the start of the i-th occurrence of the weekly mock recurring event
+func occurrence(i int) time.Time {
+	return mock.RecurringEvent.Start.AddDate(0, 0, 7*i)
+}
+
+// timeZoneOccurrence returns the occurrence of the time zone event which starts at the time
+func timeZoneOccurrence(start time.Time) *pb.Event {
+	return &pb.Event{
+		RecurringEventId: mock.TimeZoneEvent.Id.Hex(),
+		UserId:           mock.TimeZoneEvent.UserId,
+		Name:             mock.TimeZoneEvent.Name,
+		Start:            timestamppb.New(start),
+		End:              timestamppb.New(start.Add(time.Hour)),
+		Color:            mock.TimeZoneEvent.Color,
+	}
+}
+
 // Custom struct comparer for CreateEvent handler test
 func deepEqualCreateEvent(x, y *pb.CreateEventReply) bool {
 	if x.Err != y.Err {
 		return false
 	}
-	if x.EventId != y.EventId {
+	if x.EventId != y.EventId || len(x.Conflicts) != len(y.Conflicts) {
 		return false
 	}
+	for i, conflict := range x.Conflicts {
+		if conflict.Id != y.Conflicts[i].Id || !proto.Equal(conflict.Start, y.Conflicts[i].Start) {
+			return false
+		}
+	}
 	return true
 }
 
 // Custom struct comparer for ListEvent handler test
 func deepEqualListEvent(x, y *pb.ListEventReply) bool {
+	if len(x.Events) != len(y.Events) || x.NextPageToken != y.NextPageToken {
+		return false
+	}
 	for i, event := range x.Events {
 		if event.Name != y.Events[i].Name {
 			return false
@@ -87,6 +119,75 @@ func deepEqualListEvent(x, y *pb.ListEventReply) bool {
 	return true
 }
 
+// Custom struct comparer for SearchEvents handler test, the ranking is compared by the ids
+func deepEqualSearchEvents(x, y *pb.SearchEventsReply) bool {
+	if len(x.Events) != len(y.Events) || x.NextPageToken != y.NextPageToken {
+		return false
+	}
+	for i, event := range x.Events {
+		if event.Id != y.Events[i].Id || event.Name != y.Events[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// Custom struct comparer for GetEvent handler test
+func deepEqualGetEvent(x, y *pb.GetEventReply) bool {
+	if x.Err != y.Err {
+		return false
+	}
+	return deepEqualListEvent(&pb.ListEventReply{Events: []*pb.Event{x.Event}},
+		&pb.ListEventReply{Events: []*pb.Event{y.Event}})
+}
+
+// Custom struct comparer for UpdateEvent handler test
+func deepEqualUpdateEvent(x, y *pb.UpdateEventReply) bool {
+	if x.Err != y.Err {
+		return false
+	}
+	// The version of the expected event is compared if it is given
+	if x.Event.Version != 0 && x.Event.Version != y.Event.Version {
+		return false
+	}
+	return deepEqualListEvent(&pb.ListEventReply{Events: []*pb.Event{x.Event}},
+		&pb.ListEventReply{Events: []*pb.Event{y.Event}})
+}
+
+// Custom struct comparer for InviteAttendees handler test
+func deepEqualInviteAttendees(x, y *pb.InviteAttendeesReply) bool {
+	if x.Err != y.Err || len(x.Event.Attendees) != len(y.Event.Attendees) {
+		return false
+	}
+	for i, attendee := range x.Event.Attendees {
+		if attendee.UserId != y.Event.Attendees[i].UserId || attendee.Email != y.Event.Attendees[i].Email ||
+			attendee.Status != y.Event.Attendees[i].Status {
+			return false
+		}
+	}
+	return deepEqualListEvent(&pb.ListEventReply{Events: []*pb.Event{x.Event}},
+		&pb.ListEventReply{Events: []*pb.Event{y.Event}})
+}
+
+// Custom struct comparer for the calendar handler tests, the ids of the expected calendars
+// are compared if they are given
+func deepEqualCalendars(x, y []*pb.UserCalendar) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i, calendar := range x {
+		if calendar.Id != "" && calendar.Id != y[i].Id {
+			return false
+		}
+		if calendar.UserId != y[i].UserId || calendar.Name != y[i].Name || calendar.Color != y[i].Color ||
+			calendar.Visibility != y[i].Visibility || calendar.IsDefault != y[i].IsDefault || calendar.Role != y[i].Role ||
+			calendar.TimeZone != y[i].TimeZone {
+			return false
+		}
+	}
+	return true
+}
+
 // Custom struct comparer for DeleteEvent handler test
 func deepEqualDeleteEvent(x, y *pb.DeleteEventReply) bool {
 	return x.Err == y.Err
@@ -142,9 +243,9 @@ func TestCalendarService_CreateEvent(t *testing.T) {
 			expected: expectation{
 				out: &pb.CreateEventReply{
 					EventId: "",
-					Err:     "rpc error: code = Unknown desc = map[name:must be provided]",
+					Err:     "rpc error: code = InvalidArgument desc = map[name:must be provided]",
 				},
-				err: errors.New("rpc error: code = Unknown desc = map[name:must be provided]"),
+				err: errors.New("rpc error: code = InvalidArgument desc = map[name:must be provided]"),
 			},
 		},
 		"Empty_Details": {
@@ -182,9 +283,9 @@ func TestCalendarService_CreateEvent(t *testing.T) {
 			expected: expectation{
 				out: &pb.CreateEventReply{
 					EventId: "6285f86bb502f9d335124b04",
-					Err:     "rpc error: code = Unknown desc = map[color:must be provided]",
+					Err:     "rpc error: code = InvalidArgument desc = map[color:must be provided]",
 				},
-				err: errors.New("rpc error: code = Unknown desc = map[color:must be provided]"),
+				err: errors.New("rpc error: code = InvalidArgument desc = map[color:must be provided]"),
 			},
 		},
 		"Empty_Start": {
@@ -202,9 +303,9 @@ func TestCalendarService_CreateEvent(t *testing.T) {
 			expected: expectation{
 				out: &pb.CreateEventReply{
 					EventId: "6285f86bb502f9d335124b04",
-					Err:     "rpc error: code = Unknown desc = map[start:must be provided]",
+					Err:     "rpc error: code = InvalidArgument desc = map[start:must be provided]",
 				},
-				err: errors.New("rpc error: code = Unknown desc = map[start:must be provided]"),
+				err: errors.New("rpc error: code = InvalidArgument desc = map[start:must be provided]"),
 			},
 		},
 		"Empty_End": {
@@ -222,9 +323,188 @@ func TestCalendarService_CreateEvent(t *testing.T) {
 			expected: expectation{
 				out: &pb.CreateEventReply{
 					EventId: "6285f86bb502f9d335124b04",
-					Err:     "rpc error: code = Unknown desc = map[end:must be provided]",
+					Err:     "rpc error: code = InvalidArgument desc = map[end:must be provided]",
+				},
+				err: errors.New("rpc error: code = InvalidArgument desc = map[end:must be provided]"),
+			},
+		},
+		"End_Before_Start": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId: 22,
+					Name:   "Test",
+					Start:  timestamppb.New(time.Time{}.Add(time.Hour)),
+					End:    timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:  "#FFFFFF",
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[end:must not be before start]"),
+			},
+		},
+		"Unknown_Time_Zone": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId:   22,
+					Name:     "Test",
+					Start:    timestamppb.New(time.Time{}.Add(time.Second)),
+					End:      timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:    "#FFFFFF",
+					TimeZone: "Europe/Atlantis",
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[time_zone:must be a valid IANA time zone]"),
+			},
+		},
+		"Time_Zone": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId:   22,
+					Name:     "Test",
+					Start:    timestamppb.New(time.Time{}.Add(time.Second)),
+					End:      timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:    "#FFFFFF",
+					TimeZone: "Europe/Istanbul",
+				},
+			},
+			expected: expectation{
+				out: &pb.CreateEventReply{EventId: "6285f86bb502f9d335124b04"},
+			},
+		},
+		"All_Day": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId: 22,
+					Name:   "Test",
+					Start:  timestamppb.New(time.Date(2022, time.May, 1, 0, 0, 0, 0, time.UTC)),
+					End:    timestamppb.New(time.Date(2022, time.May, 2, 0, 0, 0, 0, time.UTC)),
+					Color:  "#FFFFFF",
+					AllDay: true,
+				},
+			},
+			expected: expectation{
+				out: &pb.CreateEventReply{EventId: "6285f86bb502f9d335124b04"},
+			},
+		},
+		"All_Day_Not_Date": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId: 22,
+					Name:   "Test",
+					Start:  timestamppb.New(time.Date(2022, time.May, 1, 9, 0, 0, 0, time.UTC)),
+					End:    timestamppb.New(time.Date(2022, time.May, 2, 0, 0, 0, 0, time.UTC)),
+					Color:  "#FFFFFF",
+					AllDay: true,
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[start:must be a date at midnight UTC for all-day events]"),
+			},
+		},
+		"Reminders": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId:    22,
+					Name:      "Test",
+					Start:     timestamppb.New(time.Time{}.Add(time.Second)),
+					End:       timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:     "#FFFFFF",
+					Reminders: []*pb.Reminder{{Minutes: 15, Method: "email"}, {Minutes: 0, Method: "webhook"}},
+				},
+			},
+			expected: expectation{
+				out: &pb.CreateEventReply{EventId: "6285f86bb502f9d335124b04"},
+			},
+		},
+		"Calendar": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId:     22,
+					Name:       "Test",
+					Start:      timestamppb.New(time.Time{}.Add(time.Second)),
+					End:        timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:      "#FFFFFF",
+					CalendarId: mock.WorkCalendar.Id.Hex(),
+				},
+			},
+			expected: expectation{
+				out: &pb.CreateEventReply{EventId: "6285f86bb502f9d335124b04"},
+			},
+		},
+		"Shared_Calendar_Editor": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId:     27,
+					Name:       "Test",
+					Start:      timestamppb.New(time.Time{}.Add(time.Second)),
+					End:        timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:      "#FFFFFF",
+					CalendarId: mock.WorkCalendar.Id.Hex(),
+				},
+			},
+			expected: expectation{
+				out: &pb.CreateEventReply{EventId: "6285f86bb502f9d335124b04"},
+			},
+		},
+		"Shared_Calendar_Viewer": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId:     29,
+					Name:       "Test",
+					Start:      timestamppb.New(time.Time{}.Add(time.Second)),
+					End:        timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:      "#FFFFFF",
+					CalendarId: mock.WorkCalendar.Id.Hex(),
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = the calendar is not shared with the required access"),
+			},
+		},
+		"Unknown_Calendar": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId:     23,
+					Name:       "Test",
+					Start:      timestamppb.New(time.Time{}.Add(time.Second)),
+					End:        timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:      "#FFFFFF",
+					CalendarId: mock.WorkCalendar.Id.Hex(),
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[calendar_id:must be a calendar of the user]"),
+			},
+		},
+		"Unknown_Reminder_Method": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId:    22,
+					Name:      "Test",
+					Start:     timestamppb.New(time.Time{}.Add(time.Second)),
+					End:       timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:     "#FFFFFF",
+					Reminders: []*pb.Reminder{{Minutes: 15, Method: "sms"}},
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[reminders:must be sent by email or webhook]"),
+			},
+		},
+		"Duplicate_Reminders": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId:    22,
+					Name:      "Test",
+					Start:     timestamppb.New(time.Time{}.Add(time.Second)),
+					End:       timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:     "#FFFFFF",
+					Reminders: []*pb.Reminder{{Minutes: 15, Method: "email"}, {Minutes: 15, Method: "email"}},
 				},
-				err: errors.New("rpc error: code = Unknown desc = map[end:must be provided]"),
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[reminders:must not have duplicates]"),
 			},
 		},
 		"Long_Name": {
@@ -242,9 +522,9 @@ func TestCalendarService_CreateEvent(t *testing.T) {
 			expected: expectation{
 				out: &pb.CreateEventReply{
 					EventId: "6285f86bb502f9d335124b04",
-					Err:     "rpc error: code = Unknown desc = map[name:must not be more than 80 bytes long]",
+					Err:     "rpc error: code = InvalidArgument desc = map[name:must not be more than 80 bytes long]",
 				},
-				err: errors.New("rpc error: code = Unknown desc = map[name:must not be more than 80 bytes long]"),
+				err: errors.New("rpc error: code = InvalidArgument desc = map[name:must not be more than 80 bytes long]"),
 			},
 		},
 		"Long_Color": {
@@ -262,9 +542,9 @@ func TestCalendarService_CreateEvent(t *testing.T) {
 			expected: expectation{
 				out: &pb.CreateEventReply{
 					EventId: "6285f86bb502f9d335124b04",
-					Err:     "rpc error: code = Unknown desc = map[color:must be 7 bytes long]",
+					Err:     "rpc error: code = InvalidArgument desc = map[color:must be 7 bytes long]",
 				},
-				err: errors.New("rpc error: code = Unknown desc = map[color:must be 7 bytes long]"),
+				err: errors.New("rpc error: code = InvalidArgument desc = map[color:must be 7 bytes long]"),
 			},
 		},
 		"Long_Details": {
@@ -282,9 +562,9 @@ func TestCalendarService_CreateEvent(t *testing.T) {
 			expected: expectation{
 				out: &pb.CreateEventReply{
 					EventId: "6285f86bb502f9d335124b04",
-					Err:     "rpc error: code = Unknown desc = map[details:must not be more than 1100 bytes long]",
+					Err:     "rpc error: code = InvalidArgument desc = map[details:must not be more than 1100 bytes long]",
 				},
-				err: errors.New("rpc error: code = Unknown desc = map[details:must not be more than 1100 bytes long]"),
+				err: errors.New("rpc error: code = InvalidArgument desc = map[details:must not be more than 1100 bytes long]"),
 			},
 		},
 		"Wrong_Color": {
@@ -302,9 +582,156 @@ func TestCalendarService_CreateEvent(t *testing.T) {
 			expected: expectation{
 				out: &pb.CreateEventReply{
 					EventId: "6285f86bb502f9d335124b04",
-					Err:     "rpc error: code = Unknown desc = map[color:must be start with #]",
+					Err:     "rpc error: code = InvalidArgument desc = map[color:must be start with #]",
+				},
+				err: errors.New("rpc error: code = InvalidArgument desc = map[color:must be start with #]"),
+			},
+		},
+		"Conflict_Warn": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId: 24,
+					Name:   "Review",
+					Start:  timestamppb.New(mock.Override.Start.Add(30 * time.Minute)),
+					End:    timestamppb.New(mock.Override.End.Add(30 * time.Minute)),
+					Color:  "#FFFFFF",
+				},
+			},
+			expected: expectation{
+				out: &pb.CreateEventReply{
+					EventId:   "6285f86bb502f9d335124b04",
+					Conflicts: []*pb.Event{{Id: mock.Override.Id.Hex(), Start: timestamppb.New(mock.Override.Start)}},
+				},
+			},
+		},
+		"Conflict_Recurring": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId:     24,
+					Name:       "Daily",
+					Start:      timestamppb.New(mock.RecurringEvent.Start.AddDate(0, 0, 13).Add(30 * time.Minute)),
+					End:        timestamppb.New(mock.RecurringEvent.End.AddDate(0, 0, 13).Add(30 * time.Minute)),
+					Color:      "#FFFFFF",
+					Recurrence: &pb.Recurrence{Rrule: "FREQ=DAILY;COUNT=3"},
+				},
+				ConflictPolicy: "warn",
+			},
+			expected: expectation{
+				out: &pb.CreateEventReply{
+					EventId: "6285f86bb502f9d335124b04",
+					Conflicts: []*pb.Event{{RecurringEventId: mock.RecurringEvent.Id.Hex(),
+						Start: timestamppb.New(mock.RecurringEvent.Start.AddDate(0, 0, 14))}},
+				},
+			},
+		},
+		"Conflict_Transparent": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId:      24,
+					Name:        "Focus",
+					Start:       timestamppb.New(mock.Override.Start),
+					End:         timestamppb.New(mock.Override.End),
+					Color:       "#FFFFFF",
+					Transparent: true,
+				},
+				ConflictPolicy: "reject",
+			},
+			expected: expectation{
+				out: &pb.CreateEventReply{EventId: "6285f86bb502f9d335124b04"},
+			},
+		},
+		"Conflict_Allow": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId: 24,
+					Name:   "Review",
+					Start:  timestamppb.New(mock.Override.Start),
+					End:    timestamppb.New(mock.Override.End),
+					Color:  "#FFFFFF",
+				},
+				ConflictPolicy: "allow",
+			},
+			expected: expectation{
+				out: &pb.CreateEventReply{EventId: "6285f86bb502f9d335124b04"},
+			},
+		},
+		"Conflict_Reject": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId: 24,
+					Name:   "Review",
+					Start:  timestamppb.New(mock.Override.Start),
+					End:    timestamppb.New(mock.Override.End),
+					Color:  "#FFFFFF",
+				},
+				ConflictPolicy: "reject",
+			},
+			expected: expectation{
+				out: &pb.CreateEventReply{
+					Err:       "the event overlaps the other events of the organizer",
+					Conflicts: []*pb.Event{{Id: mock.Override.Id.Hex(), Start: timestamppb.New(mock.Override.Start)}},
+				},
+				err: errors.New("rpc error: code = AlreadyExists desc = the event overlaps the other events of the organizer"),
+			},
+		},
+		"Tags": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId: 22,
+					Name:   "Test",
+					Start:  timestamppb.New(time.Time{}.Add(time.Second)),
+					End:    timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:  "#FFFFFF",
+					Tags:   []string{"Client", " client ", "Work"},
+				},
+			},
+			expected: expectation{
+				out: &pb.CreateEventReply{EventId: "6285f86bb502f9d335124b04"},
+			},
+		},
+		"Empty_Tag": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId: 22,
+					Name:   "Test",
+					Start:  timestamppb.New(time.Time{}.Add(time.Second)),
+					End:    timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:  "#FFFFFF",
+					Tags:   []string{"work", " "},
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[tags:must not have empty tags]"),
+			},
+		},
+		"Long_Tag": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId: 22,
+					Name:   "Test",
+					Start:  timestamppb.New(time.Time{}.Add(time.Second)),
+					End:    timestamppb.New(time.Time{}.Add(time.Second)),
+					Color:  "#FFFFFF",
+					Tags:   []string{strings.Repeat("a", 33)},
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[tags:must not have tags more than 32 bytes long]"),
+			},
+		},
+		"Invalid_Conflict_Policy": {
+			in: &pb.CreateEventRequest{
+				Event: &pb.Event{
+					UserId: 24,
+					Name:   "Review",
+					Start:  timestamppb.New(mock.Override.Start),
+					End:    timestamppb.New(mock.Override.End),
+					Color:  "#FFFFFF",
 				},
-				err: errors.New("rpc error: code = Unknown desc = map[color:must be start with #]"),
+				ConflictPolicy: "ignore",
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[conflict_policy:must be allow, warn or reject]"),
 			},
 		},
 	}
@@ -316,6 +743,12 @@ func TestCalendarService_CreateEvent(t *testing.T) {
 				if tt.expected.err.Error() != err.Error() {
 					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
 				}
+				// The rejected events have the conflicts in the details of the error
+				for _, detail := range status.Convert(err).Details() {
+					if reply, ok := detail.(*pb.CreateEventReply); !ok || !deepEqualCreateEvent(tt.expected.out, reply) {
+						t.Errorf("Details -> \nWant: %q;\nGot: %q", tt.expected.out, detail)
+					}
+				}
 			} else {
 				if !deepEqualCreateEvent(tt.expected.out, out) {
 					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
@@ -341,6 +774,26 @@ func TestCalendarService_ListEvent(t *testing.T) {
 		in       *pb.ListEventRequest
 		expected expectation
 	}{
+		"Invited": {
+			in: &pb.ListEventRequest{
+				UserId: mock.Event2.Attendees[0].UserId,
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						{
+							Id:      mock.Event2.Id.Hex(),
+							UserId:  mock.Event2.UserId,
+							Name:    mock.Event2.Name,
+							Details: mock.Event2.Details,
+							Start:   timestamppb.New(mock.Event2.Start),
+							End:     timestamppb.New(mock.Event2.End),
+							Color:   mock.Event2.Color,
+						},
+					},
+				},
+			},
+		},
 		"Must_Success": {
 			in: &pb.ListEventRequest{
 				UserId: mock.Event.UserId,
@@ -372,15 +825,38 @@ func TestCalendarService_ListEvent(t *testing.T) {
 				err: nil,
 			},
 		},
-		"User_Has_No_Event": {
+		"Tags": {
 			in: &pb.ListEventRequest{
-				UserId: mock.Event.UserId + 1,
+				UserId: mock.Event.UserId,
+				Tags:   []string{"Music", "work"},
 			},
 			expected: expectation{
 				out: &pb.ListEventReply{
 					Events: []*pb.Event{
 						{
-							Id:      mock.Event.Id.String(),
+							Id:      mock.Event2.Id.Hex(),
+							UserId:  mock.Event2.UserId,
+							Name:    mock.Event2.Name,
+							Details: mock.Event2.Details,
+							Start:   timestamppb.New(mock.Event2.Start),
+							End:     timestamppb.New(mock.Event2.End),
+							Color:   mock.Event2.Color,
+						},
+					},
+				},
+			},
+		},
+		"In_Time_Window": {
+			in: &pb.ListEventRequest{
+				UserId: mock.Event.UserId,
+				From:   timestamppb.New(mock.Event.Start),
+				To:     timestamppb.New(mock.Event.End.Add(time.Hour)),
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						{
+							Id:      mock.Event.Id.Hex(),
 							UserId:  mock.Event.UserId,
 							Name:    mock.Event.Name,
 							Details: mock.Event.Details,
@@ -389,7 +865,7 @@ func TestCalendarService_ListEvent(t *testing.T) {
 							Color:   mock.Event.Color,
 						},
 						{
-							Id:      mock.Event2.Id.String(),
+							Id:      mock.Event2.Id.Hex(),
 							UserId:  mock.Event2.UserId,
 							Name:    mock.Event2.Name,
 							Details: mock.Event2.Details,
@@ -398,98 +874,3082 @@ func TestCalendarService_ListEvent(t *testing.T) {
 							Color:   mock.Event2.Color,
 						},
 					},
-					Err: "rpc error: code = Unknown desc = failed to get events",
+					Err: "",
 				},
-				err: errors.New("rpc error: code = Unknown desc = failed to get events"),
+				err: nil,
 			},
 		},
-	}
-
-	for scenario, tt := range tests {
-		t.Run(scenario, func(t *testing.T) {
-			out, err := client.ListEvent(ctx, tt.in)
+		"Out_Of_Time_Window": {
+			in: &pb.ListEventRequest{
+				UserId: mock.Event.UserId,
+				From:   timestamppb.New(mock.Event.End.Add(time.Hour)),
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{Events: nil, Err: ""},
+				err: nil,
+			},
+		},
+		"Invalid_Time_Window": {
+			in: &pb.ListEventRequest{
+				UserId: mock.Event.UserId,
+				From:   timestamppb.New(mock.Event.End.Add(time.Hour)),
+				To:     timestamppb.New(mock.Event.End),
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[to:must be after from]"),
+			},
+		},
+		"First_Page": {
+			in: &pb.ListEventRequest{
+				UserId:   mock.Event.UserId,
+				PageSize: 1,
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						{
+							Id:      mock.Event.Id.Hex(),
+							UserId:  mock.Event.UserId,
+							Name:    mock.Event.Name,
+							Details: mock.Event.Details,
+							Start:   timestamppb.New(mock.Event.Start),
+							End:     timestamppb.New(mock.Event.End),
+							Color:   mock.Event.Color,
+						},
+					},
+					NextPageToken: "1",
+					Err:           "",
+				},
+				err: nil,
+			},
+		},
+		"Last_Page": {
+			in: &pb.ListEventRequest{
+				UserId:    mock.Event.UserId,
+				PageSize:  1,
+				PageToken: "1",
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						{
+							Id:      mock.Event2.Id.Hex(),
+							UserId:  mock.Event2.UserId,
+							Name:    mock.Event2.Name,
+							Details: mock.Event2.Details,
+							Start:   timestamppb.New(mock.Event2.Start),
+							End:     timestamppb.New(mock.Event2.End),
+							Color:   mock.Event2.Color,
+						},
+					},
+					NextPageToken: "",
+					Err:           "",
+				},
+				err: nil,
+			},
+		},
+		"Calendar_Filter": {
+			in: &pb.ListEventRequest{
+				UserId:      mock.Event.UserId,
+				CalendarIds: []string{mock.WorkCalendar.Id.Hex()},
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						{
+							Id:      mock.Event2.Id.Hex(),
+							UserId:  mock.Event2.UserId,
+							Name:    mock.Event2.Name,
+							Details: mock.Event2.Details,
+							Start:   timestamppb.New(mock.Event2.Start),
+							End:     timestamppb.New(mock.Event2.End),
+							Color:   mock.Event2.Color,
+						},
+					},
+				},
+			},
+		},
+		"Shared_Calendar": {
+			in: &pb.ListEventRequest{
+				UserId:      29,
+				CalendarIds: []string{mock.WorkCalendar.Id.Hex()},
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						&pb.Event{
+							Id:      mock.Event2.Id.Hex(),
+							UserId:  mock.Event2.UserId,
+							Name:    mock.Event2.Name,
+							Details: mock.Event2.Details,
+							Start:   timestamppb.New(mock.Event2.Start),
+							End:     timestamppb.New(mock.Event2.End),
+							Color:   mock.Event2.Color,
+						},
+					},
+				},
+			},
+		},
+		"Shared_Calendar_FreeBusy": {
+			in: &pb.ListEventRequest{
+				UserId:      28,
+				CalendarIds: []string{mock.WorkCalendar.Id.Hex()},
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						&pb.Event{
+							Id:     mock.Event2.Id.Hex(),
+							UserId: mock.Event2.UserId,
+							Start:  timestamppb.New(mock.Event2.Start),
+							End:    timestamppb.New(mock.Event2.End),
+						},
+					},
+				},
+			},
+		},
+		"Not_Shared_Calendar": {
+			in: &pb.ListEventRequest{
+				UserId:      23,
+				CalendarIds: []string{mock.WorkCalendar.Id.Hex()},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+		"Invalid_Calendar_Id": {
+			in: &pb.ListEventRequest{
+				UserId:      mock.Event.UserId,
+				CalendarIds: []string{"invalid"},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = invalid calendar id"),
+			},
+		},
+		"Invalid_Page_Token": {
+			in: &pb.ListEventRequest{
+				UserId:    mock.Event.UserId,
+				PageToken: "invalid",
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = invalid page token"),
+			},
+		},
+		"Negative_Page_Size": {
+			in: &pb.ListEventRequest{
+				UserId:   mock.Event.UserId,
+				PageSize: -1,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[page_size:must not be negative]"),
+			},
+		},
+		"Recurring_Event_In_Window": {
+			in: &pb.ListEventRequest{
+				UserId: mock.RecurringEvent.UserId,
+				From:   timestamppb.New(occurrence(1)),
+				To:     timestamppb.New(occurrence(3)),
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						{
+							Id:      mock.Override.Id.Hex(),
+							UserId:  mock.Override.UserId,
+							Name:    mock.Override.Name,
+							Details: mock.Override.Details,
+							Start:   timestamppb.New(mock.Override.Start),
+							End:     timestamppb.New(mock.Override.End),
+							Color:   mock.Override.Color,
+						},
+						{
+							RecurringEventId: mock.RecurringEvent.Id.Hex(),
+							UserId:           mock.RecurringEvent.UserId,
+							Name:             mock.RecurringEvent.Name,
+							Details:          mock.RecurringEvent.Details,
+							Start:            timestamppb.New(occurrence(2)),
+							End:              timestamppb.New(occurrence(2).Add(time.Hour)),
+							Color:            mock.RecurringEvent.Color,
+						},
+					},
+				},
+			},
+		},
+		"Time_Zone_Event_Across_DST": {
+			in: &pb.ListEventRequest{
+				UserId: mock.TimeZoneEvent.UserId,
+				From:   timestamppb.New(mock.TimeZoneEvent.Start),
+				To:     timestamppb.New(mock.TimeZoneEvent.Start.AddDate(0, 1, 0)),
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						timeZoneOccurrence(time.Date(2022, time.March, 21, 8, 0, 0, 0, time.UTC)),
+						timeZoneOccurrence(time.Date(2022, time.March, 28, 7, 0, 0, 0, time.UTC)),
+						timeZoneOccurrence(time.Date(2022, time.April, 4, 7, 0, 0, 0, time.UTC)),
+					},
+				},
+			},
+		},
+		"Recurring_Event_Not_Expanded": {
+			in: &pb.ListEventRequest{
+				UserId:   mock.RecurringEvent.UserId,
+				From:     timestamppb.New(occurrence(1)),
+				To:       timestamppb.New(occurrence(3)),
+				NoExpand: true,
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						{
+							Id:      mock.RecurringEvent.Id.Hex(),
+							UserId:  mock.RecurringEvent.UserId,
+							Name:    mock.RecurringEvent.Name,
+							Details: mock.RecurringEvent.Details,
+							Start:   timestamppb.New(mock.RecurringEvent.Start),
+							End:     timestamppb.New(mock.RecurringEvent.End),
+							Color:   mock.RecurringEvent.Color,
+						},
+						{
+							Id:      mock.Override.Id.Hex(),
+							UserId:  mock.Override.UserId,
+							Name:    mock.Override.Name,
+							Details: mock.Override.Details,
+							Start:   timestamppb.New(mock.Override.Start),
+							End:     timestamppb.New(mock.Override.End),
+							Color:   mock.Override.Color,
+						},
+					},
+				},
+			},
+		},
+		"Recurring_Event_Without_Window_End": {
+			in: &pb.ListEventRequest{
+				UserId: mock.RecurringEvent.UserId,
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						{
+							Id:      mock.RecurringEvent.Id.Hex(),
+							UserId:  mock.RecurringEvent.UserId,
+							Name:    mock.RecurringEvent.Name,
+							Details: mock.RecurringEvent.Details,
+							Start:   timestamppb.New(mock.RecurringEvent.Start),
+							End:     timestamppb.New(mock.RecurringEvent.End),
+							Color:   mock.RecurringEvent.Color,
+						},
+						{
+							Id:      mock.Override.Id.Hex(),
+							UserId:  mock.Override.UserId,
+							Name:    mock.Override.Name,
+							Details: mock.Override.Details,
+							Start:   timestamppb.New(mock.Override.Start),
+							End:     timestamppb.New(mock.Override.End),
+							Color:   mock.Override.Color,
+						},
+					},
+				},
+			},
+		},
+		"User_Has_No_Event": {
+			in: &pb.ListEventRequest{
+				UserId: mock.Event.UserId + 1,
+			},
+			expected: expectation{
+				out: &pb.ListEventReply{
+					Events: []*pb.Event{
+						{
+							Id:      mock.Event.Id.String(),
+							UserId:  mock.Event.UserId,
+							Name:    mock.Event.Name,
+							Details: mock.Event.Details,
+							Start:   timestamppb.New(mock.Event.Start),
+							End:     timestamppb.New(mock.Event.End),
+							Color:   mock.Event.Color,
+						},
+						{
+							Id:      mock.Event2.Id.String(),
+							UserId:  mock.Event2.UserId,
+							Name:    mock.Event2.Name,
+							Details: mock.Event2.Details,
+							Start:   timestamppb.New(mock.Event2.Start),
+							End:     timestamppb.New(mock.Event2.End),
+							Color:   mock.Event2.Color,
+						},
+					},
+					Err: "rpc error: code = Unknown desc = failed to get events",
+				},
+				err: errors.New("rpc error: code = Unknown desc = failed to get events"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.ListEvent(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+			} else {
+				if !deepEqualListEvent(tt.expected.out, out) {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+				}
+			}
+
+		})
+	}
+}
+
+func TestCalendarService_ListEvent_Pages(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	in := &pb.ListEventRequest{
+		UserId: mock.RecurringEvent.UserId,
+		From:   timestamppb.New(occurrence(0)),
+		To:     timestamppb.New(occurrence(4)),
+	}
+	all, err := client.ListEvent(ctx, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The pages of the window are taken from the expanded occurrences
+	in.PageSize = 1
+	var paged []*pb.Event
+	for {
+		out, err := client.ListEvent(ctx, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(out.Events) != 1 {
+			t.Fatalf("expected 1 event in the page, got %d", len(out.Events))
+		}
+		paged = append(paged, out.Events...)
+		if out.NextPageToken == "" {
+			break
+		}
+		in.PageToken = out.NextPageToken
+	}
+
+	if len(all.Events) < 3 || !deepEqualListEvent(all, &pb.ListEventReply{Events: paged}) {
+		t.Errorf("Out -> \nWant: %q;\nGot: %q", all.Events, paged)
+	}
+}
+
+func TestCalendarService_SearchEvents(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.SearchEventsReply
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.SearchEventsRequest
+		expected expectation
+	}{
+		"Name": {
+			in: &pb.SearchEventsRequest{
+				UserId: mock.Event.UserId,
+				Query:  "spring",
+			},
+			expected: expectation{
+				out: &pb.SearchEventsReply{
+					Events: []*pb.Event{{Id: mock.Event.Id.Hex(), Name: mock.Event.Name}},
+				},
+			},
+		},
+		"Invited": {
+			in: &pb.SearchEventsRequest{
+				UserId: mock.Event2.Attendees[0].UserId,
+				Query:  "Sapphire",
+			},
+			expected: expectation{
+				out: &pb.SearchEventsReply{
+					Events: []*pb.Event{{Id: mock.Event2.Id.Hex(), Name: mock.Event2.Name}},
+				},
+			},
+		},
+		"Ranked": {
+			in: &pb.SearchEventsRequest{
+				UserId: mock.RecurringEvent.UserId,
+				Query:  "weekly afternoon",
+			},
+			expected: expectation{
+				out: &pb.SearchEventsReply{
+					Events: []*pb.Event{
+						{Id: mock.Override.Id.Hex(), Name: mock.Override.Name},
+						{Id: mock.RecurringEvent.Id.Hex(), Name: mock.RecurringEvent.Name},
+					},
+				},
+			},
+		},
+		"Page": {
+			in: &pb.SearchEventsRequest{
+				UserId:   mock.RecurringEvent.UserId,
+				Query:    "weekly afternoon",
+				PageSize: 1,
+			},
+			expected: expectation{
+				out: &pb.SearchEventsReply{
+					Events:        []*pb.Event{{Id: mock.Override.Id.Hex(), Name: mock.Override.Name}},
+					NextPageToken: "1",
+				},
+			},
+		},
+		"Next_Page": {
+			in: &pb.SearchEventsRequest{
+				UserId:    mock.RecurringEvent.UserId,
+				Query:     "weekly afternoon",
+				PageSize:  1,
+				PageToken: "1",
+			},
+			expected: expectation{
+				out: &pb.SearchEventsReply{
+					Events: []*pb.Event{{Id: mock.RecurringEvent.Id.Hex(), Name: mock.RecurringEvent.Name}},
+				},
+			},
+		},
+		"Time_Window": {
+			in: &pb.SearchEventsRequest{
+				UserId: mock.RecurringEvent.UserId,
+				Query:  "weekly",
+				From:   timestamppb.New(time.Date(2022, time.January, 20, 0, 0, 0, 0, time.UTC)),
+			},
+			expected: expectation{
+				out: &pb.SearchEventsReply{
+					Events: []*pb.Event{{Id: mock.RecurringEvent.Id.Hex(), Name: mock.RecurringEvent.Name}},
+				},
+			},
+		},
+		"Tags": {
+			in: &pb.SearchEventsRequest{
+				UserId: mock.Event.UserId,
+				Query:  "time",
+				Tags:   []string{"personal"},
+			},
+			expected: expectation{
+				out: &pb.SearchEventsReply{
+					Events: []*pb.Event{{Id: mock.Event.Id.Hex(), Name: mock.Event.Name}},
+				},
+			},
+		},
+		"No_Match": {
+			in: &pb.SearchEventsRequest{
+				UserId: mock.Event.UserId,
+				Query:  "winter",
+			},
+			expected: expectation{
+				out: &pb.SearchEventsReply{},
+			},
+		},
+		"Empty_Query": {
+			in: &pb.SearchEventsRequest{
+				UserId: mock.Event.UserId,
+				Query:  "  ",
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[q:must be provided]"),
+			},
+		},
+		"Invalid_Page_Token": {
+			in: &pb.SearchEventsRequest{
+				UserId:    mock.Event.UserId,
+				Query:     "time",
+				PageToken: "invalid",
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = invalid page token"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.SearchEvents(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+			} else {
+				if tt.expected.err != nil {
+					t.Errorf("Err -> Want: \n%q\n;Got: nil", tt.expected.err)
+				} else if !deepEqualSearchEvents(tt.expected.out, out) {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+				}
+			}
+		})
+	}
+}
+
+func TestCalendarService_GetEvent(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.GetEventReply
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.GetEventRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.GetEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  mock.Event2.UserId,
+			},
+			expected: expectation{
+				out: &pb.GetEventReply{
+					Event: &pb.Event{
+						Id:      mock.Event2.Id.Hex(),
+						UserId:  mock.Event2.UserId,
+						Name:    mock.Event2.Name,
+						Details: mock.Event2.Details,
+						Start:   timestamppb.New(mock.Event2.Start),
+						End:     timestamppb.New(mock.Event2.End),
+						Color:   mock.Event2.Color,
+					},
+					Err: "",
+				},
+				err: nil,
+			},
+		},
+		"Not_Exist_Event": {
+			in: &pb.GetEventRequest{
+				EventId: "6285f86bb502f9d335124b04",
+				UserId:  mock.Event.UserId,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+		"Invited": {
+			in: &pb.GetEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  mock.Event2.Attendees[0].UserId,
+			},
+			expected: expectation{
+				out: &pb.GetEventReply{
+					Event: &pb.Event{
+						Id:      mock.Event2.Id.Hex(),
+						UserId:  mock.Event2.UserId,
+						Name:    mock.Event2.Name,
+						Details: mock.Event2.Details,
+						Start:   timestamppb.New(mock.Event2.Start),
+						End:     timestamppb.New(mock.Event2.End),
+						Color:   mock.Event2.Color,
+					},
+				},
+			},
+		},
+		"Shared_Calendar": {
+			in: &pb.GetEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  29,
+			},
+			expected: expectation{
+				out: &pb.GetEventReply{
+					Event: &pb.Event{
+						Id:      mock.Event2.Id.Hex(),
+						UserId:  mock.Event2.UserId,
+						Name:    mock.Event2.Name,
+						Details: mock.Event2.Details,
+						Start:   timestamppb.New(mock.Event2.Start),
+						End:     timestamppb.New(mock.Event2.End),
+						Color:   mock.Event2.Color,
+					},
+				},
+			},
+		},
+		"Shared_Calendar_FreeBusy": {
+			in: &pb.GetEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  28,
+			},
+			expected: expectation{
+				out: &pb.GetEventReply{
+					Event: &pb.Event{
+						Id:     mock.Event2.Id.Hex(),
+						UserId: mock.Event2.UserId,
+						Start:  timestamppb.New(mock.Event2.Start),
+						End:    timestamppb.New(mock.Event2.End),
+					},
+				},
+			},
+		},
+		"Not_Owner": {
+			in: &pb.GetEventRequest{
+				EventId: mock.Event.Id.Hex(),
+				UserId:  mock.Event.UserId + 1,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.GetEvent(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+			} else {
+				if tt.expected.out == nil || !deepEqualGetEvent(tt.expected.out, out) {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+				}
+			}
+
+		})
+	}
+}
+
+func TestCalendarService_UpdateEvent(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.UpdateEventReply
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.UpdateEventRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.Event.Id.Hex(),
+					UserId: mock.Event.UserId,
+					Name:   "Autumn Time",
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				out: &pb.UpdateEventReply{
+					Event: &pb.Event{
+						Id:      mock.Event.Id.Hex(),
+						UserId:  mock.Event.UserId,
+						Name:    "Autumn Time",
+						Details: mock.Event.Details,
+						Start:   timestamppb.New(mock.Event.Start),
+						End:     timestamppb.New(mock.Event.End),
+						Color:   mock.Event.Color,
+					},
+					Err: "",
+				},
+				err: nil,
+			},
+		},
+		"Empty_Name": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.Event.Id.Hex(),
+					UserId: mock.Event.UserId,
+					Name:   "",
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[name:must be provided]"),
+			},
+		},
+		"Empty_Mask": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.Event.Id.Hex(),
+					UserId: mock.Event.UserId,
+					Name:   "Autumn Time",
+				},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[color:must be provided]"),
+			},
+		},
+		"Unknown_Field": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.Event.Id.Hex(),
+					UserId: mock.Event.UserId,
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"user_id"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = unknown field in update mask: user_id"),
+			},
+		},
+		"Invalid_Event_Id": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     "invalid",
+					UserId: mock.Event.UserId,
+					Name:   "Autumn Time",
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = invalid event id: the provided hex string is not a valid ObjectID"),
+			},
+		},
+		"This_Occurrence": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.RecurringEvent.Id.Hex(),
+					UserId: mock.RecurringEvent.UserId,
+					Name:   "Retrospective",
+				},
+				UpdateMask:   &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+				Scope:        pb.RecurrenceScope_SCOPE_THIS,
+				RecurrenceId: timestamppb.New(occurrence(2)),
+			},
+			expected: expectation{
+				out: &pb.UpdateEventReply{
+					Event: &pb.Event{
+						Id:      "6285f86bb502f9d335124b04",
+						UserId:  mock.RecurringEvent.UserId,
+						Name:    "Retrospective",
+						Details: mock.RecurringEvent.Details,
+						Start:   timestamppb.New(occurrence(2)),
+						End:     timestamppb.New(occurrence(2).Add(time.Hour)),
+						Color:   mock.RecurringEvent.Color,
+					},
+				},
+			},
+		},
+		"Overridden_Occurrence": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.RecurringEvent.Id.Hex(),
+					UserId: mock.RecurringEvent.UserId,
+					Name:   "Retrospective",
+				},
+				UpdateMask:   &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+				Scope:        pb.RecurrenceScope_SCOPE_THIS,
+				RecurrenceId: timestamppb.New(occurrence(1)),
+			},
+			expected: expectation{
+				out: &pb.UpdateEventReply{
+					Event: &pb.Event{
+						Id:      mock.Override.Id.Hex(),
+						UserId:  mock.Override.UserId,
+						Name:    "Retrospective",
+						Details: mock.Override.Details,
+						Start:   timestamppb.New(mock.Override.Start),
+						End:     timestamppb.New(mock.Override.End),
+						Color:   mock.Override.Color,
+					},
+				},
+			},
+		},
+		"This_And_Following": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.RecurringEvent.Id.Hex(),
+					UserId: mock.RecurringEvent.UserId,
+					Color:  "#FF0000",
+				},
+				UpdateMask:   &fieldmaskpb.FieldMask{Paths: []string{"color"}},
+				Scope:        pb.RecurrenceScope_SCOPE_THIS_AND_FOLLOWING,
+				RecurrenceId: timestamppb.New(occurrence(2)),
+			},
+			expected: expectation{
+				out: &pb.UpdateEventReply{
+					Event: &pb.Event{
+						Id:      "6285f86bb502f9d335124b04",
+						UserId:  mock.RecurringEvent.UserId,
+						Name:    mock.RecurringEvent.Name,
+						Details: mock.RecurringEvent.Details,
+						Start:   timestamppb.New(occurrence(2)),
+						End:     timestamppb.New(occurrence(2).Add(time.Hour)),
+						Color:   "#FF0000",
+					},
+				},
+			},
+		},
+		"Not_An_Occurrence": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.RecurringEvent.Id.Hex(),
+					UserId: mock.RecurringEvent.UserId,
+					Name:   "Retrospective",
+				},
+				UpdateMask:   &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+				Scope:        pb.RecurrenceScope_SCOPE_THIS,
+				RecurrenceId: timestamppb.New(occurrence(4)),
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = recurrence id is not an occurrence of the event"),
+			},
+		},
+		"Not_Recurring_Event": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.Event.Id.Hex(),
+					UserId: mock.Event.UserId,
+					Name:   "Autumn Time",
+				},
+				UpdateMask:   &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+				Scope:        pb.RecurrenceScope_SCOPE_THIS,
+				RecurrenceId: timestamppb.New(mock.Event.Start),
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = recurrence id is not an occurrence of the event"),
+			},
+		},
+		"Invalid_Recurrence": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:         mock.RecurringEvent.Id.Hex(),
+					UserId:     mock.RecurringEvent.UserId,
+					Recurrence: &pb.Recurrence{Rrule: "FREQ=HOURLY"},
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"recurrence"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[recurrence:must have a valid RRULE]"),
+			},
+		},
+		"Not_Owner": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.Event.Id.Hex(),
+					UserId: mock.Event.UserId + 1,
+					Name:   "Autumn Time",
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+		"Move_Calendar": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:         mock.Event.Id.Hex(),
+					UserId:     mock.Event.UserId,
+					CalendarId: mock.WorkCalendar.Id.Hex(),
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"calendar_id"}},
+			},
+			expected: expectation{
+				out: &pb.UpdateEventReply{
+					Event: &pb.Event{
+						Id:      mock.Event.Id.Hex(),
+						UserId:  mock.Event.UserId,
+						Name:    mock.Event.Name,
+						Details: mock.Event.Details,
+						Start:   timestamppb.New(mock.Event.Start),
+						End:     timestamppb.New(mock.Event.End),
+						Color:   mock.Event.Color,
+					},
+				},
+			},
+		},
+		"Move_Occurrence_Calendar": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:         mock.RecurringEvent.Id.Hex(),
+					UserId:     mock.RecurringEvent.UserId,
+					CalendarId: mock.WorkCalendar.Id.Hex(),
+				},
+				UpdateMask:   &fieldmaskpb.FieldMask{Paths: []string{"calendar_id"}},
+				Scope:        pb.RecurrenceScope_SCOPE_THIS,
+				RecurrenceId: timestamppb.New(occurrence(2)),
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[calendar_id:must not be changed for an occurrence]"),
+			},
+		},
+		"Shared_Calendar_Editor": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.Event2.Id.Hex(),
+					UserId: 27,
+					Name:   "Autumn Time",
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				out: &pb.UpdateEventReply{
+					Event: &pb.Event{
+						Id:      mock.Event2.Id.Hex(),
+						UserId:  mock.Event2.UserId,
+						Name:    "Autumn Time",
+						Details: mock.Event2.Details,
+						Start:   timestamppb.New(mock.Event2.Start),
+						End:     timestamppb.New(mock.Event2.End),
+						Color:   mock.Event2.Color,
+					},
+				},
+			},
+		},
+		"Shared_Calendar_Viewer": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.Event2.Id.Hex(),
+					UserId: 29,
+					Name:   "Autumn Time",
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = the calendar is not shared with the required access"),
+			},
+		},
+		"Shared_Calendar_Move": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:         mock.Event2.Id.Hex(),
+					UserId:     27,
+					CalendarId: mock.DefaultCalendar.Id.Hex(),
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"calendar_id"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[calendar_id:must be a calendar of the user]"),
+			},
+		},
+		"Not_Organizer": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:     mock.Event2.Id.Hex(),
+					UserId: mock.Event2.Attendees[0].UserId,
+					Name:   "Autumn Time",
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = only the organizer can change the event"),
+			},
+		},
+		"Current_Version": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:      mock.Event2.Id.Hex(),
+					UserId:  mock.Event2.UserId,
+					Name:    "Autumn Time",
+					Version: mock.Event2.Version,
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				out: &pb.UpdateEventReply{
+					Event: &pb.Event{
+						Id:      mock.Event2.Id.Hex(),
+						UserId:  mock.Event2.UserId,
+						Name:    "Autumn Time",
+						Details: mock.Event2.Details,
+						Start:   timestamppb.New(mock.Event2.Start),
+						End:     timestamppb.New(mock.Event2.End),
+						Color:   mock.Event2.Color,
+						Version: mock.Event2.Version + 1,
+					},
+				},
+			},
+		},
+		"Stale_Version": {
+			in: &pb.UpdateEventRequest{
+				Event: &pb.Event{
+					Id:      mock.Event2.Id.Hex(),
+					UserId:  mock.Event2.UserId,
+					Name:    "Autumn Time",
+					Version: mock.Event2.Version - 1,
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = FailedPrecondition desc = the event has been changed since the given version"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.UpdateEvent(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+			} else {
+				if tt.expected.out == nil || !deepEqualUpdateEvent(tt.expected.out, out) {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+				}
+			}
+
+		})
+	}
+}
+
+func TestCalendarService_DeleteEvent(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.DeleteEventReply
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.DeleteEventRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.DeleteEventRequest{
+				EventId: mock.Event.Id.String(),
+				UserId:  mock.Event.UserId,
+			},
+			expected: expectation{
+				out: &pb.DeleteEventReply{Err: ""},
+				err: errors.New(""),
+			},
+		},
+		"Not_Exist_Event": {
+			in: &pb.DeleteEventRequest{
+				EventId: mock.Event.Id.String(),
+				UserId:  mock.Event.UserId + 1,
+			},
+			expected: expectation{
+				out: &pb.DeleteEventReply{Err: "rpc error: code = Unknown desc = failed to delete event"},
+				err: errors.New("rpc error: code = Unknown desc = failed to delete event"),
+			},
+		},
+		"Not_Organizer": {
+			in: &pb.DeleteEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  mock.Event2.Attendees[0].UserId,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = only the organizer can change the event"),
+			},
+		},
+		"Shared_Calendar_Editor": {
+			in: &pb.DeleteEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  27,
+			},
+			expected: expectation{
+				out: &pb.DeleteEventReply{Err: ""},
+			},
+		},
+		"Shared_Calendar_Viewer": {
+			in: &pb.DeleteEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  29,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = the calendar is not shared with the required access"),
+			},
+		},
+		"This_Occurrence": {
+			in: &pb.DeleteEventRequest{
+				EventId:      mock.RecurringEvent.Id.Hex(),
+				UserId:       mock.RecurringEvent.UserId,
+				Scope:        pb.RecurrenceScope_SCOPE_THIS,
+				RecurrenceId: timestamppb.New(occurrence(1)),
+			},
+			expected: expectation{
+				out: &pb.DeleteEventReply{Err: ""},
+			},
+		},
+		"This_And_Following": {
+			in: &pb.DeleteEventRequest{
+				EventId:      mock.RecurringEvent.Id.Hex(),
+				UserId:       mock.RecurringEvent.UserId,
+				Scope:        pb.RecurrenceScope_SCOPE_THIS_AND_FOLLOWING,
+				RecurrenceId: timestamppb.New(occurrence(2)),
+			},
+			expected: expectation{
+				out: &pb.DeleteEventReply{Err: ""},
+			},
+		},
+		"Not_An_Occurrence": {
+			in: &pb.DeleteEventRequest{
+				EventId:      mock.RecurringEvent.Id.Hex(),
+				UserId:       mock.RecurringEvent.UserId,
+				Scope:        pb.RecurrenceScope_SCOPE_THIS,
+				RecurrenceId: timestamppb.New(occurrence(1).Add(time.Minute)),
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = recurrence id is not an occurrence of the event"),
+			},
+		},
+		"Current_Version": {
+			in: &pb.DeleteEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  mock.Event2.UserId,
+				Version: mock.Event2.Version,
+			},
+			expected: expectation{
+				out: &pb.DeleteEventReply{Err: ""},
+			},
+		},
+		"Stale_Version": {
+			in: &pb.DeleteEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  27,
+				Version: mock.Event2.Version - 1,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = FailedPrecondition desc = the event has been changed since the given version"),
+			},
+		},
+		"Stale_Occurrence_Version": {
+			in: &pb.DeleteEventRequest{
+				EventId:      mock.RecurringEvent.Id.Hex(),
+				UserId:       mock.RecurringEvent.UserId,
+				Scope:        pb.RecurrenceScope_SCOPE_THIS,
+				RecurrenceId: timestamppb.New(occurrence(1)),
+				Version:      mock.RecurringEvent.Version + 1,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = FailedPrecondition desc = the event has been changed since the given version"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.DeleteEvent(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+			} else {
+				if !deepEqualDeleteEvent(tt.expected.out, out) {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+				}
+			}
+
+		})
+	}
+
+}
+
+func TestCalendarService_ListTrash(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.ListTrashReply
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.ListTrashRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.ListTrashRequest{UserId: mock.TrashedEvent.UserId},
+			expected: expectation{
+				out: &pb.ListTrashReply{Events: []*pb.Event{encodeEvent(*mock.TrashedEvent)}},
+			},
+		},
+		"Empty_Trash": {
+			in: &pb.ListTrashRequest{UserId: mock.TimeZoneEvent.UserId},
+			expected: expectation{
+				out: &pb.ListTrashReply{},
+			},
+		},
+		"Invalid_Page_Token": {
+			in: &pb.ListTrashRequest{UserId: mock.TrashedEvent.UserId, PageToken: "invalid"},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = invalid page token"),
+			},
+		},
+		"Negative_Page_Size": {
+			in: &pb.ListTrashRequest{UserId: mock.TrashedEvent.UserId, PageSize: -1},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[page_size:must not be negative]"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.ListTrash(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if len(tt.expected.out.Events) != len(out.Events) {
+				t.Fatalf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+			}
+			for i, event := range tt.expected.out.Events {
+				if !proto.Equal(event, out.Events[i]) {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+				}
+			}
+		})
+	}
+}
+
+func TestCalendarService_RestoreEvent(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	restored := *mock.TrashedEvent
+	restored.DeletedAt = nil
+	restored.Version++
+
+	type expectation struct {
+		out *pb.RestoreEventReply
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.RestoreEventRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.RestoreEventRequest{
+				EventId: mock.TrashedEvent.Id.Hex(),
+				UserId:  mock.TrashedEvent.UserId,
+			},
+			expected: expectation{
+				out: &pb.RestoreEventReply{Event: encodeEvent(restored)},
+			},
+		},
+		"Not_In_Trash": {
+			in: &pb.RestoreEventRequest{
+				EventId: mock.Event.Id.Hex(),
+				UserId:  mock.Event.UserId,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+		"Other_User": {
+			in: &pb.RestoreEventRequest{
+				EventId: mock.TrashedEvent.Id.Hex(),
+				UserId:  mock.TrashedEvent.UserId + 1,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.RestoreEvent(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if !proto.Equal(tt.expected.out, out) {
+				t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+			}
+		})
+	}
+}
+
+func TestCalendarService_PurgeEvent(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.PurgeEventReply
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.PurgeEventRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.PurgeEventRequest{
+				EventId: mock.TrashedEvent.Id.Hex(),
+				UserId:  mock.TrashedEvent.UserId,
+			},
+			expected: expectation{
+				out: &pb.PurgeEventReply{},
+			},
+		},
+		"Not_In_Trash": {
+			in: &pb.PurgeEventRequest{
+				EventId: mock.Event.Id.Hex(),
+				UserId:  mock.Event.UserId,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.PurgeEvent(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if !proto.Equal(tt.expected.out, out) {
+				t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+			}
+		})
+	}
+}
+
+func TestCalendarService_ListHistory(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.ListHistoryReply
+		err error
+	}
+
+	history := make([]*pb.HistoryEntry, 0, len(mock.Event2History))
+	for _, entry := range mock.Event2History {
+		history = append(history, encodeHistoryEntry(entry))
+	}
+
+	tests := map[string]struct {
+		in       *pb.ListHistoryRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.ListHistoryRequest{EventId: mock.Event2.Id.Hex(), UserId: mock.Event2.UserId},
+			expected: expectation{
+				out: &pb.ListHistoryReply{Entries: history},
+			},
+		},
+		"Page": {
+			in: &pb.ListHistoryRequest{EventId: mock.Event2.Id.Hex(), UserId: mock.Event2.UserId, PageSize: 1},
+			expected: expectation{
+				out: &pb.ListHistoryReply{Entries: history[:1], NextPageToken: "1"},
+			},
+		},
+		"Shared_Calendar_Viewer": {
+			in: &pb.ListHistoryRequest{EventId: mock.Event2.Id.Hex(), UserId: 29},
+			expected: expectation{
+				out: &pb.ListHistoryReply{Entries: history},
+			},
+		},
+		"Shared_Calendar_FreeBusy": {
+			in: &pb.ListHistoryRequest{EventId: mock.Event2.Id.Hex(), UserId: 28},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = the calendar is not shared with the required access"),
+			},
+		},
+		"Attendee": {
+			in: &pb.ListHistoryRequest{EventId: mock.Event2.Id.Hex(), UserId: mock.Event2.Attendees[0].UserId},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+		"No_History": {
+			in: &pb.ListHistoryRequest{EventId: mock.Event.Id.Hex(), UserId: mock.Event.UserId},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.ListHistory(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if !proto.Equal(tt.expected.out, out) {
+				t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+			}
+		})
+	}
+}
+
+func TestCalendarService_RevertEvent(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	created := mock.Event2History[len(mock.Event2History)-1]
+
+	type expectation struct {
+		name    string
+		details string
+		err     error
+	}
+
+	tests := map[string]struct {
+		in       *pb.RevertEventRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.RevertEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  mock.Event2.UserId,
+				EntryId: created.Id.Hex(),
+			},
+			expected: expectation{name: created.Event.Name, details: created.Event.Details},
+		},
+		"Shared_Calendar_Editor": {
+			in: &pb.RevertEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  27,
+				EntryId: created.Id.Hex(),
+			},
+			expected: expectation{name: created.Event.Name, details: created.Event.Details},
+		},
+		"Shared_Calendar_Viewer": {
+			in: &pb.RevertEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  29,
+				EntryId: created.Id.Hex(),
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = the calendar is not shared with the required access"),
+			},
+		},
+		"Unknown_Entry": {
+			in: &pb.RevertEventRequest{
+				EventId: mock.Event.Id.Hex(),
+				UserId:  mock.Event.UserId,
+				EntryId: created.Id.Hex(),
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.RevertEvent(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if tt.expected.err != nil {
+				t.Fatalf("Err -> Want: \n%q\n;Got: nil", tt.expected.err)
+			}
+			if out.Event.Name != tt.expected.name || out.Event.Details != tt.expected.details {
+				t.Errorf("Out -> \nWant: %q %q;\nGot: %q", tt.expected.name, tt.expected.details, out.Event)
+			}
+			// The attendees are not reverted
+			if len(out.Event.Attendees) != len(mock.Event2.Attendees) {
+				t.Errorf("Attendees -> \nWant: %d;\nGot: %d", len(mock.Event2.Attendees), len(out.Event.Attendees))
+			}
+		})
+	}
+}
+
+func TestCalendarService_WatchEvents(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	// The watch is resumed after the creation of Event2, so its update is sent first
+	created, updated := mock.Event2History[1], mock.Event2History[0]
+	stream, err := client.WatchEvents(ctx, &pb.WatchEventsRequest{
+		UserId:      mock.Event2.UserId,
+		ResumeToken: mock.ResumeToken(created),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := encodeNotification(repository.NewNotification(updated, mock.ResumeToken(updated)))
+	out, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !proto.Equal(expected, out) {
+		t.Errorf("Out -> \nWant: %q;\nGot: %q", expected, out)
+	}
+	if out.Type != repository.NotificationUpdated {
+		t.Errorf("Type -> Want: %q; Got: %q", repository.NotificationUpdated, out.Type)
+	}
+
+	// The changes after the resume are sent as they are made
+	reply, err := client.CreateEvent(ctx, &pb.CreateEventRequest{
+		Event: &pb.Event{
+			UserId: mock.Event2.UserId,
+			Name:   "Watched",
+			Start:  timestamppb.New(time.Time{}.Add(time.Second)),
+			End:    timestamppb.New(time.Time{}.Add(time.Second)),
+			Color:  "#FFFFFF",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err = stream.Recv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Type != repository.NotificationCreated || out.Event.Id != reply.EventId || out.Token == "" {
+		t.Errorf("Out -> \nWant: a created notification of %s;\nGot: %q", reply.EventId, out)
+	}
+
+	// Another watch which is resumed with the token of the creation gets only the later changes
+	resumed, err := client.WatchEvents(ctx, &pb.WatchEventsRequest{UserId: mock.Event2.UserId, ResumeToken: out.Token})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The events of a deleted calendar are deleted too
+	_, err = client.DeleteCalendar(ctx, &pb.DeleteCalendarRequest{CalendarId: mock.WorkCalendar.Id.Hex(),
+		UserId: mock.WorkCalendar.UserId})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err = stream.Recv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Type != repository.NotificationDeleted || out.Event.Id != mock.Event2.Id.Hex() {
+		t.Errorf("Out -> \nWant: a deleted notification of %s;\nGot: %q", mock.Event2.Id.Hex(), out)
+	}
+
+	resumedOut, err := resumed.Recv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !proto.Equal(out, resumedOut) {
+		t.Errorf("Resumed -> \nWant: %q;\nGot: %q", out, resumedOut)
+	}
+
+	stream, err = client.WatchEvents(ctx, &pb.WatchEventsRequest{UserId: mock.Event2.UserId, ResumeToken: "invalid"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	want := "rpc error: code = InvalidArgument desc = invalid page token"
+	if err == nil || err.Error() != want {
+		t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", want, err)
+	}
+}
+
+func TestCalendarService_InviteAttendees(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.InviteAttendeesReply
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.InviteAttendeesRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.InviteAttendeesRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  mock.Event2.UserId,
+				Attendees: []*pb.Attendee{
+					{Email: "Guest@example.com"},
+					{UserId: 27, Status: "accepted"},
+				},
+			},
+			expected: expectation{
+				out: &pb.InviteAttendeesReply{
+					Event: &pb.Event{
+						Id:      mock.Event2.Id.Hex(),
+						UserId:  mock.Event2.UserId,
+						Name:    mock.Event2.Name,
+						Details: mock.Event2.Details,
+						Start:   timestamppb.New(mock.Event2.Start),
+						End:     timestamppb.New(mock.Event2.End),
+						Color:   mock.Event2.Color,
+						Attendees: []*pb.Attendee{
+							{UserId: 26, Status: "needs-action"},
+							{Email: "guest@example.com", Status: "needs-action"},
+							{UserId: 27, Status: "needs-action"},
+						},
+					},
+				},
+			},
+		},
+		"No_Attendees": {
+			in: &pb.InviteAttendeesRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  mock.Event2.UserId,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[attendees:must be provided]"),
+			},
+		},
+		"Organizer": {
+			in: &pb.InviteAttendeesRequest{
+				EventId:   mock.Event2.Id.Hex(),
+				UserId:    mock.Event2.UserId,
+				Attendees: []*pb.Attendee{{UserId: mock.Event2.UserId}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[attendees:must not include the organizer]"),
+			},
+		},
+		"Invalid_Email": {
+			in: &pb.InviteAttendeesRequest{
+				EventId:   mock.Event2.Id.Hex(),
+				UserId:    mock.Event2.UserId,
+				Attendees: []*pb.Attendee{{Email: "guest"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[attendees:must have valid emails]"),
+			},
+		},
+		"Not_Organizer": {
+			in: &pb.InviteAttendeesRequest{
+				EventId:   mock.Event2.Id.Hex(),
+				UserId:    mock.Event2.Attendees[0].UserId,
+				Attendees: []*pb.Attendee{{UserId: 27}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = only the organizer can change the event"),
+			},
+		},
+		"Not_Exist_Event": {
+			in: &pb.InviteAttendeesRequest{
+				EventId:   mock.Event2.Id.Hex(),
+				UserId:    mock.Event2.UserId + 1,
+				Attendees: []*pb.Attendee{{UserId: 27}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.InviteAttendees(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+			} else {
+				if tt.expected.out == nil || !deepEqualInviteAttendees(tt.expected.out, out) {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+				}
+			}
+		})
+	}
+}
+
+func TestCalendarService_RespondEvent(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	tests := map[string]struct {
+		in  *pb.RespondEventRequest
+		err error
+	}{
+		"Must_Success": {
+			in: &pb.RespondEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  mock.Event2.Attendees[0].UserId,
+				Status:  "accepted",
+			},
+		},
+		"Invalid_Status": {
+			in: &pb.RespondEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  mock.Event2.Attendees[0].UserId,
+				Status:  "needs-action",
+			},
+			err: errors.New("rpc error: code = InvalidArgument desc = map[status:must be accepted, declined or tentative]"),
+		},
+		"Not_Attendee": {
+			in: &pb.RespondEventRequest{
+				EventId: mock.Event2.Id.Hex(),
+				UserId:  mock.Event2.UserId,
+				Status:  "declined",
+			},
+			err: errors.New("rpc error: code = NotFound desc = record not found"),
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			_, err := client.RespondEvent(ctx, tt.in)
+			if (err == nil) != (tt.err == nil) || (err != nil && tt.err.Error() != err.Error()) {
+				t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestCalendarService_AddAttachment(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	content := []byte("%PDF-1.4 minutes")
+
+	tests := map[string]struct {
+		in  *pb.AddAttachmentRequest
+		err error
+	}{
+		"Must_Success": {
+			in: &pb.AddAttachmentRequest{
+				EventId:    mock.Event2.Id.Hex(),
+				UserId:     mock.Event2.UserId,
+				Attachment: &pb.Attachment{Name: "minutes.pdf", ContentType: "application/pdf"},
+				Content:    content,
+			},
+		},
+		"Editor": {
+			in: &pb.AddAttachmentRequest{
+				EventId:    mock.Event2.Id.Hex(),
+				UserId:     27,
+				Attachment: &pb.Attachment{Name: "minutes.pdf", ContentType: "Application/PDF; name=minutes.pdf"},
+				Content:    content,
+			},
+		},
+		"Disallowed_Type": {
+			in: &pb.AddAttachmentRequest{
+				EventId:    mock.Event2.Id.Hex(),
+				UserId:     mock.Event2.UserId,
+				Attachment: &pb.Attachment{Name: "minutes.html", ContentType: "text/html"},
+				Content:    content,
+			},
+			err: errors.New("rpc error: code = InvalidArgument desc = map[content_type:must be a document or an image type]"),
+		},
+		"Empty_Content": {
+			in: &pb.AddAttachmentRequest{
+				EventId:    mock.Event2.Id.Hex(),
+				UserId:     mock.Event2.UserId,
+				Attachment: &pb.Attachment{Name: "minutes.pdf", ContentType: "application/pdf"},
+			},
+			err: errors.New("rpc error: code = InvalidArgument desc = map[size:must not be empty]"),
+		},
+		"Path_Name": {
+			in: &pb.AddAttachmentRequest{
+				EventId:    mock.Event2.Id.Hex(),
+				UserId:     mock.Event2.UserId,
+				Attachment: &pb.Attachment{Name: "../minutes.pdf", ContentType: "application/pdf"},
+				Content:    content,
+			},
+			err: errors.New("rpc error: code = InvalidArgument desc = map[name:must not contain a path]"),
+		},
+		"Override": {
+			in: &pb.AddAttachmentRequest{
+				EventId:    mock.Override.Id.Hex(),
+				UserId:     mock.Override.UserId,
+				Attachment: &pb.Attachment{Name: "minutes.pdf", ContentType: "application/pdf"},
+				Content:    content,
+			},
+			err: errors.New("rpc error: code = InvalidArgument desc = map[event_id:must not be an occurrence, attach to the recurring event]"),
+		},
+		"Attendee": {
+			in: &pb.AddAttachmentRequest{
+				EventId:    mock.Event2.Id.Hex(),
+				UserId:     mock.Event2.Attendees[0].UserId,
+				Attachment: &pb.Attachment{Name: "minutes.pdf", ContentType: "application/pdf"},
+				Content:    content,
+			},
+			err: errors.New("rpc error: code = PermissionDenied desc = only the organizer can change the event"),
+		},
+		"Not_Exist_Event": {
+			in: &pb.AddAttachmentRequest{
+				EventId:    mock.Event2.Id.Hex(),
+				UserId:     mock.Event2.UserId + 1,
+				Attachment: &pb.Attachment{Name: "minutes.pdf", ContentType: "application/pdf"},
+				Content:    content,
+			},
+			err: errors.New("rpc error: code = NotFound desc = record not found"),
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.AddAttachment(ctx, tt.in)
+			if (err == nil) != (tt.err == nil) || (err != nil && tt.err.Error() != err.Error()) {
+				t.Fatalf("Err -> Want: \n%q\n;Got: \n%q\n", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			attachments := out.Event.Attachments
+			if len(attachments) != 2 || attachments[0].Id != mock.Event2Attachment.Id.Hex() {
+				t.Fatalf("expected the attachment to be added after %s, got %v", mock.Event2Attachment.Id.Hex(), attachments)
+			}
+			added := attachments[1]
+			if added.Id == "" || added.Name != "minutes.pdf" || added.ContentType != "application/pdf" ||
+				added.Size != int64(len(content)) {
+				t.Errorf("unexpected attachment %v", added)
+			}
+			if out.Event.UserId != mock.Event2.UserId || out.Event.Version != mock.Event2.Version+1 {
+				t.Errorf("expected the event of %d at version %d, got %d at %d", mock.Event2.UserId,
+					mock.Event2.Version+1, out.Event.UserId, out.Event.Version)
+			}
+		})
+	}
+}
+
+func TestCalendarService_GetAttachment(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	tests := map[string]struct {
+		in  *pb.GetAttachmentRequest
+		err error
+	}{
+		"Organizer": {
+			in: &pb.GetAttachmentRequest{
+				EventId:      mock.Event2.Id.Hex(),
+				UserId:       mock.Event2.UserId,
+				AttachmentId: mock.Event2Attachment.Id.Hex(),
+			},
+		},
+		"Attendee": {
+			in: &pb.GetAttachmentRequest{
+				EventId:      mock.Event2.Id.Hex(),
+				UserId:       mock.Event2.Attendees[0].UserId,
+				AttachmentId: mock.Event2Attachment.Id.Hex(),
+			},
+		},
+		"Viewer": {
+			in: &pb.GetAttachmentRequest{
+				EventId:      mock.Event2.Id.Hex(),
+				UserId:       29,
+				AttachmentId: mock.Event2Attachment.Id.Hex(),
+			},
+		},
+		"FreeBusy": {
+			in: &pb.GetAttachmentRequest{
+				EventId:      mock.Event2.Id.Hex(),
+				UserId:       28,
+				AttachmentId: mock.Event2Attachment.Id.Hex(),
+			},
+			err: errors.New("rpc error: code = NotFound desc = record not found"),
+		},
+		"Other_Event": {
+			in: &pb.GetAttachmentRequest{
+				EventId:      mock.Event.Id.Hex(),
+				UserId:       mock.Event.UserId,
+				AttachmentId: mock.Event2Attachment.Id.Hex(),
+			},
+			err: errors.New("rpc error: code = NotFound desc = record not found"),
+		},
+		"Stranger": {
+			in: &pb.GetAttachmentRequest{
+				EventId:      mock.Event2.Id.Hex(),
+				UserId:       mock.Event2.UserId + 1,
+				AttachmentId: mock.Event2Attachment.Id.Hex(),
+			},
+			err: errors.New("rpc error: code = NotFound desc = record not found"),
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.GetAttachment(ctx, tt.in)
+			if (err == nil) != (tt.err == nil) || (err != nil && tt.err.Error() != err.Error()) {
+				t.Fatalf("Err -> Want: \n%q\n;Got: \n%q\n", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if out.Attachment.Id != mock.Event2Attachment.Id.Hex() || out.Attachment.Name != mock.Event2Attachment.Name ||
+				out.Attachment.ContentType != mock.Event2Attachment.ContentType {
+				t.Errorf("unexpected attachment %v", out.Attachment)
+			}
+			if string(out.Content) != string(mock.AttachmentContent) {
+				t.Errorf("expected the content %q, got %q", mock.AttachmentContent, out.Content)
+			}
+		})
+	}
+}
+
+func TestCalendarService_DeleteAttachment(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	tests := map[string]struct {
+		in  *pb.DeleteAttachmentRequest
+		err error
+	}{
+		"Must_Success": {
+			in: &pb.DeleteAttachmentRequest{
+				EventId:      mock.Event2.Id.Hex(),
+				UserId:       mock.Event2.UserId,
+				AttachmentId: mock.Event2Attachment.Id.Hex(),
+			},
+		},
+		"Editor": {
+			in: &pb.DeleteAttachmentRequest{
+				EventId:      mock.Event2.Id.Hex(),
+				UserId:       27,
+				AttachmentId: mock.Event2Attachment.Id.Hex(),
+			},
+		},
+		"Attendee": {
+			in: &pb.DeleteAttachmentRequest{
+				EventId:      mock.Event2.Id.Hex(),
+				UserId:       mock.Event2.Attendees[0].UserId,
+				AttachmentId: mock.Event2Attachment.Id.Hex(),
+			},
+			err: errors.New("rpc error: code = PermissionDenied desc = only the organizer can change the event"),
+		},
+		"Not_Exist_Attachment": {
+			in: &pb.DeleteAttachmentRequest{
+				EventId:      mock.Event2.Id.Hex(),
+				UserId:       mock.Event2.UserId,
+				AttachmentId: primitive.NewObjectID().Hex(),
+			},
+			err: errors.New("rpc error: code = NotFound desc = record not found"),
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.DeleteAttachment(ctx, tt.in)
+			if (err == nil) != (tt.err == nil) || (err != nil && tt.err.Error() != err.Error()) {
+				t.Fatalf("Err -> Want: \n%q\n;Got: \n%q\n", tt.err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(out.Event.Attachments) != 0 || out.Event.Version != mock.Event2.Version+1 {
+				t.Errorf("expected no attachments at version %d, got %v at %d", mock.Event2.Version+1,
+					out.Event.Attachments, out.Event.Version)
+			}
+		})
+	}
+}
+
+func TestCalendarService_ImportEvents(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	event := func(uid string, recurrenceId *timestamppb.Timestamp) *pb.Event {
+		return &pb.Event{
+			Name:         "Imported",
+			Start:        timestamppb.New(occurrence(1)),
+			End:          timestamppb.New(occurrence(1).Add(time.Hour)),
+			Color:        "#00FF00",
+			Uid:          uid,
+			RecurrenceId: recurrenceId,
+		}
+	}
+	invalid := event("invalid@example.com", nil)
+	invalid.Name = ""
+
+	in := &pb.ImportEventsRequest{
+		UserId: mock.RecurringEvent.UserId,
+		Events: []*pb.Event{
+			event(mock.RecurringEvent.UID, timestamppb.New(occurrence(1))),
+			event(mock.RecurringEvent.UID, timestamppb.New(occurrence(2))),
+			event(mock.RecurringEvent.UID, nil),
+			event("new@example.com", nil),
+			event("unknown@example.com", timestamppb.New(occurrence(1))),
+			event(mock.RecurringEvent.UID, timestamppb.New(occurrence(1).Add(time.Minute))),
+			invalid,
+		},
+	}
+
+	expected := []*pb.ImportResult{
+		{Uid: mock.RecurringEvent.UID, EventId: mock.Override.Id.Hex(), Created: false},
+		{Uid: mock.RecurringEvent.UID, EventId: "6285f86bb502f9d335124b04", Created: true},
+		{Uid: mock.RecurringEvent.UID, EventId: mock.RecurringEvent.Id.Hex(), Created: false},
+		{Uid: "new@example.com", EventId: "6285f86bb502f9d335124b04", Created: true},
+		{Uid: "unknown@example.com", Err: "recurring event is not found"},
+		{Uid: mock.RecurringEvent.UID, Err: "recurrence id is not an occurrence of the event"},
+		{Uid: "invalid@example.com", Err: "map[name:must be provided]"},
+	}
+
+	out, err := client.ImportEvents(ctx, in)
+	if err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+	if len(out.Results) != len(expected) {
+		t.Fatalf("Out -> \nWant: %q;\nGot: %q", expected, out.Results)
+	}
+	for i, result := range out.Results {
+		if result.Uid != expected[i].Uid || result.EventId != expected[i].EventId ||
+			result.Created != expected[i].Created || result.Err != expected[i].Err {
+			t.Errorf("Out -> \nWant: %q;\nGot: %q", expected[i], result)
+		}
+	}
+}
+
+func TestCalendarService_BatchCreateEvents(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	event := func(name, calendarId string) *pb.Event {
+		return &pb.Event{
+			Name:       name,
+			Start:      timestamppb.New(occurrence(1)),
+			End:        timestamppb.New(occurrence(1).Add(time.Hour)),
+			Color:      "#00FF00",
+			CalendarId: calendarId,
+		}
+	}
+
+	in := &pb.BatchCreateEventsRequest{
+		UserId: mock.Event.UserId,
+		Events: []*pb.Event{
+			event("Default", ""),
+			event("Work", mock.WorkCalendar.Id.Hex()),
+			event("", ""),
+			event("Unknown", primitive.NewObjectID().Hex()),
+		},
+	}
+
+	// The created events have an event id, the others have an error
+	expected := []*pb.BatchResult{
+		{EventId: "created"},
+		{EventId: "created"},
+		{Err: "map[name:must be provided]"},
+		{Err: "map[calendar_id:must be a calendar of the user]"},
+	}
+
+	out, err := client.BatchCreateEvents(ctx, in)
+	if err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+	if len(out.Results) != len(expected) {
+		t.Fatalf("Out -> \nWant: %q;\nGot: %q", expected, out.Results)
+	}
+	for i, result := range out.Results {
+		if (result.EventId != "") != (expected[i].EventId != "") || result.Err != expected[i].Err {
+			t.Errorf("Out -> \nWant: %q;\nGot: %q", expected[i], result)
+		}
+	}
+
+	tooLarge := make([]*pb.Event, repository.MaxBatchSize+1)
+	for i := range tooLarge {
+		tooLarge[i] = event("Default", "")
+	}
+
+	tests := map[string]struct {
+		in  *pb.BatchCreateEventsRequest
+		err error
+	}{
+		"Empty_Batch": {
+			in:  &pb.BatchCreateEventsRequest{UserId: mock.Event.UserId},
+			err: errors.New("rpc error: code = InvalidArgument desc = map[events:must be provided]"),
+		},
+		"Too_Large_Batch": {
+			in: &pb.BatchCreateEventsRequest{
+				UserId: mock.Event.UserId,
+				Events: tooLarge,
+			},
+			err: errors.New("rpc error: code = InvalidArgument desc = map[events:must not have more than 1000 items]"),
+		},
+		"Invalid_Calendar_Id": {
+			in: &pb.BatchCreateEventsRequest{
+				UserId: mock.Event.UserId,
+				Events: []*pb.Event{event("Default", ""), event("Invalid", "invalid")},
+			},
+			err: errors.New("rpc error: code = InvalidArgument desc = events[1]: invalid calendar id: the provided hex string is not a valid ObjectID"),
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			_, err := client.BatchCreateEvents(ctx, tt.in)
+			if err == nil || tt.err.Error() != err.Error() {
+				t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestCalendarService_BatchDeleteEvents(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	in := &pb.BatchDeleteEventsRequest{
+		UserId: mock.Event.UserId,
+		EventIds: []string{
+			mock.Event.Id.Hex(),
+			mock.Event2.Id.Hex(),
+			"invalid",
+			mock.RecurringEvent.Id.Hex(),
+		},
+	}
+
+	expected := []*pb.BatchResult{
+		{EventId: mock.Event.Id.Hex()},
+		{EventId: mock.Event2.Id.Hex()},
+		{Err: "invalid event id"},
+		{Err: "record not found"},
+	}
+
+	out, err := client.BatchDeleteEvents(ctx, in)
+	if err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+	if len(out.Results) != len(expected) {
+		t.Fatalf("Out -> \nWant: %q;\nGot: %q", expected, out.Results)
+	}
+	for i, result := range out.Results {
+		if !proto.Equal(result, expected[i]) {
+			t.Errorf("Out -> \nWant: %q;\nGot: %q", expected[i], result)
+		}
+	}
+
+	_, err = client.BatchDeleteEvents(ctx, &pb.BatchDeleteEventsRequest{UserId: mock.Event.UserId})
+	want := "rpc error: code = InvalidArgument desc = map[event_ids:must be provided]"
+	if err == nil || err.Error() != want {
+		t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", want, err)
+	}
+}
+
+func TestCalendarService_ListTags(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.ListTagsReply
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.ListTagsRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.ListTagsRequest{UserId: mock.Event.UserId},
+			expected: expectation{
+				out: &pb.ListTagsReply{
+					Tags: []*pb.TagCount{
+						{Tag: "music", Count: 2},
+						{Tag: "personal", Count: 1},
+						{Tag: "work", Count: 1},
+					},
+				},
+			},
+		},
+		"No_Tags": {
+			in: &pb.ListTagsRequest{UserId: mock.TimeZoneEvent.UserId},
+			expected: expectation{
+				out: &pb.ListTagsReply{},
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.ListTags(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if len(tt.expected.out.Tags) != len(out.Tags) {
+				t.Fatalf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+			}
+			for i, tag := range tt.expected.out.Tags {
+				if !proto.Equal(tag, out.Tags[i]) {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+				}
+			}
+		})
+	}
+}
+
+func TestCalendarService_ServiceStatus(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	expected := errors.New("")
+
+	_, err := client.ServiceStatus(ctx, &pb.ServiceStatusRequest{})
+
+	if err != nil {
+		if expected.Error() != err.Error() {
+			t.Errorf("Err -> \nWant: %v;\nGot: %v", expected, err)
+		}
+	}
+}
+
+func TestCalendarService_GetEventByUID(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		id        string
+		overrides int
+		err       error
+	}
+
+	tests := map[string]struct {
+		in       *pb.GetEventByUIDRequest
+		expected expectation
+	}{
+		"Imported_Event": {
+			in: &pb.GetEventByUIDRequest{
+				Uid:    mock.RecurringEvent.UID,
+				UserId: mock.RecurringEvent.UserId,
+			},
+			expected: expectation{id: mock.RecurringEvent.Id.Hex(), overrides: 1},
+		},
+		"Generated_UID": {
+			in: &pb.GetEventByUIDRequest{
+				Uid:    mock.Event2.ICalUID(),
+				UserId: mock.Event2.UserId,
+			},
+			expected: expectation{id: mock.Event2.Id.Hex()},
+		},
+		"Override_UID": {
+			in: &pb.GetEventByUIDRequest{
+				Uid:    mock.Override.Id.Hex() + "@kalenderium",
+				UserId: mock.Override.UserId,
+			},
+			expected: expectation{err: errors.New("rpc error: code = NotFound desc = record not found")},
+		},
+		"Not_Owner": {
+			in: &pb.GetEventByUIDRequest{
+				Uid:    mock.RecurringEvent.UID,
+				UserId: mock.Event.UserId,
+			},
+			expected: expectation{err: errors.New("rpc error: code = NotFound desc = record not found")},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.GetEventByUID(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if tt.expected.err != nil {
+				t.Fatalf("Err -> Want: %q; Got: nil", tt.expected.err)
+			}
+			if out.Event.Id != tt.expected.id || len(out.Overrides) != tt.expected.overrides {
+				t.Errorf("Out -> Want: %s with %d overrides; Got: %s with %d overrides",
+					tt.expected.id, tt.expected.overrides, out.Event.Id, len(out.Overrides))
+			}
+		})
+	}
+}
+
+func TestCalendarService_GetEventByResource(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		id        string
+		overrides int
+		err       error
+	}
+
+	tests := map[string]struct {
+		in       *pb.GetEventByResourceRequest
+		expected expectation
+	}{
+		"Resource_Name": {
+			in: &pb.GetEventByResourceRequest{
+				Name:   mock.RecurringEvent.Resource,
+				UserId: mock.RecurringEvent.UserId,
+			},
+			expected: expectation{id: mock.RecurringEvent.Id.Hex(), overrides: 1},
+		},
+		"UID_Of_Named_Resource": {
+			in: &pb.GetEventByResourceRequest{
+				Name:   mock.RecurringEvent.UID,
+				UserId: mock.RecurringEvent.UserId,
+			},
+			expected: expectation{err: errors.New("rpc error: code = NotFound desc = record not found")},
+		},
+		"Named_After_UID": {
+			in: &pb.GetEventByResourceRequest{
+				Name:   mock.Event2.ICalUID(),
+				UserId: mock.Event2.UserId,
+			},
+			expected: expectation{id: mock.Event2.Id.Hex()},
+		},
+		"Not_Owner": {
+			in: &pb.GetEventByResourceRequest{
+				Name:   mock.RecurringEvent.Resource,
+				UserId: mock.Event.UserId,
+			},
+			expected: expectation{err: errors.New("rpc error: code = NotFound desc = record not found")},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.GetEventByResource(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if tt.expected.err != nil {
+				t.Fatalf("Err -> Want: %q; Got: nil", tt.expected.err)
+			}
+			if out.Event.Id != tt.expected.id || len(out.Overrides) != tt.expected.overrides {
+				t.Errorf("Out -> Want: %s with %d overrides; Got: %s with %d overrides",
+					tt.expected.id, tt.expected.overrides, out.Event.Id, len(out.Overrides))
+			}
+		})
+	}
+}
+
+func TestCalendarService_CreateCalendar(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.UserCalendar
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.CreateCalendarRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.CreateCalendarRequest{
+				Calendar: &pb.UserCalendar{UserId: 22, Name: "On-call", Color: "#FF0000"},
+			},
+			expected: expectation{
+				out: &pb.UserCalendar{
+					Id:         "62a1c0d4e1b2c3d4e5f60718",
+					UserId:     22,
+					Name:       "On-call",
+					Color:      "#FF0000",
+					Visibility: "private",
+				},
+			},
+		},
+		"Not_Default": {
+			in: &pb.CreateCalendarRequest{
+				Calendar: &pb.UserCalendar{UserId: 22, Name: "Personal", Color: "#00FF00", Visibility: "public",
+					IsDefault: true},
+			},
+			expected: expectation{
+				out: &pb.UserCalendar{
+					Id:         "62a1c0d4e1b2c3d4e5f60718",
+					UserId:     22,
+					Name:       "Personal",
+					Color:      "#00FF00",
+					Visibility: "public",
+				},
+			},
+		},
+		"Empty_Name": {
+			in: &pb.CreateCalendarRequest{
+				Calendar: &pb.UserCalendar{UserId: 22, Color: "#FF0000"},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[name:must be provided]"),
+			},
+		},
+		"Invalid_Visibility": {
+			in: &pb.CreateCalendarRequest{
+				Calendar: &pb.UserCalendar{UserId: 22, Name: "On-call", Color: "#FF0000", Visibility: "secret"},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[visibility:must be private or public]"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.CreateCalendar(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if tt.expected.out == nil || !deepEqualCalendars([]*pb.UserCalendar{tt.expected.out}, []*pb.UserCalendar{out.Calendar}) {
+				t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+			}
+		})
+	}
+}
+
+func TestCalendarService_ListCalendars(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	tests := map[string]struct {
+		in       *pb.ListCalendarsRequest
+		expected []*pb.UserCalendar
+	}{
+		"Must_Success": {
+			in: &pb.ListCalendarsRequest{UserId: 22},
+			expected: []*pb.UserCalendar{
+				{
+					Id:         mock.DefaultCalendar.Id.Hex(),
+					UserId:     22,
+					Name:       mock.DefaultCalendar.Name,
+					Color:      mock.DefaultCalendar.Color,
+					Visibility: mock.DefaultCalendar.Visibility,
+					IsDefault:  true,
+				},
+				{
+					Id:         mock.WorkCalendar.Id.Hex(),
+					UserId:     22,
+					Name:       mock.WorkCalendar.Name,
+					Color:      mock.WorkCalendar.Color,
+					Visibility: mock.WorkCalendar.Visibility,
+				},
+			},
+		},
+		"Shared_Calendar": {
+			in: &pb.ListCalendarsRequest{UserId: 27},
+			expected: []*pb.UserCalendar{
+				{
+					UserId:     27,
+					Name:       "Calendar",
+					Color:      "#1E90FF",
+					Visibility: "private",
+					IsDefault:  true,
+				},
+				{
+					Id:         mock.WorkCalendar.Id.Hex(),
+					UserId:     22,
+					Name:       mock.WorkCalendar.Name,
+					Color:      mock.WorkCalendar.Color,
+					Visibility: mock.WorkCalendar.Visibility,
+					Role:       "editor",
+				},
+			},
+		},
+		"New_User": {
+			in: &pb.ListCalendarsRequest{UserId: 30},
+			expected: []*pb.UserCalendar{
+				{
+					UserId:     30,
+					Name:       "Calendar",
+					Color:      "#1E90FF",
+					Visibility: "private",
+					IsDefault:  true,
+				},
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.ListCalendars(ctx, tt.in)
+			if err != nil {
+				t.Fatalf("Err -> Want: nil; Got: %q", err)
+			}
+			if !deepEqualCalendars(tt.expected, out.Calendars) {
+				t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected, out.Calendars)
+			}
+		})
+	}
+}
+
+func TestCalendarService_UpdateCalendar(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out *pb.UserCalendar
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.UpdateCalendarRequest
+		expected expectation
+	}{
+		"Rename": {
+			in: &pb.UpdateCalendarRequest{
+				Calendar:   &pb.UserCalendar{Id: mock.WorkCalendar.Id.Hex(), UserId: 22, Name: "Office"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				out: &pb.UserCalendar{
+					Id:         mock.WorkCalendar.Id.Hex(),
+					UserId:     22,
+					Name:       "Office",
+					Color:      mock.WorkCalendar.Color,
+					Visibility: mock.WorkCalendar.Visibility,
+				},
+			},
+		},
+		"Time_Zone": {
+			in: &pb.UpdateCalendarRequest{
+				Calendar:   &pb.UserCalendar{Id: mock.WorkCalendar.Id.Hex(), UserId: 22, TimeZone: "Europe/Istanbul"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"time_zone"}},
+			},
+			expected: expectation{
+				out: &pb.UserCalendar{
+					Id:         mock.WorkCalendar.Id.Hex(),
+					UserId:     22,
+					Name:       mock.WorkCalendar.Name,
+					Color:      mock.WorkCalendar.Color,
+					Visibility: mock.WorkCalendar.Visibility,
+					TimeZone:   "Europe/Istanbul",
+				},
+			},
+		},
+		"Invalid_Time_Zone": {
+			in: &pb.UpdateCalendarRequest{
+				Calendar:   &pb.UserCalendar{Id: mock.WorkCalendar.Id.Hex(), UserId: 22, TimeZone: "Mars/Olympus"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"time_zone"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[time_zone:must be a valid IANA time zone]"),
+			},
+		},
+		"Empty_Mask": {
+			in: &pb.UpdateCalendarRequest{
+				Calendar: &pb.UserCalendar{Id: mock.WorkCalendar.Id.Hex(), UserId: 22, Name: "Office"},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[color:must be provided visibility:must be private or public]"),
+			},
+		},
+		"Unknown_Field": {
+			in: &pb.UpdateCalendarRequest{
+				Calendar:   &pb.UserCalendar{Id: mock.WorkCalendar.Id.Hex(), UserId: 22},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"default"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = unknown field in update mask: default"),
+			},
+		},
+		"Shared_Calendar_Owner": {
+			in: &pb.UpdateCalendarRequest{
+				Calendar:   &pb.UserCalendar{Id: mock.WorkCalendar.Id.Hex(), UserId: 31, Name: "Office"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				out: &pb.UserCalendar{
+					Id:         mock.WorkCalendar.Id.Hex(),
+					UserId:     22,
+					Name:       "Office",
+					Color:      mock.WorkCalendar.Color,
+					Visibility: mock.WorkCalendar.Visibility,
+					Role:       "owner",
+				},
+			},
+		},
+		"Shared_Calendar_Editor": {
+			in: &pb.UpdateCalendarRequest{
+				Calendar:   &pb.UserCalendar{Id: mock.WorkCalendar.Id.Hex(), UserId: 27, Name: "Office"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = the calendar is not shared with the required access"),
+			},
+		},
+		"Not_Owner": {
+			in: &pb.UpdateCalendarRequest{
+				Calendar:   &pb.UserCalendar{Id: mock.WorkCalendar.Id.Hex(), UserId: 23, Name: "Office"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.UpdateCalendar(ctx, tt.in)
 			if err != nil {
-				if tt.expected.err.Error() != err.Error() {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
 					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
 				}
-			} else {
-				if !deepEqualListEvent(tt.expected.out, out) {
-					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
-				}
+				return
 			}
+			if tt.expected.out == nil || !deepEqualCalendars([]*pb.UserCalendar{tt.expected.out}, []*pb.UserCalendar{out.Calendar}) {
+				t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+			}
+		})
+	}
+}
+
+func TestCalendarService_DeleteCalendar(t *testing.T) {
+	ctx := context.Background()
 
+	client, closer := Server(ctx)
+	defer closer()
+
+	tests := map[string]struct {
+		in  *pb.DeleteCalendarRequest
+		err error
+	}{
+		"Must_Success": {
+			in: &pb.DeleteCalendarRequest{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 22},
+		},
+		"Default_Calendar": {
+			in:  &pb.DeleteCalendarRequest{CalendarId: mock.DefaultCalendar.Id.Hex(), UserId: 22},
+			err: errors.New("rpc error: code = FailedPrecondition desc = the default calendar can not be deleted"),
+		},
+		"Shared_Calendar_Owner": {
+			in:  &pb.DeleteCalendarRequest{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 31},
+			err: errors.New("rpc error: code = PermissionDenied desc = the calendar is not shared with the required access"),
+		},
+		"Not_Owner": {
+			in:  &pb.DeleteCalendarRequest{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 23},
+			err: errors.New("rpc error: code = NotFound desc = record not found"),
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			_, err := client.DeleteCalendar(ctx, tt.in)
+			if (err == nil) != (tt.err == nil) || (err != nil && tt.err.Error() != err.Error()) {
+				t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.err, err)
+			}
 		})
 	}
 }
 
-func TestCalendarService_DeleteEvent(t *testing.T) {
+func TestCalendarService_GrantAccess(t *testing.T) {
 	ctx := context.Background()
 
 	client, closer := Server(ctx)
 	defer closer()
 
 	type expectation struct {
-		out *pb.DeleteEventReply
+		out *pb.Grant
 		err error
 	}
 
 	tests := map[string]struct {
-		in       *pb.DeleteEventRequest
+		in       *pb.GrantAccessRequest
 		expected expectation
 	}{
 		"Must_Success": {
-			in: &pb.DeleteEventRequest{
-				EventId: mock.Event.Id.String(),
-				UserId:  mock.Event.UserId,
+			in: &pb.GrantAccessRequest{
+				Grant:  &pb.Grant{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 23, Role: "viewer"},
+				UserId: 22,
 			},
 			expected: expectation{
-				out: &pb.DeleteEventReply{Err: ""},
-				err: errors.New(""),
+				out: &pb.Grant{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 23, Role: "viewer"},
 			},
 		},
-		"Not_Exist_Event": {
-			in: &pb.DeleteEventRequest{
-				EventId: mock.Event.Id.String(),
-				UserId:  mock.Event.UserId + 1,
+		"Shared_Calendar_Owner": {
+			in: &pb.GrantAccessRequest{
+				Grant:  &pb.Grant{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 27, Role: "owner"},
+				UserId: 31,
 			},
 			expected: expectation{
-				out: &pb.DeleteEventReply{Err: "rpc error: code = Unknown desc = failed to delete event"},
-				err: errors.New("rpc error: code = Unknown desc = failed to delete event"),
+				out: &pb.Grant{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 27, Role: "owner"},
+			},
+		},
+		"Shared_Calendar_Editor": {
+			in: &pb.GrantAccessRequest{
+				Grant:  &pb.Grant{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 23, Role: "viewer"},
+				UserId: 27,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = the calendar is not shared with the required access"),
+			},
+		},
+		"Calendar_User": {
+			in: &pb.GrantAccessRequest{
+				Grant:  &pb.Grant{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 22, Role: "viewer"},
+				UserId: 31,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[user_id:must not be the user of the calendar]"),
+			},
+		},
+		"Invalid_Role": {
+			in: &pb.GrantAccessRequest{
+				Grant:  &pb.Grant{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 23, Role: "admin"},
+				UserId: 22,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[role:must be owner, editor, viewer or freebusy]"),
+			},
+		},
+		"Not_Shared_Calendar": {
+			in: &pb.GrantAccessRequest{
+				Grant:  &pb.Grant{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 24, Role: "viewer"},
+				UserId: 23,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = NotFound desc = record not found"),
+			},
+		},
+		"Invalid_Calendar_Id": {
+			in: &pb.GrantAccessRequest{
+				Grant:  &pb.Grant{CalendarId: "invalid", UserId: 23, Role: "viewer"},
+				UserId: 22,
+			},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = invalid calendar id"),
 			},
 		},
 	}
 
 	for scenario, tt := range tests {
 		t.Run(scenario, func(t *testing.T) {
-			out, err := client.DeleteEvent(ctx, tt.in)
+			out, err := client.GrantAccess(ctx, tt.in)
 			if err != nil {
-				if tt.expected.err.Error() != err.Error() {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
 					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
 				}
-			} else {
-				if !deepEqualDeleteEvent(tt.expected.out, out) {
-					t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+				return
+			}
+			if tt.expected.out == nil || !proto.Equal(tt.expected.out, out.Grant) {
+				t.Errorf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out)
+			}
+		})
+	}
+}
+
+func TestCalendarService_ListGrants(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	type expectation struct {
+		out []*pb.Grant
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.ListGrantsRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.ListGrantsRequest{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 22},
+			expected: expectation{
+				out: []*pb.Grant{
+					{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 27, Role: "editor"},
+					{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 28, Role: "freebusy"},
+					{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 29, Role: "viewer"},
+					{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 31, Role: "owner"},
+				},
+			},
+		},
+		"Shared_Calendar_Viewer": {
+			in: &pb.ListGrantsRequest{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 29},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = the calendar is not shared with the required access"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.ListGrants(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if tt.expected.err != nil || len(tt.expected.out) != len(out.Grants) {
+				t.Fatalf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out.Grants)
+			}
+			for i, grant := range tt.expected.out {
+				if !proto.Equal(grant, out.Grants[i]) {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", grant, out.Grants[i])
 				}
 			}
+		})
+	}
+}
+
+func TestCalendarService_RevokeAccess(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	tests := map[string]struct {
+		in  *pb.RevokeAccessRequest
+		err error
+	}{
+		"Must_Success": {
+			in: &pb.RevokeAccessRequest{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 22, GranteeId: 27},
+		},
+		"Leave_Calendar": {
+			in: &pb.RevokeAccessRequest{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 28, GranteeId: 28},
+		},
+		"Shared_Calendar_Editor": {
+			in:  &pb.RevokeAccessRequest{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 27, GranteeId: 29},
+			err: errors.New("rpc error: code = PermissionDenied desc = the calendar is not shared with the required access"),
+		},
+		"Not_Shared": {
+			in:  &pb.RevokeAccessRequest{CalendarId: mock.WorkCalendar.Id.Hex(), UserId: 22, GranteeId: 23},
+			err: errors.New("rpc error: code = NotFound desc = record not found"),
+		},
+	}
 
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			_, err := client.RevokeAccess(ctx, tt.in)
+			if (err == nil) != (tt.err == nil) || (err != nil && tt.err.Error() != err.Error()) {
+				t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.err, err)
+			}
 		})
 	}
+}
+
+func TestCalendarService_FreeBusy(t *testing.T) {
+	ctx := context.Background()
+
+	client, closer := Server(ctx)
+	defer closer()
+
+	week := func(weeks int, hour int) time.Time {
+		return time.Date(2022, time.January, 3+7*weeks, hour, 0, 0, 0, time.UTC)
+	}
+	interval := func(start, end time.Time) *pb.Interval {
+		return &pb.Interval{Start: timestamppb.New(start), End: timestamppb.New(end)}
+	}
+
+	type expectation struct {
+		out []*pb.UserFreeBusy
+		err error
+	}
+
+	tests := map[string]struct {
+		in       *pb.FreeBusyRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.FreeBusyRequest{UserId: 24, UserIds: []uint64{24},
+				From: timestamppb.New(week(0, 0)), To: timestamppb.New(week(4, 0))},
+			expected: expectation{
+				out: []*pb.UserFreeBusy{{UserId: 24, Busy: []*pb.Interval{
+					interval(week(0, 9), week(0, 10)),
+					interval(week(1, 14), week(1, 15)),
+					interval(week(2, 9), week(2, 10)),
+					interval(week(3, 9), week(3, 10)),
+				}}},
+			},
+		},
+		"Clipped_Window": {
+			in: &pb.FreeBusyRequest{UserId: 24, UserIds: []uint64{24, 24},
+				From: timestamppb.New(week(0, 9).Add(30 * time.Minute)), To: timestamppb.New(week(1, 14).Add(30 * time.Minute))},
+			expected: expectation{
+				out: []*pb.UserFreeBusy{{UserId: 24, Busy: []*pb.Interval{
+					interval(week(0, 9).Add(30*time.Minute), week(0, 10)),
+					interval(week(1, 14), week(1, 14).Add(30*time.Minute)),
+				}}},
+			},
+		},
+		"Public_Calendar": {
+			in: &pb.FreeBusyRequest{UserId: 23, UserIds: []uint64{22},
+				From: timestamppb.New(week(0, 0)), To: timestamppb.New(week(4, 0))},
+			expected: expectation{
+				out: []*pb.UserFreeBusy{{UserId: 22}},
+			},
+		},
+		"Not_Shared_Calendar": {
+			in: &pb.FreeBusyRequest{UserId: 22, UserIds: []uint64{24},
+				From: timestamppb.New(week(0, 0)), To: timestamppb.New(week(4, 0))},
+			expected: expectation{
+				out: []*pb.UserFreeBusy{{UserId: 24, Err: "the calendar is not shared with the required access"}},
+			},
+		},
+		"Empty_User_Ids": {
+			in: &pb.FreeBusyRequest{UserId: 24,
+				From: timestamppb.New(week(0, 0)), To: timestamppb.New(week(4, 0))},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[user_ids:must be provided]"),
+			},
+		},
+		"Too_Long_Window": {
+			in: &pb.FreeBusyRequest{UserId: 24, UserIds: []uint64{24},
+				From: timestamppb.New(week(0, 0)), To: timestamppb.New(week(0, 0).AddDate(2, 0, 0))},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[to:must not be more than 366 days after from]"),
+			},
+		},
+	}
 
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.FreeBusy(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if tt.expected.err != nil || len(tt.expected.out) != len(out.Users) {
+				t.Fatalf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out.Users)
+			}
+			for i, user := range tt.expected.out {
+				if !proto.Equal(user, out.Users[i]) {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", user, out.Users[i])
+				}
+			}
+		})
+	}
 }
 
-func TestCalendarService_ServiceStatus(t *testing.T) {
+func TestCalendarService_FindMeetingSlots(t *testing.T) {
 	ctx := context.Background()
 
 	client, closer := Server(ctx)
 	defer closer()
 
-	expected := errors.New("")
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2022, time.January, day, hour, minute, 0, 0, time.UTC)
+	}
+	slot := func(start time.Time) *pb.Interval {
+		return &pb.Interval{Start: timestamppb.New(start), End: timestamppb.New(start.Add(time.Hour))}
+	}
+	workingHours := &pb.WorkingHours{Start: "09:00", End: "17:00"}
 
-	_, err := client.ServiceStatus(ctx, &pb.ServiceStatusRequest{})
+	type expectation struct {
+		out []*pb.Interval
+		err error
+	}
 
-	if err != nil {
-		if expected.Error() != err.Error() {
-			t.Errorf("Err -> \nWant: %v;\nGot: %v", expected, err)
-		}
+	tests := map[string]struct {
+		in       *pb.FindMeetingSlotsRequest
+		expected expectation
+	}{
+		"Must_Success": {
+			in: &pb.FindMeetingSlotsRequest{UserId: 24, Duration: 60, WorkingHours: workingHours, MaxResults: 3,
+				From: timestamppb.New(at(3, 0, 0)), To: timestamppb.New(at(5, 0, 0))},
+			expected: expectation{
+				out: []*pb.Interval{slot(at(3, 12, 30)), slot(at(4, 12, 30)), slot(at(3, 11, 30))},
+			},
+		},
+		"Recurring_Event": {
+			in: &pb.FindMeetingSlotsRequest{UserId: 24, Duration: 60,
+				WorkingHours: &pb.WorkingHours{Start: "09:00", End: "10:00"},
+				From:         timestamppb.New(at(3, 0, 0)), To: timestamppb.New(at(12, 0, 0))},
+			expected: expectation{
+				out: []*pb.Interval{slot(at(4, 9, 0)), slot(at(5, 9, 0)), slot(at(6, 9, 0)), slot(at(7, 9, 0)),
+					slot(at(10, 9, 0)), slot(at(11, 9, 0))},
+			},
+		},
+		"Time_Zones": {
+			in: &pb.FindMeetingSlotsRequest{UserId: 25, UserIds: []uint64{22}, Duration: 60, WorkingHours: workingHours,
+				MaxResults: 1,
+				From:       timestamppb.New(time.Date(2022, time.March, 28, 0, 0, 0, 0, time.UTC)),
+				To:         timestamppb.New(time.Date(2022, time.March, 29, 0, 0, 0, 0, time.UTC))},
+			expected: expectation{
+				out: []*pb.Interval{slot(time.Date(2022, time.March, 28, 10, 30, 0, 0, time.UTC))},
+			},
+		},
+		"No_Slot": {
+			in: &pb.FindMeetingSlotsRequest{UserId: 24, Duration: 60, WorkingHours: workingHours,
+				From: timestamppb.New(at(1, 0, 0)), To: timestamppb.New(at(3, 0, 0))},
+			expected: expectation{
+				out: []*pb.Interval{},
+			},
+		},
+		"Not_Shared_Calendar": {
+			in: &pb.FindMeetingSlotsRequest{UserId: 22, UserIds: []uint64{24}, Duration: 60,
+				From: timestamppb.New(at(3, 0, 0)), To: timestamppb.New(at(5, 0, 0))},
+			expected: expectation{
+				err: errors.New("rpc error: code = PermissionDenied desc = the calendar is not shared with the required access"),
+			},
+		},
+		"Invalid_Duration": {
+			in: &pb.FindMeetingSlotsRequest{UserId: 24,
+				From: timestamppb.New(at(3, 0, 0)), To: timestamppb.New(at(5, 0, 0))},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[duration:must be between 1 and 1440 minutes]"),
+			},
+		},
+		"Invalid_Working_Hours": {
+			in: &pb.FindMeetingSlotsRequest{UserId: 24, Duration: 60, WorkingHours: &pb.WorkingHours{Start: "9am", End: "17:00"},
+				From: timestamppb.New(at(3, 0, 0)), To: timestamppb.New(at(5, 0, 0))},
+			expected: expectation{
+				err: errors.New("rpc error: code = InvalidArgument desc = map[working_hours:must have a start as 15:04]"),
+			},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			out, err := client.FindMeetingSlots(ctx, tt.in)
+			if err != nil {
+				if tt.expected.err == nil || tt.expected.err.Error() != err.Error() {
+					t.Errorf("Err -> Want: \n%q\n;Got: \n%q\n", tt.expected.err, err)
+				}
+				return
+			}
+			if tt.expected.err != nil || len(tt.expected.out) != len(out.Slots) {
+				t.Fatalf("Out -> \nWant: %q;\nGot: %q", tt.expected.out, out.Slots)
+			}
+			for i, slot := range tt.expected.out {
+				if !proto.Equal(slot, out.Slots[i]) {
+					t.Errorf("Out -> \nWant: %q;\nGot: %q", slot, out.Slots[i])
+				}
+			}
+		})
 	}
 }
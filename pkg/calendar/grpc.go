@@ -4,15 +4,53 @@ import (
 	"context"
 	"github.com/3n0ugh/kalenderium/pkg/calendar/pb"
 	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/go-kit/kit/endpoint"
 	grpcTransport "github.com/go-kit/kit/transport/grpc"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type gRPCServer struct {
-	createEvent   grpcTransport.Handler
-	listEvent     grpcTransport.Handler
-	deleteEvent   grpcTransport.Handler
-	serviceStatus grpcTransport.Handler
+	createEvent        grpcTransport.Handler
+	listEvent          grpcTransport.Handler
+	searchEvents       grpcTransport.Handler
+	listTags           grpcTransport.Handler
+	getEvent           grpcTransport.Handler
+	updateEvent        grpcTransport.Handler
+	deleteEvent        grpcTransport.Handler
+	listTrash          grpcTransport.Handler
+	restoreEvent       grpcTransport.Handler
+	purgeEvent         grpcTransport.Handler
+	listHistory        grpcTransport.Handler
+	revertEvent        grpcTransport.Handler
+	inviteAttendees    grpcTransport.Handler
+	respondEvent       grpcTransport.Handler
+	importEvents       grpcTransport.Handler
+	getEventByUID      grpcTransport.Handler
+	getEventByResource grpcTransport.Handler
+	createCalendar     grpcTransport.Handler
+	listCalendars      grpcTransport.Handler
+	updateCalendar     grpcTransport.Handler
+	deleteCalendar     grpcTransport.Handler
+	grantAccess        grpcTransport.Handler
+	listGrants         grpcTransport.Handler
+	revokeAccess       grpcTransport.Handler
+	freeBusy           grpcTransport.Handler
+	findMeetingSlots   grpcTransport.Handler
+	serviceStatus      grpcTransport.Handler
+
+	batchCreateEvents grpcTransport.Handler
+	batchDeleteEvents grpcTransport.Handler
+
+	// The go-kit gRPC transport does not serve the streams, so their endpoints are called directly
+	watchEvents endpoint.Endpoint
+
+	addAttachment    grpcTransport.Handler
+	getAttachment    grpcTransport.Handler
+	deleteAttachment grpcTransport.Handler
 }
 
 func NewGRPCServer(ep Set) pb.CalendarServer {
@@ -25,21 +63,136 @@ func NewGRPCServer(ep Set) pb.CalendarServer {
 			ep.ListEventEndpoint,
 			decodeListEventRequest,
 			encodeListEventResponse),
+		searchEvents: grpcTransport.NewServer(
+			ep.SearchEventsEndpoint,
+			decodeSearchEventsRequest,
+			encodeSearchEventsResponse),
+		listTags: grpcTransport.NewServer(
+			ep.ListTagsEndpoint,
+			decodeListTagsRequest,
+			encodeListTagsResponse),
+		getEvent: grpcTransport.NewServer(
+			ep.GetEventEndpoint,
+			decodeGetEventRequest,
+			encodeGetEventResponse),
+		updateEvent: grpcTransport.NewServer(
+			ep.UpdateEventEndpoint,
+			decodeUpdateEventRequest,
+			encodeUpdateEventResponse),
 		deleteEvent: grpcTransport.NewServer(
 			ep.DeleteEventEndpoint,
 			decodeDeleteEventRequest,
 			encodeDeleteEventResponse),
+		listTrash: grpcTransport.NewServer(
+			ep.ListTrashEndpoint,
+			decodeListTrashRequest,
+			encodeListTrashResponse),
+		restoreEvent: grpcTransport.NewServer(
+			ep.RestoreEventEndpoint,
+			decodeRestoreEventRequest,
+			encodeRestoreEventResponse),
+		purgeEvent: grpcTransport.NewServer(
+			ep.PurgeEventEndpoint,
+			decodePurgeEventRequest,
+			encodePurgeEventResponse),
+		listHistory: grpcTransport.NewServer(
+			ep.ListHistoryEndpoint,
+			decodeListHistoryRequest,
+			encodeListHistoryResponse),
+		revertEvent: grpcTransport.NewServer(
+			ep.RevertEventEndpoint,
+			decodeRevertEventRequest,
+			encodeRevertEventResponse),
+		inviteAttendees: grpcTransport.NewServer(
+			ep.InviteAttendeesEndpoint,
+			decodeInviteAttendeesRequest,
+			encodeInviteAttendeesResponse),
+		respondEvent: grpcTransport.NewServer(
+			ep.RespondEventEndpoint,
+			decodeRespondEventRequest,
+			encodeRespondEventResponse),
+		importEvents: grpcTransport.NewServer(
+			ep.ImportEventsEndpoint,
+			decodeImportEventsRequest,
+			encodeImportEventsResponse),
+		getEventByUID: grpcTransport.NewServer(
+			ep.GetEventByUIDEndpoint,
+			decodeGetEventByUIDRequest,
+			encodeGetEventByUIDResponse),
+		getEventByResource: grpcTransport.NewServer(
+			ep.GetEventByResourceEndpoint,
+			decodeGetEventByResourceRequest,
+			encodeGetEventByResourceResponse),
+		createCalendar: grpcTransport.NewServer(
+			ep.CreateCalendarEndpoint,
+			decodeCreateCalendarRequest,
+			encodeCreateCalendarResponse),
+		listCalendars: grpcTransport.NewServer(
+			ep.ListCalendarsEndpoint,
+			decodeListCalendarsRequest,
+			encodeListCalendarsResponse),
+		updateCalendar: grpcTransport.NewServer(
+			ep.UpdateCalendarEndpoint,
+			decodeUpdateCalendarRequest,
+			encodeUpdateCalendarResponse),
+		deleteCalendar: grpcTransport.NewServer(
+			ep.DeleteCalendarEndpoint,
+			decodeDeleteCalendarRequest,
+			encodeDeleteCalendarResponse),
+		grantAccess: grpcTransport.NewServer(
+			ep.GrantAccessEndpoint,
+			decodeGrantAccessRequest,
+			encodeGrantAccessResponse),
+		listGrants: grpcTransport.NewServer(
+			ep.ListGrantsEndpoint,
+			decodeListGrantsRequest,
+			encodeListGrantsResponse),
+		revokeAccess: grpcTransport.NewServer(
+			ep.RevokeAccessEndpoint,
+			decodeRevokeAccessRequest,
+			encodeRevokeAccessResponse),
+		freeBusy: grpcTransport.NewServer(
+			ep.FreeBusyEndpoint,
+			decodeFreeBusyRequest,
+			encodeFreeBusyResponse),
+		findMeetingSlots: grpcTransport.NewServer(
+			ep.FindMeetingSlotsEndpoint,
+			decodeFindMeetingSlotsRequest,
+			encodeFindMeetingSlotsResponse),
 		serviceStatus: grpcTransport.NewServer(
 			ep.ServiceStatusEndpoint,
 			decodeServiceStatusRequest,
 			encodeServiceStatusResponse),
+		batchCreateEvents: grpcTransport.NewServer(
+			ep.BatchCreateEventsEndpoint,
+			decodeBatchCreateEventsRequest,
+			encodeBatchCreateEventsResponse),
+		batchDeleteEvents: grpcTransport.NewServer(
+			ep.BatchDeleteEventsEndpoint,
+			decodeBatchDeleteEventsRequest,
+			encodeBatchDeleteEventsResponse),
+
+		watchEvents: ep.WatchEventsEndpoint,
+
+		addAttachment: grpcTransport.NewServer(
+			ep.AddAttachmentEndpoint,
+			decodeAddAttachmentRequest,
+			encodeAddAttachmentResponse),
+		getAttachment: grpcTransport.NewServer(
+			ep.GetAttachmentEndpoint,
+			decodeGetAttachmentRequest,
+			encodeGetAttachmentResponse),
+		deleteAttachment: grpcTransport.NewServer(
+			ep.DeleteAttachmentEndpoint,
+			decodeDeleteAttachmentRequest,
+			encodeDeleteAttachmentResponse),
 	}
 }
 
 func (g *gRPCServer) CreateEvent(ctx context.Context, r *pb.CreateEventRequest) (*pb.CreateEventReply, error) {
 	_, resp, err := g.createEvent.ServeGRPC(ctx, r)
 	if err != nil {
-		return nil, err
+		return nil, encodeError(err)
 	}
 	return resp.(*pb.CreateEventReply), nil
 }
@@ -47,19 +200,251 @@ func (g *gRPCServer) CreateEvent(ctx context.Context, r *pb.CreateEventRequest)
 func (g *gRPCServer) ListEvent(ctx context.Context, r *pb.ListEventRequest) (*pb.ListEventReply, error) {
 	_, resp, err := g.listEvent.ServeGRPC(ctx, r)
 	if err != nil {
-		return nil, err
+		return nil, encodeError(err)
 	}
 	return resp.(*pb.ListEventReply), nil
 }
 
+func (g *gRPCServer) SearchEvents(ctx context.Context, r *pb.SearchEventsRequest) (*pb.SearchEventsReply, error) {
+	_, resp, err := g.searchEvents.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.SearchEventsReply), nil
+}
+
+func (g *gRPCServer) ListTags(ctx context.Context, r *pb.ListTagsRequest) (*pb.ListTagsReply, error) {
+	_, resp, err := g.listTags.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.ListTagsReply), nil
+}
+
+func (g *gRPCServer) GetEvent(ctx context.Context, r *pb.GetEventRequest) (*pb.GetEventReply, error) {
+	_, resp, err := g.getEvent.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.GetEventReply), nil
+}
+
+func (g *gRPCServer) UpdateEvent(ctx context.Context, r *pb.UpdateEventRequest) (*pb.UpdateEventReply, error) {
+	_, resp, err := g.updateEvent.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.UpdateEventReply), nil
+}
+
 func (g *gRPCServer) DeleteEvent(ctx context.Context, r *pb.DeleteEventRequest) (*pb.DeleteEventReply, error) {
 	_, resp, err := g.deleteEvent.ServeGRPC(ctx, r)
 	if err != nil {
-		return nil, err
+		return nil, encodeError(err)
 	}
 	return resp.(*pb.DeleteEventReply), nil
 }
 
+func (g *gRPCServer) BatchCreateEvents(ctx context.Context, r *pb.BatchCreateEventsRequest) (*pb.BatchCreateEventsReply, error) {
+	_, resp, err := g.batchCreateEvents.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.BatchCreateEventsReply), nil
+}
+
+func (g *gRPCServer) BatchDeleteEvents(ctx context.Context, r *pb.BatchDeleteEventsRequest) (*pb.BatchDeleteEventsReply, error) {
+	_, resp, err := g.batchDeleteEvents.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.BatchDeleteEventsReply), nil
+}
+
+func (g *gRPCServer) ListTrash(ctx context.Context, r *pb.ListTrashRequest) (*pb.ListTrashReply, error) {
+	_, resp, err := g.listTrash.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.ListTrashReply), nil
+}
+
+func (g *gRPCServer) RestoreEvent(ctx context.Context, r *pb.RestoreEventRequest) (*pb.RestoreEventReply, error) {
+	_, resp, err := g.restoreEvent.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.RestoreEventReply), nil
+}
+
+func (g *gRPCServer) PurgeEvent(ctx context.Context, r *pb.PurgeEventRequest) (*pb.PurgeEventReply, error) {
+	_, resp, err := g.purgeEvent.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.PurgeEventReply), nil
+}
+
+func (g *gRPCServer) ListHistory(ctx context.Context, r *pb.ListHistoryRequest) (*pb.ListHistoryReply, error) {
+	_, resp, err := g.listHistory.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.ListHistoryReply), nil
+}
+
+func (g *gRPCServer) RevertEvent(ctx context.Context, r *pb.RevertEventRequest) (*pb.RevertEventReply, error) {
+	_, resp, err := g.revertEvent.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.RevertEventReply), nil
+}
+
+func (g *gRPCServer) WatchEvents(r *pb.WatchEventsRequest, stream pb.Calendar_WatchEventsServer) error {
+	_, err := g.watchEvents(stream.Context(), decodeWatchEventsRequest(r, stream))
+	if err != nil {
+		return encodeError(err)
+	}
+	return nil
+}
+
+func (g *gRPCServer) AddAttachment(ctx context.Context, r *pb.AddAttachmentRequest) (*pb.AddAttachmentReply, error) {
+	_, resp, err := g.addAttachment.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.AddAttachmentReply), nil
+}
+
+func (g *gRPCServer) GetAttachment(ctx context.Context, r *pb.GetAttachmentRequest) (*pb.GetAttachmentReply, error) {
+	_, resp, err := g.getAttachment.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.GetAttachmentReply), nil
+}
+
+func (g *gRPCServer) DeleteAttachment(ctx context.Context, r *pb.DeleteAttachmentRequest) (*pb.DeleteAttachmentReply, error) {
+	_, resp, err := g.deleteAttachment.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.DeleteAttachmentReply), nil
+}
+
+func (g *gRPCServer) InviteAttendees(ctx context.Context, r *pb.InviteAttendeesRequest) (*pb.InviteAttendeesReply, error) {
+	_, resp, err := g.inviteAttendees.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.InviteAttendeesReply), nil
+}
+
+func (g *gRPCServer) RespondEvent(ctx context.Context, r *pb.RespondEventRequest) (*pb.RespondEventReply, error) {
+	_, resp, err := g.respondEvent.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.RespondEventReply), nil
+}
+
+func (g *gRPCServer) ImportEvents(ctx context.Context, r *pb.ImportEventsRequest) (*pb.ImportEventsReply, error) {
+	_, resp, err := g.importEvents.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.ImportEventsReply), nil
+}
+
+func (g *gRPCServer) GetEventByUID(ctx context.Context, r *pb.GetEventByUIDRequest) (*pb.GetEventByUIDReply, error) {
+	_, resp, err := g.getEventByUID.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.GetEventByUIDReply), nil
+}
+
+func (g *gRPCServer) GetEventByResource(ctx context.Context, r *pb.GetEventByResourceRequest) (*pb.GetEventByResourceReply, error) {
+	_, resp, err := g.getEventByResource.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.GetEventByResourceReply), nil
+}
+
+func (g *gRPCServer) CreateCalendar(ctx context.Context, r *pb.CreateCalendarRequest) (*pb.CreateCalendarReply, error) {
+	_, resp, err := g.createCalendar.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.CreateCalendarReply), nil
+}
+
+func (g *gRPCServer) ListCalendars(ctx context.Context, r *pb.ListCalendarsRequest) (*pb.ListCalendarsReply, error) {
+	_, resp, err := g.listCalendars.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.ListCalendarsReply), nil
+}
+
+func (g *gRPCServer) UpdateCalendar(ctx context.Context, r *pb.UpdateCalendarRequest) (*pb.UpdateCalendarReply, error) {
+	_, resp, err := g.updateCalendar.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.UpdateCalendarReply), nil
+}
+
+func (g *gRPCServer) DeleteCalendar(ctx context.Context, r *pb.DeleteCalendarRequest) (*pb.DeleteCalendarReply, error) {
+	_, resp, err := g.deleteCalendar.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.DeleteCalendarReply), nil
+}
+
+func (g *gRPCServer) GrantAccess(ctx context.Context, r *pb.GrantAccessRequest) (*pb.GrantAccessReply, error) {
+	_, resp, err := g.grantAccess.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.GrantAccessReply), nil
+}
+
+func (g *gRPCServer) ListGrants(ctx context.Context, r *pb.ListGrantsRequest) (*pb.ListGrantsReply, error) {
+	_, resp, err := g.listGrants.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.ListGrantsReply), nil
+}
+
+func (g *gRPCServer) RevokeAccess(ctx context.Context, r *pb.RevokeAccessRequest) (*pb.RevokeAccessReply, error) {
+	_, resp, err := g.revokeAccess.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.RevokeAccessReply), nil
+}
+
+func (g *gRPCServer) FreeBusy(ctx context.Context, r *pb.FreeBusyRequest) (*pb.FreeBusyReply, error) {
+	_, resp, err := g.freeBusy.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.FreeBusyReply), nil
+}
+
+func (g *gRPCServer) FindMeetingSlots(ctx context.Context, r *pb.FindMeetingSlotsRequest) (*pb.FindMeetingSlotsReply, error) {
+	_, resp, err := g.findMeetingSlots.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return resp.(*pb.FindMeetingSlotsReply), nil
+}
+
 func (g *gRPCServer) ServiceStatus(ctx context.Context, r *pb.ServiceStatusRequest) (*pb.ServiceStatusReply, error) {
 	_, resp, err := g.serviceStatus.ServeGRPC(ctx, r)
 	if err != nil {
@@ -72,28 +457,79 @@ func (g *gRPCServer) ServiceStatus(ctx context.Context, r *pb.ServiceStatusReque
 func decodeCreateEventRequest(_ context.Context, req interface{}) (interface{}, error) {
 	request := req.(*pb.CreateEventRequest)
 
-	event := repository.Event{
-		UserId:  request.Event.UserId,
-		Name:    request.Event.Name,
-		Details: request.Event.Details,
-		Start:   request.Event.Start.AsTime(),
-		End:     request.Event.End.AsTime(),
-		Color:   request.Event.Color,
+	event, err := decodeNewEvent(request.Event)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	return CreateEventRequest{Event: event}, nil
+	return CreateEventRequest{Event: event, ConflictPolicy: request.ConflictPolicy}, nil
+}
+
+// decodeBatchCreateEventsRequest extracts a user-domain request object from a gRPC request
+func decodeBatchCreateEventsRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.BatchCreateEventsRequest)
+
+	events := make([]repository.Event, len(request.Events))
+	for i, e := range request.Events {
+		event, err := decodeNewEvent(e)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, errors.Wrapf(err, "events[%d]", i).Error())
+		}
+		events[i] = event
+	}
+
+	return BatchCreateEventsRequest{UserId: request.UserId, Events: events}, nil
+}
+
+// encodeBatchCreateEventsResponse encodes the passed response object to the gRPC response message.
+func encodeBatchCreateEventsResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(BatchCreateEventsResponse)
+	return &pb.BatchCreateEventsReply{Results: encodeBatchResults(reply.Results), Err: reply.Err}, nil
+}
+
+// decodeBatchDeleteEventsRequest extracts a user-domain request object from a gRPC request
+func decodeBatchDeleteEventsRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.BatchDeleteEventsRequest)
+	return BatchDeleteEventsRequest{UserId: request.UserId, EventIds: request.EventIds}, nil
+}
+
+// encodeBatchDeleteEventsResponse encodes the passed response object to the gRPC response message.
+func encodeBatchDeleteEventsResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(BatchDeleteEventsResponse)
+	return &pb.BatchDeleteEventsReply{Results: encodeBatchResults(reply.Results), Err: reply.Err}, nil
 }
 
 // encodeCreateEventResponse encodes the passed response object to the gRPC response message.
 func encodeCreateEventResponse(_ context.Context, res interface{}) (interface{}, error) {
 	reply := res.(CreateEventResponse)
-	return &pb.CreateEventReply{EventId: reply.EventId, Err: reply.Err}, nil
+	return &pb.CreateEventReply{EventId: reply.EventId, Conflicts: encodeEvents(reply.Conflicts), Err: reply.Err}, nil
 }
 
 // decodeListEventRequest extracts a user-domain request object from a gRPC request
 func decodeListEventRequest(_ context.Context, req interface{}) (interface{}, error) {
 	request := req.(*pb.ListEventRequest)
-	return ListEventRequest{UserId: request.UserId}, nil
+
+	var filter repository.EventFilter
+	if request.From != nil {
+		filter.From = request.From.AsTime()
+	}
+	if request.To != nil {
+		filter.To = request.To.AsTime()
+	}
+
+	for _, id := range request.CalendarIds {
+		calendarId, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid calendar id")
+		}
+		filter.CalendarIds = append(filter.CalendarIds, calendarId)
+	}
+	filter.Tags = request.Tags
+	filter.NoExpand = request.NoExpand
+
+	page := repository.Page{Size: int(request.PageSize), Token: request.PageToken}
+
+	return ListEventRequest{UserId: request.UserId, Filter: filter, Page: page}, nil
 }
 
 // encodeListEventResponse encodes the passed response object to the gRPC response message.
@@ -102,25 +538,94 @@ func encodeListEventResponse(_ context.Context, res interface{}) (interface{}, e
 
 	var events []*pb.Event
 	for _, e := range reply.Events {
-		event := &pb.Event{
-			Id:      e.Id.Hex(),
-			UserId:  e.UserId,
-			Name:    e.Name,
-			Details: e.Details,
-			Start:   timestamppb.New(e.Start),
-			End:     timestamppb.New(e.End),
-			Color:   e.Color,
-		}
-		events = append(events, event)
+		events = append(events, encodeEvent(e))
+	}
+
+	return &pb.ListEventReply{Events: events, NextPageToken: reply.NextPageToken, Err: ""}, nil
+}
+
+// decodeSearchEventsRequest extracts a user-domain request object from a gRPC request
+func decodeSearchEventsRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.SearchEventsRequest)
+
+	var filter repository.EventFilter
+	if request.From != nil {
+		filter.From = request.From.AsTime()
+	}
+	if request.To != nil {
+		filter.To = request.To.AsTime()
 	}
+	filter.Tags = request.Tags
+
+	page := repository.Page{Size: int(request.PageSize), Token: request.PageToken}
 
-	return &pb.ListEventReply{Events: events, Err: ""}, nil
+	return SearchEventsRequest{UserId: request.UserId, Query: request.Query, Filter: filter, Page: page}, nil
+}
+
+// encodeSearchEventsResponse encodes the passed response object to the gRPC response message.
+func encodeSearchEventsResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(SearchEventsResponse)
+	return &pb.SearchEventsReply{Events: encodeEvents(reply.Events), NextPageToken: reply.NextPageToken, Err: ""}, nil
+}
+
+// decodeListTagsRequest extracts a user-domain request object from a gRPC request
+func decodeListTagsRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.ListTagsRequest)
+	return ListTagsRequest{UserId: request.UserId}, nil
+}
+
+// encodeListTagsResponse encodes the passed response object to the gRPC response message.
+func encodeListTagsResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(ListTagsResponse)
+
+	tags := make([]*pb.TagCount, 0, len(reply.Tags))
+	for _, tag := range reply.Tags {
+		tags = append(tags, &pb.TagCount{Tag: tag.Tag, Count: int32(tag.Count)})
+	}
+	return &pb.ListTagsReply{Tags: tags, Err: reply.Err}, nil
+}
+
+// decodeGetEventRequest extracts a user-domain request object from a gRPC request
+func decodeGetEventRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.GetEventRequest)
+	return GetEventRequest{EventId: request.EventId, UserId: request.UserId}, nil
+}
+
+// encodeGetEventResponse encodes the passed response object to the gRPC response message.
+func encodeGetEventResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(GetEventResponse)
+	return &pb.GetEventReply{Event: encodeEvent(reply.Event), Err: reply.Err}, nil
+}
+
+// decodeUpdateEventRequest extracts a user-domain request object from a gRPC request
+func decodeUpdateEventRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.UpdateEventRequest)
+	if request.Event == nil {
+		return nil, status.Error(codes.InvalidArgument, "event must be provided")
+	}
+
+	event, err := decodeEvent(request.Event)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	target := decodeTarget(request.Scope, request.RecurrenceId)
+
+	return UpdateEventRequest{Event: event, Fields: request.UpdateMask.GetPaths(), Target: target}, nil
+}
+
+// encodeUpdateEventResponse encodes the passed response object to the gRPC response message.
+func encodeUpdateEventResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(UpdateEventResponse)
+	return &pb.UpdateEventReply{Event: encodeEvent(reply.Event), Err: reply.Err}, nil
 }
 
 // decodeDeleteEventRequest extracts a user-domain request object from a gRPC request
 func decodeDeleteEventRequest(_ context.Context, req interface{}) (interface{}, error) {
 	request := req.(*pb.DeleteEventRequest)
-	return DeleteEventRequest{EventId: request.EventId, UserId: request.UserId}, nil
+	target := decodeTarget(request.Scope, request.RecurrenceId)
+	return DeleteEventRequest{EventId: request.EventId, UserId: request.UserId, Target: target,
+		Version: request.Version}, nil
 }
 
 // encodeDeleteEventResponse encodes the passed response object to the gRPC response message.
@@ -129,6 +634,467 @@ func encodeDeleteEventResponse(_ context.Context, res interface{}) (interface{},
 	return &pb.DeleteEventReply{Err: reply.Err}, nil
 }
 
+// decodeListTrashRequest extracts a user-domain request object from a gRPC request
+func decodeListTrashRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.ListTrashRequest)
+	page := repository.Page{Size: int(request.PageSize), Token: request.PageToken}
+	return ListTrashRequest{UserId: request.UserId, Page: page}, nil
+}
+
+// encodeListTrashResponse encodes the passed response object to the gRPC response message.
+func encodeListTrashResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(ListTrashResponse)
+	return &pb.ListTrashReply{Events: encodeEvents(reply.Events), NextPageToken: reply.NextPageToken, Err: ""}, nil
+}
+
+// decodeRestoreEventRequest extracts a user-domain request object from a gRPC request
+func decodeRestoreEventRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.RestoreEventRequest)
+	return RestoreEventRequest{EventId: request.EventId, UserId: request.UserId}, nil
+}
+
+// encodeRestoreEventResponse encodes the passed response object to the gRPC response message.
+func encodeRestoreEventResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(RestoreEventResponse)
+	return &pb.RestoreEventReply{Event: encodeEvent(reply.Event), Err: reply.Err}, nil
+}
+
+// decodePurgeEventRequest extracts a user-domain request object from a gRPC request
+func decodePurgeEventRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.PurgeEventRequest)
+	return PurgeEventRequest{EventId: request.EventId, UserId: request.UserId}, nil
+}
+
+// encodePurgeEventResponse encodes the passed response object to the gRPC response message.
+func encodePurgeEventResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(PurgeEventResponse)
+	return &pb.PurgeEventReply{Err: reply.Err}, nil
+}
+
+// decodeListHistoryRequest extracts a user-domain request object from a gRPC request
+func decodeListHistoryRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.ListHistoryRequest)
+	page := repository.Page{Size: int(request.PageSize), Token: request.PageToken}
+	return ListHistoryRequest{EventId: request.EventId, UserId: request.UserId, Page: page}, nil
+}
+
+// encodeListHistoryResponse encodes the passed response object to the gRPC response message.
+func encodeListHistoryResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(ListHistoryResponse)
+
+	entries := make([]*pb.HistoryEntry, 0, len(reply.Entries))
+	for _, entry := range reply.Entries {
+		entries = append(entries, encodeHistoryEntry(entry))
+	}
+	return &pb.ListHistoryReply{Entries: entries, NextPageToken: reply.NextPageToken, Err: ""}, nil
+}
+
+// decodeRevertEventRequest extracts a user-domain request object from a gRPC request
+func decodeRevertEventRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.RevertEventRequest)
+	return RevertEventRequest{EventId: request.EventId, EntryId: request.EntryId, UserId: request.UserId}, nil
+}
+
+// encodeRevertEventResponse encodes the passed response object to the gRPC response message.
+func encodeRevertEventResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(RevertEventResponse)
+	return &pb.RevertEventReply{Event: encodeEvent(reply.Event), Err: reply.Err}, nil
+}
+
+// decodeWatchEventsRequest extracts a user-domain request object from a gRPC request, the
+// notifications are sent to the stream
+func decodeWatchEventsRequest(r *pb.WatchEventsRequest, stream pb.Calendar_WatchEventsServer) WatchEventsRequest {
+	return WatchEventsRequest{
+		UserId:      r.UserId,
+		ResumeToken: r.ResumeToken,
+		Send: func(notification repository.Notification) error {
+			return stream.Send(encodeNotification(notification))
+		},
+	}
+}
+
+// encodeNotification converts the notification to the gRPC message
+func encodeNotification(notification repository.Notification) *pb.EventNotification {
+	return &pb.EventNotification{
+		Token:     notification.Token,
+		Type:      notification.Type,
+		UserId:    notification.UserId,
+		Event:     encodeEvent(notification.Event),
+		CreatedAt: timestamppb.New(notification.CreatedAt),
+	}
+}
+
+// decodeAddAttachmentRequest extracts a user-domain request object from a gRPC request
+func decodeAddAttachmentRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.AddAttachmentRequest)
+	var attachment repository.Attachment
+	if request.Attachment != nil {
+		attachment.Name, attachment.ContentType = request.Attachment.Name, request.Attachment.ContentType
+	}
+	return AddAttachmentRequest{
+		EventId:    request.EventId,
+		UserId:     request.UserId,
+		Attachment: attachment,
+		Content:    request.Content,
+	}, nil
+}
+
+// encodeAddAttachmentResponse encodes the passed response object to the gRPC response message.
+func encodeAddAttachmentResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(AddAttachmentResponse)
+	return &pb.AddAttachmentReply{Event: encodeEvent(reply.Event), Err: reply.Err}, nil
+}
+
+// decodeGetAttachmentRequest extracts a user-domain request object from a gRPC request
+func decodeGetAttachmentRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.GetAttachmentRequest)
+	return GetAttachmentRequest{
+		EventId:      request.EventId,
+		UserId:       request.UserId,
+		AttachmentId: request.AttachmentId,
+	}, nil
+}
+
+// encodeGetAttachmentResponse encodes the passed response object to the gRPC response message.
+func encodeGetAttachmentResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(GetAttachmentResponse)
+	return &pb.GetAttachmentReply{
+		Attachment: encodeAttachment(reply.Attachment),
+		Content:    reply.Content,
+		Err:        reply.Err,
+	}, nil
+}
+
+// decodeDeleteAttachmentRequest extracts a user-domain request object from a gRPC request
+func decodeDeleteAttachmentRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.DeleteAttachmentRequest)
+	return DeleteAttachmentRequest{
+		EventId:      request.EventId,
+		UserId:       request.UserId,
+		AttachmentId: request.AttachmentId,
+	}, nil
+}
+
+// encodeDeleteAttachmentResponse encodes the passed response object to the gRPC response message.
+func encodeDeleteAttachmentResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(DeleteAttachmentResponse)
+	return &pb.DeleteAttachmentReply{Event: encodeEvent(reply.Event), Err: reply.Err}, nil
+}
+
+// encodeHistoryEntry converts the history entry to the gRPC message
+func encodeHistoryEntry(entry repository.HistoryEntry) *pb.HistoryEntry {
+	changes := make([]*pb.FieldChange, 0, len(entry.Changes))
+	for _, change := range entry.Changes {
+		changes = append(changes, &pb.FieldChange{
+			Field:  change.Field,
+			Before: string(change.Before),
+			After:  string(change.After),
+		})
+	}
+	return &pb.HistoryEntry{
+		Id:        entry.Id.Hex(),
+		EventId:   entry.EventId.Hex(),
+		UserId:    entry.UserId,
+		Action:    entry.Action,
+		Changes:   changes,
+		Event:     encodeEvent(entry.Event),
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+	}
+}
+
+// decodeInviteAttendeesRequest extracts a user-domain request object from a gRPC request
+func decodeInviteAttendeesRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.InviteAttendeesRequest)
+	return InviteAttendeesRequest{
+		EventId:   request.EventId,
+		UserId:    request.UserId,
+		Attendees: decodeAttendees(request.Attendees),
+	}, nil
+}
+
+// encodeInviteAttendeesResponse encodes the passed response object to the gRPC response message.
+func encodeInviteAttendeesResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(InviteAttendeesResponse)
+	return &pb.InviteAttendeesReply{Event: encodeEvent(reply.Event), Err: reply.Err}, nil
+}
+
+// decodeRespondEventRequest extracts a user-domain request object from a gRPC request
+func decodeRespondEventRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.RespondEventRequest)
+	return RespondEventRequest{EventId: request.EventId, UserId: request.UserId, Status: request.Status}, nil
+}
+
+// encodeRespondEventResponse encodes the passed response object to the gRPC response message.
+func encodeRespondEventResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(RespondEventResponse)
+	return &pb.RespondEventReply{Err: reply.Err}, nil
+}
+
+// decodeImportEventsRequest extracts a user-domain request object from a gRPC request
+func decodeImportEventsRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.ImportEventsRequest)
+
+	events := make([]repository.Event, len(request.Events))
+	for i, e := range request.Events {
+		events[i] = repository.Event{
+			Name:       e.Name,
+			Details:    e.Details,
+			Start:      e.Start.AsTime(),
+			End:        e.End.AsTime(),
+			Color:      e.Color,
+			TimeZone:   e.TimeZone,
+			AllDay:     e.AllDay,
+			Reminders:  decodeReminders(e.Reminders),
+			Recurrence: decodeRecurrence(e.Recurrence),
+			UID:        e.Uid,
+			Resource:   e.Resource,
+
+			Transparent: e.Transparent,
+			Tags:        e.Tags,
+		}
+		if e.RecurrenceId != nil {
+			recurrenceId := e.RecurrenceId.AsTime()
+			events[i].RecurrenceId = &recurrenceId
+		}
+	}
+
+	return ImportEventsRequest{UserId: request.UserId, Events: events}, nil
+}
+
+// encodeImportEventsResponse encodes the passed response object to the gRPC response message.
+func encodeImportEventsResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(ImportEventsResponse)
+
+	results := make([]*pb.ImportResult, len(reply.Results))
+	for i, r := range reply.Results {
+		results[i] = &pb.ImportResult{Uid: r.UID, EventId: r.EventId, Created: r.Created, Err: r.Err}
+		if r.RecurrenceId != nil {
+			results[i].RecurrenceId = timestamppb.New(*r.RecurrenceId)
+		}
+	}
+
+	return &pb.ImportEventsReply{Results: results, Err: reply.Err}, nil
+}
+
+// decodeGetEventByUIDRequest extracts a user-domain request object from a gRPC request
+func decodeGetEventByUIDRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.GetEventByUIDRequest)
+	return GetEventByUIDRequest{UID: request.Uid, UserId: request.UserId}, nil
+}
+
+// encodeGetEventByUIDResponse encodes the passed response object to the gRPC response message.
+func encodeGetEventByUIDResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(GetEventByUIDResponse)
+
+	var overrides []*pb.Event
+	for _, e := range reply.Overrides {
+		overrides = append(overrides, encodeEvent(e))
+	}
+	return &pb.GetEventByUIDReply{Event: encodeEvent(reply.Event), Overrides: overrides, Err: reply.Err}, nil
+}
+
+// decodeGetEventByResourceRequest extracts a user-domain request object from a gRPC request
+func decodeGetEventByResourceRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.GetEventByResourceRequest)
+	return GetEventByResourceRequest{Name: request.Name, UserId: request.UserId}, nil
+}
+
+// encodeGetEventByResourceResponse encodes the passed response object to the gRPC response message.
+func encodeGetEventByResourceResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(GetEventByResourceResponse)
+
+	var overrides []*pb.Event
+	for _, e := range reply.Overrides {
+		overrides = append(overrides, encodeEvent(e))
+	}
+	return &pb.GetEventByResourceReply{Event: encodeEvent(reply.Event), Overrides: overrides, Err: reply.Err}, nil
+}
+
+// decodeCreateCalendarRequest extracts a user-domain request object from a gRPC request
+func decodeCreateCalendarRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.CreateCalendarRequest)
+	if request.Calendar == nil {
+		return nil, status.Error(codes.InvalidArgument, "calendar must be provided")
+	}
+
+	calendar, err := decodeCalendar(request.Calendar)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return CreateCalendarRequest{Calendar: calendar}, nil
+}
+
+// encodeCreateCalendarResponse encodes the passed response object to the gRPC response message.
+func encodeCreateCalendarResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(CreateCalendarResponse)
+	return &pb.CreateCalendarReply{Calendar: encodeCalendar(reply.Calendar), Err: reply.Err}, nil
+}
+
+// decodeListCalendarsRequest extracts a user-domain request object from a gRPC request
+func decodeListCalendarsRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.ListCalendarsRequest)
+	return ListCalendarsRequest{UserId: request.UserId}, nil
+}
+
+// encodeListCalendarsResponse encodes the passed response object to the gRPC response message.
+func encodeListCalendarsResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(ListCalendarsResponse)
+
+	var calendars []*pb.UserCalendar
+	for _, c := range reply.Calendars {
+		calendars = append(calendars, encodeCalendar(c))
+	}
+	return &pb.ListCalendarsReply{Calendars: calendars, Err: reply.Err}, nil
+}
+
+// decodeUpdateCalendarRequest extracts a user-domain request object from a gRPC request
+func decodeUpdateCalendarRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.UpdateCalendarRequest)
+	if request.Calendar == nil {
+		return nil, status.Error(codes.InvalidArgument, "calendar must be provided")
+	}
+
+	calendar, err := decodeCalendar(request.Calendar)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return UpdateCalendarRequest{Calendar: calendar, Fields: request.UpdateMask.GetPaths()}, nil
+}
+
+// encodeUpdateCalendarResponse encodes the passed response object to the gRPC response message.
+func encodeUpdateCalendarResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(UpdateCalendarResponse)
+	return &pb.UpdateCalendarReply{Calendar: encodeCalendar(reply.Calendar), Err: reply.Err}, nil
+}
+
+// decodeDeleteCalendarRequest extracts a user-domain request object from a gRPC request
+func decodeDeleteCalendarRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.DeleteCalendarRequest)
+	return DeleteCalendarRequest{CalendarId: request.CalendarId, UserId: request.UserId}, nil
+}
+
+// encodeDeleteCalendarResponse encodes the passed response object to the gRPC response message.
+func encodeDeleteCalendarResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(DeleteCalendarResponse)
+	return &pb.DeleteCalendarReply{Err: reply.Err}, nil
+}
+
+// decodeGrantAccessRequest extracts a user-domain request object from a gRPC request
+func decodeGrantAccessRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.GrantAccessRequest)
+	if request.Grant == nil {
+		return nil, status.Error(codes.InvalidArgument, "grant must be provided")
+	}
+
+	calendarId, err := primitive.ObjectIDFromHex(request.Grant.CalendarId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid calendar id")
+	}
+	return GrantAccessRequest{
+		Grant:  repository.Grant{CalendarId: calendarId, UserId: request.Grant.UserId, Role: request.Grant.Role},
+		UserId: request.UserId,
+	}, nil
+}
+
+// encodeGrantAccessResponse encodes the passed response object to the gRPC response message.
+func encodeGrantAccessResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(GrantAccessResponse)
+	return &pb.GrantAccessReply{Grant: encodeGrant(reply.Grant), Err: reply.Err}, nil
+}
+
+// decodeListGrantsRequest extracts a user-domain request object from a gRPC request
+func decodeListGrantsRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.ListGrantsRequest)
+	return ListGrantsRequest{CalendarId: request.CalendarId, UserId: request.UserId}, nil
+}
+
+// encodeListGrantsResponse encodes the passed response object to the gRPC response message.
+func encodeListGrantsResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(ListGrantsResponse)
+
+	var grants []*pb.Grant
+	for _, grant := range reply.Grants {
+		grants = append(grants, encodeGrant(grant))
+	}
+	return &pb.ListGrantsReply{Grants: grants, Err: reply.Err}, nil
+}
+
+// decodeRevokeAccessRequest extracts a user-domain request object from a gRPC request
+func decodeRevokeAccessRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.RevokeAccessRequest)
+	return RevokeAccessRequest{CalendarId: request.CalendarId, UserId: request.UserId, GranteeId: request.GranteeId}, nil
+}
+
+// encodeRevokeAccessResponse encodes the passed response object to the gRPC response message.
+func encodeRevokeAccessResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(RevokeAccessResponse)
+	return &pb.RevokeAccessReply{Err: reply.Err}, nil
+}
+
+// decodeFreeBusyRequest extracts a user-domain request object from a gRPC request
+func decodeFreeBusyRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.FreeBusyRequest)
+
+	freeBusy := FreeBusyRequest{UserId: request.UserId, UserIds: request.UserIds}
+	if request.From != nil {
+		freeBusy.From = request.From.AsTime()
+	}
+	if request.To != nil {
+		freeBusy.To = request.To.AsTime()
+	}
+	return freeBusy, nil
+}
+
+// encodeFreeBusyResponse encodes the passed response object to the gRPC response message.
+func encodeFreeBusyResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(FreeBusyResponse)
+
+	var users []*pb.UserFreeBusy
+	for _, freeBusy := range reply.FreeBusy {
+		user := &pb.UserFreeBusy{UserId: freeBusy.UserId, Err: freeBusy.Err}
+		for _, interval := range freeBusy.Busy {
+			user.Busy = append(user.Busy, &pb.Interval{Start: timestamppb.New(interval.Start), End: timestamppb.New(interval.End)})
+		}
+		users = append(users, user)
+	}
+	return &pb.FreeBusyReply{Users: users, Err: reply.Err}, nil
+}
+
+// decodeFindMeetingSlotsRequest extracts a user-domain request object from a gRPC request
+func decodeFindMeetingSlotsRequest(_ context.Context, req interface{}) (interface{}, error) {
+	request := req.(*pb.FindMeetingSlotsRequest)
+
+	query := repository.SlotQuery{
+		UserIds:    request.UserIds,
+		Duration:   int(request.Duration),
+		MaxResults: int(request.MaxResults),
+	}
+	if request.From != nil {
+		query.From = request.From.AsTime()
+	}
+	if request.To != nil {
+		query.To = request.To.AsTime()
+	}
+	if hours := request.WorkingHours; hours != nil {
+		query.WorkingHours = &repository.WorkingHours{Start: hours.Start, End: hours.End}
+		for _, day := range hours.Days {
+			query.WorkingHours.Days = append(query.WorkingHours.Days, int(day))
+		}
+	}
+	return FindMeetingSlotsRequest{UserId: request.UserId, Query: query}, nil
+}
+
+// encodeFindMeetingSlotsResponse encodes the passed response object to the gRPC response message.
+func encodeFindMeetingSlotsResponse(_ context.Context, res interface{}) (interface{}, error) {
+	reply := res.(FindMeetingSlotsResponse)
+
+	var slots []*pb.Interval
+	for _, slot := range reply.Slots {
+		slots = append(slots, &pb.Interval{Start: timestamppb.New(slot.Start), End: timestamppb.New(slot.End)})
+	}
+	return &pb.FindMeetingSlotsReply{Slots: slots, Err: reply.Err}, nil
+}
+
 // decodeServiceStatusRequest extracts a user-domain request object from a gRPC request
 func decodeServiceStatusRequest(_ context.Context, req interface{}) (interface{}, error) {
 	_ = req.(*pb.ServiceStatusRequest)
@@ -140,3 +1106,309 @@ func encodeServiceStatusResponse(_ context.Context, res interface{}) (interface{
 	reply := res.(ServiceStatusResponse)
 	return &pb.ServiceStatusReply{Code: int32(reply.Code), Err: reply.Err}, nil
 }
+
+// decodeEvent converts the gRPC event message to the repository event
+func decodeEvent(e *pb.Event) (repository.Event, error) {
+	id, err := primitive.ObjectIDFromHex(e.Id)
+	if err != nil {
+		return repository.Event{}, errors.Wrap(err, "invalid event id")
+	}
+	calendarId, err := decodeCalendarId(e.CalendarId)
+	if err != nil {
+		return repository.Event{}, err
+	}
+
+	return repository.Event{
+		Id:         id,
+		UserId:     e.UserId,
+		CalendarId: calendarId,
+		Name:       e.Name,
+		Details:    e.Details,
+		Start:      e.Start.AsTime(),
+		End:        e.End.AsTime(),
+		Color:      e.Color,
+		TimeZone:   e.TimeZone,
+		AllDay:     e.AllDay,
+		Reminders:  decodeReminders(e.Reminders),
+		Attendees:  decodeAttendees(e.Attendees),
+		Recurrence: decodeRecurrence(e.Recurrence),
+
+		Transparent: e.Transparent,
+		Tags:        e.Tags,
+		Version:     e.Version,
+	}, nil
+}
+
+// decodeNewEvent converts the gRPC event message of an event which is created, the fields which
+// are set by the calendar service are not read
+func decodeNewEvent(e *pb.Event) (repository.Event, error) {
+	calendarId, err := decodeCalendarId(e.CalendarId)
+	if err != nil {
+		return repository.Event{}, err
+	}
+
+	return repository.Event{
+		UserId:     e.UserId,
+		CalendarId: calendarId,
+		Name:       e.Name,
+		Details:    e.Details,
+		Start:      e.Start.AsTime(),
+		End:        e.End.AsTime(),
+		Color:      e.Color,
+		TimeZone:   e.TimeZone,
+		AllDay:     e.AllDay,
+		Reminders:  decodeReminders(e.Reminders),
+		Attendees:  decodeAttendees(e.Attendees),
+		Recurrence: decodeRecurrence(e.Recurrence),
+
+		Transparent: e.Transparent,
+		Tags:        e.Tags,
+	}, nil
+}
+
+// encodeBatchResults converts the repository batch results to the gRPC batch result messages
+func encodeBatchResults(r []repository.BatchResult) []*pb.BatchResult {
+	results := make([]*pb.BatchResult, len(r))
+	for i, result := range r {
+		results[i] = &pb.BatchResult{EventId: result.EventId, Err: result.Err}
+	}
+	return results
+}
+
+// encodeEvent converts the repository event to the gRPC event message
+func encodeEvent(e repository.Event) *pb.Event {
+	event := &pb.Event{
+		Id:         encodeEventId(e.Id),
+		UserId:     e.UserId,
+		Name:       e.Name,
+		Details:    e.Details,
+		Start:      timestamppb.New(e.Start),
+		End:        timestamppb.New(e.End),
+		Color:      e.Color,
+		TimeZone:   e.TimeZone,
+		AllDay:     e.AllDay,
+		Reminders:  encodeReminders(e.Reminders),
+		CalendarId: encodeCalendarId(e.CalendarId),
+		Attendees:  encodeAttendees(e.Attendees),
+		Recurrence: encodeRecurrence(e.Recurrence),
+		Uid:        e.UID,
+		Resource:   e.Resource,
+
+		Transparent: e.Transparent,
+		Tags:        e.Tags,
+		Version:     e.Version,
+		Attachments: encodeAttachments(e.Attachments),
+	}
+	if e.RecurringEventId != nil {
+		event.RecurringEventId = e.RecurringEventId.Hex()
+	}
+	if e.RecurrenceId != nil {
+		event.RecurrenceId = timestamppb.New(*e.RecurrenceId)
+	}
+	if e.DeletedAt != nil {
+		event.DeletedAt = timestamppb.New(*e.DeletedAt)
+	}
+	return event
+}
+
+// encodeEvents converts the repository events to the gRPC event messages
+func encodeEvents(events []repository.Event) []*pb.Event {
+	var encoded []*pb.Event
+	for _, e := range events {
+		encoded = append(encoded, encodeEvent(e))
+	}
+	return encoded
+}
+
+// decodeCalendarId converts the calendar id of the gRPC event message, it is zero if empty
+func decodeCalendarId(id string) (primitive.ObjectID, error) {
+	if id == "" {
+		return primitive.NilObjectID, nil
+	}
+	calendarId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, errors.Wrap(err, "invalid calendar id")
+	}
+	return calendarId, nil
+}
+
+// encodeEventId converts the id of the repository event, it is empty for the unmodified
+// occurrences of the recurring events
+func encodeEventId(id primitive.ObjectID) string {
+	if id.IsZero() {
+		return ""
+	}
+	return id.Hex()
+}
+
+// encodeCalendarId converts the calendar id of the repository event, it is empty if zero
+func encodeCalendarId(id primitive.ObjectID) string {
+	if id.IsZero() {
+		return ""
+	}
+	return id.Hex()
+}
+
+// decodeCalendar converts the gRPC calendar message to the repository calendar
+func decodeCalendar(c *pb.UserCalendar) (repository.Calendar, error) {
+	calendar := repository.Calendar{
+		UserId:     c.UserId,
+		Name:       c.Name,
+		Color:      c.Color,
+		Visibility: c.Visibility,
+		TimeZone:   c.TimeZone,
+	}
+	if c.Id != "" {
+		id, err := primitive.ObjectIDFromHex(c.Id)
+		if err != nil {
+			return repository.Calendar{}, errors.Wrap(err, "invalid calendar id")
+		}
+		calendar.Id = id
+	}
+	return calendar, nil
+}
+
+// encodeCalendar converts the repository calendar to the gRPC calendar message
+func encodeCalendar(c repository.Calendar) *pb.UserCalendar {
+	return &pb.UserCalendar{
+		Id:         encodeCalendarId(c.Id),
+		UserId:     c.UserId,
+		Name:       c.Name,
+		Color:      c.Color,
+		Visibility: c.Visibility,
+		IsDefault:  c.Default,
+		Role:       c.Role,
+		TimeZone:   c.TimeZone,
+	}
+}
+
+// encodeGrant converts the repository grant to the gRPC grant message
+func encodeGrant(g repository.Grant) *pb.Grant {
+	return &pb.Grant{CalendarId: encodeCalendarId(g.CalendarId), UserId: g.UserId, Role: g.Role}
+}
+
+// decodeRecurrence converts the gRPC recurrence message to the repository recurrence
+func decodeRecurrence(r *pb.Recurrence) *repository.Recurrence {
+	if r == nil {
+		return nil
+	}
+
+	recurrence := &repository.Recurrence{RRule: r.Rrule}
+	for _, exdate := range r.Exdates {
+		recurrence.ExDates = append(recurrence.ExDates, exdate.AsTime())
+	}
+	for _, rdate := range r.Rdates {
+		recurrence.RDates = append(recurrence.RDates, rdate.AsTime())
+	}
+	return recurrence
+}
+
+// encodeRecurrence converts the repository recurrence to the gRPC recurrence message
+func encodeRecurrence(r *repository.Recurrence) *pb.Recurrence {
+	if r == nil {
+		return nil
+	}
+
+	recurrence := &pb.Recurrence{Rrule: r.RRule}
+	for _, exdate := range r.ExDates {
+		recurrence.Exdates = append(recurrence.Exdates, timestamppb.New(exdate))
+	}
+	for _, rdate := range r.RDates {
+		recurrence.Rdates = append(recurrence.Rdates, timestamppb.New(rdate))
+	}
+	return recurrence
+}
+
+// decodeReminders converts the gRPC reminder messages to the repository reminders
+func decodeReminders(r []*pb.Reminder) []repository.Reminder {
+	var reminders []repository.Reminder
+	for _, reminder := range r {
+		reminders = append(reminders, repository.Reminder{Minutes: int(reminder.Minutes), Method: reminder.Method})
+	}
+	return reminders
+}
+
+// encodeReminders converts the repository reminders to the gRPC reminder messages
+func encodeReminders(r []repository.Reminder) []*pb.Reminder {
+	var reminders []*pb.Reminder
+	for _, reminder := range r {
+		reminders = append(reminders, &pb.Reminder{Minutes: int32(reminder.Minutes), Method: reminder.Method})
+	}
+	return reminders
+}
+
+// decodeAttendees converts the gRPC attendee messages to the repository attendees
+func decodeAttendees(a []*pb.Attendee) []repository.Attendee {
+	var attendees []repository.Attendee
+	for _, attendee := range a {
+		attendees = append(attendees, repository.Attendee{UserId: attendee.UserId, Email: attendee.Email, Status: attendee.Status})
+	}
+	return attendees
+}
+
+// encodeAttendees converts the repository attendees to the gRPC attendee messages
+func encodeAttendees(a []repository.Attendee) []*pb.Attendee {
+	var attendees []*pb.Attendee
+	for _, attendee := range a {
+		attendees = append(attendees, &pb.Attendee{UserId: attendee.UserId, Email: attendee.Email, Status: attendee.Status})
+	}
+	return attendees
+}
+
+// encodeAttachment converts the repository attachment to the gRPC attachment message
+func encodeAttachment(a repository.Attachment) *pb.Attachment {
+	return &pb.Attachment{
+		Id:          a.Id.Hex(),
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		CreatedAt:   timestamppb.New(a.CreatedAt),
+	}
+}
+
+// encodeAttachments converts the repository attachments to the gRPC attachment messages, the
+// attachments are not decoded since only the calendar service sets them
+func encodeAttachments(a []repository.Attachment) []*pb.Attachment {
+	var attachments []*pb.Attachment
+	for _, attachment := range a {
+		attachments = append(attachments, encodeAttachment(attachment))
+	}
+	return attachments
+}
+
+// decodeTarget converts the gRPC recurrence scope to the repository target
+func decodeTarget(scope pb.RecurrenceScope, recurrenceId *timestamppb.Timestamp) repository.Target {
+	target := repository.Target{Scope: repository.RecurrenceScope(scope)}
+	if recurrenceId != nil {
+		target.RecurrenceId = recurrenceId.AsTime()
+	}
+	return target
+}
+
+// encodeError converts the domain errors to the gRPC status errors
+func encodeError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrRecordNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, repository.ErrInvalidPageToken), errors.Is(err, repository.ErrNotOccurrence),
+		errors.Is(err, repository.ErrValidation):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, repository.ErrNotOrganizer), errors.Is(err, repository.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, repository.ErrDefaultCalendar), errors.Is(err, repository.ErrVersionMismatch):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, repository.ErrConflict):
+		// The conflicting events are given in the details of the status
+		st := status.New(codes.AlreadyExists, err.Error())
+		var conflict *repository.ConflictError
+		if errors.As(err, &conflict) {
+			reply := &pb.CreateEventReply{Err: err.Error(), Conflicts: encodeEvents(conflict.Conflicts)}
+			if detailed, detailsErr := st.WithDetails(reply); detailsErr == nil {
+				return detailed.Err()
+			}
+		}
+		return st.Err()
+	default:
+		return err
+	}
+}
@@ -0,0 +1,63 @@
+package reminder
+
+import (
+	"context"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/pkg/account/pb"
+	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+)
+
+// Notification is a reminder of an event or an occurrence which is due
+type Notification struct {
+	UserId   uint64
+	Event    repository.Event
+	Reminder repository.Reminder
+}
+
+// Notifier sends the notifications by a reminder method
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// EmailResolver gets the email address of the user
+type EmailResolver interface {
+	Email(ctx context.Context, userId uint64) (string, error)
+}
+
+// accountEmails gets the email addresses from the account service
+type accountEmails struct {
+	client pb.AccountClient
+}
+
+// NewAccountEmails creates an EmailResolver which asks the account service
+func NewAccountEmails(client pb.AccountClient) EmailResolver {
+	return &accountEmails{client: client}
+}
+
+func (a *accountEmails) Email(ctx context.Context, userId uint64) (string, error) {
+	resp, err := a.client.GetEmail(ctx, &pb.GetEmailRequest{UserId: userId})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get email of the user")
+	}
+	return resp.Email, nil
+}
+
+// logNotifier writes the notifications to the log. It is used for the methods which have no
+// notifier configured.
+type logNotifier struct {
+	logger log.Logger
+}
+
+// NewLogNotifier creates a Notifier which logs the notifications
+func NewLogNotifier(logger log.Logger) Notifier {
+	return &logNotifier{logger: logger}
+}
+
+func (l *logNotifier) Notify(_ context.Context, n Notification) error {
+	return l.logger.Log("msg", "reminder", "method", n.Reminder.Method, "user", n.UserId,
+		"event", n.Event.Id.Hex(), "name", n.Event.Name, "start", n.Event.Start.Format(time.RFC3339),
+		"minutes", n.Reminder.Minutes)
+}
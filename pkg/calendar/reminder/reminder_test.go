@@ -0,0 +1,343 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryStore keeps the events and the jobs in memory, the claims are atomic like in the database
+type memoryStore struct {
+	mu     sync.Mutex
+	events []repository.Event
+	jobs   []repository.ReminderJob
+}
+
+func (m *memoryStore) GetEvent(_ context.Context, eventId string, userId uint64) (*repository.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, event := range m.events {
+		if event.UserId == userId && event.Id.Hex() == eventId {
+			return &event, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (m *memoryStore) ListOverrides(_ context.Context, userId uint64, eventIds []primitive.ObjectID) ([]repository.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var overrides []repository.Event
+	for _, event := range m.events {
+		for _, id := range eventIds {
+			if event.UserId == userId && event.RecurringEventId != nil && *event.RecurringEventId == id {
+				overrides = append(overrides, event)
+			}
+		}
+	}
+	return overrides, nil
+}
+
+func (m *memoryStore) ListRemindedEvents(_ context.Context, from, to time.Time) ([]repository.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var events []repository.Event
+	for _, event := range m.events {
+		if event.RecurringEventId == nil && len(event.Reminders) > 0 && event.Start.Before(to) &&
+			(event.IsRecurring() || !event.End.Before(from)) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (m *memoryStore) CreateReminderJobs(_ context.Context, jobs []repository.ReminderJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, job := range jobs {
+		if m.find(job) == nil {
+			job.Id = primitive.NewObjectID()
+			m.jobs = append(m.jobs, job)
+		}
+	}
+	return nil
+}
+
+// find returns the job with the same key
+func (m *memoryStore) find(job repository.ReminderJob) *repository.ReminderJob {
+	for i, other := range m.jobs {
+		sameOccurrence := (other.RecurrenceId == nil && job.RecurrenceId == nil) ||
+			(other.RecurrenceId != nil && job.RecurrenceId != nil && other.RecurrenceId.Equal(*job.RecurrenceId))
+		if other.EventId == job.EventId && sameOccurrence && other.Start.Equal(job.Start) && other.Reminder == job.Reminder {
+			return &m.jobs[i]
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) ClaimReminderJob(_ context.Context, owner string, now time.Time, lease time.Duration) (*repository.ReminderJob, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var claimed *repository.ReminderJob
+	for i, job := range m.jobs {
+		due := job.Status == repository.ReminderPending && !job.DueAt.After(now)
+		expired := job.Status == repository.ReminderSending && job.LeaseUntil.Before(now)
+		if (due || expired) && (claimed == nil || job.DueAt.Before(claimed.DueAt)) {
+			claimed = &m.jobs[i]
+		}
+	}
+	if claimed == nil {
+		return nil, repository.ErrRecordNotFound
+	}
+
+	leaseUntil := now.Add(lease)
+	claimed.Status, claimed.Owner, claimed.LeaseUntil = repository.ReminderSending, owner, &leaseUntil
+	claimed.Attempts++
+	job := *claimed
+	return &job, nil
+}
+
+func (m *memoryStore) FinishReminderJob(_ context.Context, job *repository.ReminderJob) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, other := range m.jobs {
+		if other.Id == job.Id && other.Owner == job.Owner && other.Status == repository.ReminderSending {
+			m.jobs[i].Status, m.jobs[i].DueAt, m.jobs[i].Err, m.jobs[i].LeaseUntil = job.Status, job.DueAt, job.Err, nil
+			return nil
+		}
+	}
+	return repository.ErrRecordNotFound
+}
+
+// recorder records the notifications, it fails the first failures notifications
+type recorder struct {
+	mu            sync.Mutex
+	notifications []Notification
+	failures      int
+}
+
+func (r *recorder) Notify(_ context.Context, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("unavailable")
+	}
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.notifications)
+}
+
+var start = time.Date(2022, time.January, 3, 9, 0, 0, 0, time.UTC)
+
+// newStore creates a single event at start with two email reminders, and a weekly event which
+// started a week before with a webhook reminder
+func newStore() *memoryStore {
+	weeklyStart := start.AddDate(0, 0, -7)
+	return &memoryStore{events: []repository.Event{
+		{
+			Id:        primitive.NewObjectID(),
+			UserId:    22,
+			Name:      "Spring Time",
+			Start:     start,
+			End:       start.Add(time.Hour),
+			Reminders: []repository.Reminder{{Minutes: 15, Method: "email"}, {Minutes: 5, Method: "email"}},
+		},
+		{
+			Id:         primitive.NewObjectID(),
+			UserId:     24,
+			Name:       "Weekly Meeting",
+			Start:      weeklyStart,
+			End:        weeklyStart.Add(time.Hour),
+			Reminders:  []repository.Reminder{{Minutes: 10, Method: "webhook"}},
+			Recurrence: &repository.Recurrence{RRule: "FREQ=WEEKLY;COUNT=4"},
+		},
+	}}
+}
+
+func newTestScheduler(store Store, notifier Notifier, now time.Time) *Scheduler {
+	s := NewScheduler(store, map[string]Notifier{"email": notifier, "webhook": notifier}, Options{}, log.NewNopLogger())
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	ctx := context.Background()
+	store, notifier := newStore(), &recorder{}
+
+	// The 15 and 10 minutes reminders are due, the 5 minutes reminder is not yet
+	now := start.Add(-10 * time.Minute)
+	if err := newTestScheduler(store, notifier, now).Tick(ctx); err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+	if notifier.count() != 2 {
+		t.Fatalf("Out -> Want: 2 notifications; Got: %d", notifier.count())
+	}
+
+	for _, n := range notifier.notifications {
+		if !n.Event.Start.Equal(start) {
+			t.Errorf("Out -> Want: %v; Got: %v", start, n.Event.Start)
+		}
+		if n.Reminder.Method == "webhook" && (n.Event.RecurrenceId == nil || !n.Event.RecurrenceId.Equal(start)) {
+			t.Errorf("Out -> Want: occurrence at %v; Got: %v", start, n.Event.RecurrenceId)
+		}
+	}
+
+	// The sent reminders are not sent again, even after a restart
+	if err := newTestScheduler(store, notifier, now).Tick(ctx); err != nil || notifier.count() != 2 {
+		t.Errorf("Out -> Want: 2 notifications; Got: %d, %v", notifier.count(), err)
+	}
+
+	if err := newTestScheduler(store, notifier, start.Add(-5*time.Minute)).Tick(ctx); err != nil || notifier.count() != 3 {
+		t.Errorf("Out -> Want: 3 notifications; Got: %d, %v", notifier.count(), err)
+	}
+}
+
+func TestScheduler_Replicas(t *testing.T) {
+	ctx := context.Background()
+	store, notifier := newStore(), &recorder{}
+
+	// The replicas plan and send concurrently, every reminder is sent once
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, now := range []time.Time{start.Add(-10 * time.Minute), start.Add(-4 * time.Minute)} {
+				if err := newTestScheduler(store, notifier, now).Tick(ctx); err != nil {
+					t.Errorf("Err -> Want: nil; Got: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if notifier.count() != 3 {
+		t.Errorf("Out -> Want: 3 notifications; Got: %d", notifier.count())
+	}
+}
+
+func TestScheduler_Stale(t *testing.T) {
+	ctx := context.Background()
+	store, notifier := newStore(), &recorder{}
+
+	now := start.Add(-20 * time.Minute)
+	s := newTestScheduler(store, notifier, now)
+	if err := s.plan(ctx, now.Add(6*time.Minute)); err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+
+	// The event is moved an hour later after its reminder is planned
+	store.events[0].Start = store.events[0].Start.Add(time.Hour)
+	store.events[0].End = store.events[0].End.Add(time.Hour)
+
+	if err := newTestScheduler(store, notifier, start.Add(-10*time.Minute)).Tick(ctx); err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+	if notifier.count() != 1 || notifier.notifications[0].Reminder.Method != "webhook" {
+		t.Errorf("Out -> Want: only the webhook; Got: %+v", notifier.notifications)
+	}
+	if store.jobs[0].Status != repository.ReminderCancelled {
+		t.Errorf("Out -> Want: %q; Got: %q", repository.ReminderCancelled, store.jobs[0].Status)
+	}
+}
+
+func TestScheduler_Retry(t *testing.T) {
+	ctx := context.Background()
+	store, notifier := newStore(), &recorder{failures: 2}
+
+	now := start.Add(-10 * time.Minute)
+	if err := newTestScheduler(store, notifier, now).Tick(ctx); err != nil || notifier.count() != 0 {
+		t.Fatalf("Out -> Want: 0 notifications; Got: %d, %v", notifier.count(), err)
+	}
+	for _, job := range store.jobs {
+		if job.Status != repository.ReminderPending || !job.DueAt.After(now) || job.Err == "" {
+			t.Errorf("Out -> Want: retried job; Got: %+v", job)
+		}
+	}
+
+	if err := newTestScheduler(store, notifier, now.Add(time.Minute)).Tick(ctx); err != nil || notifier.count() != 2 {
+		t.Errorf("Out -> Want: 2 notifications; Got: %d, %v", notifier.count(), err)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var payload webhookPayload
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Err -> Want: JSON; Got: %v", err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	event := newStore().events[1]
+	occurrence := event.Occurrence(start)
+	n := Notification{UserId: 24, Event: occurrence, Reminder: event.Reminders[0]}
+
+	if err := NewWebhookNotifier(srv.URL).Notify(context.Background(), n); err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+	if payload.EventId != event.Id.Hex() || payload.RecurrenceId == nil || !payload.Start.Equal(start) || payload.Minutes != 10 {
+		t.Errorf("Out -> Got: %+v", payload)
+	}
+
+	status = http.StatusInternalServerError
+	if err := NewWebhookNotifier(srv.URL).Notify(context.Background(), n); err == nil {
+		t.Errorf("Err -> Want: webhook responded 500; Got: nil")
+	}
+}
+
+type staticEmails string
+
+func (s staticEmails) Email(_ context.Context, _ uint64) (string, error) {
+	return string(s), nil
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	notifier := NewSMTPNotifier(SMTPConfig{Host: "localhost", Port: "25", From: "Kalenderium <no-reply@example.com>"},
+		staticEmails("test@22.com")).(*smtpNotifier)
+
+	var from string
+	var to []string
+	var msg string
+	notifier.send = func(_ string, _ smtp.Auth, f string, rcpt []string, m []byte) error {
+		from, to, msg = f, rcpt, string(m)
+		return nil
+	}
+
+	event := newStore().events[0]
+	event.Name = "Spring\r\nBcc: victim@example.com"
+	if err := notifier.Notify(context.Background(), Notification{UserId: 22, Event: event, Reminder: event.Reminders[0]}); err != nil {
+		t.Fatalf("Err -> Want: nil; Got: %v", err)
+	}
+
+	if from != "no-reply@example.com" || len(to) != 1 || to[0] != "test@22.com" {
+		t.Errorf("Out -> Want: no-reply@example.com -> test@22.com; Got: %s -> %v", from, to)
+	}
+	headers := msg[:strings.Index(msg, "\r\n\r\n")]
+	if strings.Contains(headers, "\r\nBcc:") || !strings.Contains(headers, "Subject: =?utf-8?q?") {
+		t.Errorf("Out -> Want: encoded subject; Got: %q", headers)
+	}
+	if !strings.Contains(msg, "starts on Monday, January 3, 2022 09:00 UTC") {
+		t.Errorf("Out -> Got: %q", msg)
+	}
+}
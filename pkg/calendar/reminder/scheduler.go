@@ -0,0 +1,302 @@
+package reminder
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultInterval    = 30 * time.Second
+	defaultGrace       = time.Hour
+	defaultMaxAttempts = 5
+)
+
+// errStale means the event has changed since the job was planned, the job of the current
+// reminder is planned separately
+var errStale = errors.New("event or reminder has changed")
+
+// Store is the part of the calendar repository which the scheduler uses
+type Store interface {
+	GetEvent(ctx context.Context, eventId string, userId uint64) (*repository.Event, error)
+	ListOverrides(ctx context.Context, userId uint64, eventIds []primitive.ObjectID) ([]repository.Event, error)
+	ListRemindedEvents(ctx context.Context, from, to time.Time) ([]repository.Event, error)
+	CreateReminderJobs(ctx context.Context, jobs []repository.ReminderJob) error
+	ClaimReminderJob(ctx context.Context, owner string, now time.Time, lease time.Duration) (*repository.ReminderJob, error)
+	FinishReminderJob(ctx context.Context, job *repository.ReminderJob) error
+}
+
+// Options tunes the scheduler. Zero values mean the defaults.
+type Options struct {
+	// Interval between the runs, the reminders are sent at most this late
+	Interval time.Duration
+	// Grace is how late a reminder is still sent, e.g. after a downtime
+	Grace time.Duration
+	// MaxAttempts limits the attempts to send a reminder
+	MaxAttempts int
+}
+
+// Scheduler plans the jobs of the due reminders and sends them through the notifiers.
+// The jobs are stored, so the reminders survive the restarts. Every scheduler claims the
+// jobs atomically with a lease, so the replicas do not send the same reminder twice.
+type Scheduler struct {
+	store     Store
+	notifiers map[string]Notifier
+	logger    log.Logger
+
+	owner       string
+	interval    time.Duration
+	grace       time.Duration
+	lease       time.Duration
+	maxAttempts int
+
+	now func() time.Time
+}
+
+// NewScheduler creates a scheduler which sends the reminders by the notifier of their method
+func NewScheduler(store Store, notifiers map[string]Notifier, opts Options, logger log.Logger) *Scheduler {
+	s := &Scheduler{
+		store:       store,
+		notifiers:   notifiers,
+		logger:      logger,
+		owner:       newOwner(),
+		interval:    opts.Interval,
+		grace:       opts.Grace,
+		maxAttempts: opts.MaxAttempts,
+		now:         time.Now,
+	}
+	if s.interval <= 0 {
+		s.interval = defaultInterval
+	}
+	if s.grace <= 0 {
+		s.grace = defaultGrace
+	}
+	if s.maxAttempts <= 0 {
+		s.maxAttempts = defaultMaxAttempts
+	}
+
+	// The notifiers have half of the lease to send, so the job is not claimed again while
+	// it is being sent
+	s.lease = 2 * s.interval
+	if s.lease < time.Minute {
+		s.lease = time.Minute
+	}
+	return s
+}
+
+// newOwner creates a unique name for the scheduler
+func newOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return host + "-" + primitive.NewObjectID().Hex()
+}
+
+// Run runs the scheduler every interval until the context is cancelled
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Tick(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Log("msg", "failed to run reminder scheduler", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick plans the reminders which are due until the next run, and sends the due ones
+func (s *Scheduler) Tick(ctx context.Context) error {
+	now := s.now()
+
+	// The planned jobs are still sent if the planning fails
+	planErr := s.plan(ctx, now)
+	if err := s.dispatch(ctx, now); err != nil {
+		return err
+	}
+	return planErr
+}
+
+// plan creates the jobs of the reminders which are due in [now - grace, now + interval)
+func (s *Scheduler) plan(ctx context.Context, now time.Time) error {
+	from, to := now.Add(-s.grace), now.Add(s.interval)
+
+	// The reminders are due before the start of the events
+	until := to.Add(repository.MaxReminderMinutes * time.Minute)
+	events, err := s.store.ListRemindedEvents(ctx, from, until)
+	if err != nil {
+		return err
+	}
+
+	var jobs []repository.ReminderJob
+	for _, event := range events {
+		occurrences := []repository.Event{event}
+		if event.IsRecurring() {
+			overrides, err := s.store.ListOverrides(ctx, event.UserId, []primitive.ObjectID{event.Id})
+			if err != nil {
+				return err
+			}
+			if occurrences, err = event.Expand(overrides, from, until); err != nil {
+				s.logger.Log("msg", "failed to expand recurring event", "event", event.Id.Hex(), "err", err)
+				continue
+			}
+		}
+
+		for _, occurrence := range occurrences {
+			for _, reminder := range occurrence.Reminders {
+				due := occurrence.Start.Add(-time.Duration(reminder.Minutes) * time.Minute)
+				if due.Before(from) || !due.Before(to) {
+					continue
+				}
+				jobs = append(jobs, newJob(occurrence, reminder, due))
+			}
+		}
+	}
+
+	return s.store.CreateReminderJobs(ctx, jobs)
+}
+
+// newJob creates the pending job of the reminder
+func newJob(event repository.Event, reminder repository.Reminder, due time.Time) repository.ReminderJob {
+	job := repository.ReminderJob{
+		UserId:   event.UserId,
+		EventId:  event.Id,
+		Start:    event.Start,
+		Reminder: reminder,
+		DueAt:    due,
+		Status:   repository.ReminderPending,
+	}
+	// The unmodified occurrences have no id, they are reminded by their recurring event
+	if event.RecurringEventId != nil && event.Id.IsZero() {
+		job.EventId, job.RecurrenceId = *event.RecurringEventId, event.RecurrenceId
+	}
+	return job
+}
+
+// dispatch claims and sends the due jobs one by one
+func (s *Scheduler) dispatch(ctx context.Context, now time.Time) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		job, err := s.store.ClaimReminderJob(ctx, s.owner, now, s.lease)
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		s.send(ctx, job, now)
+		if err = s.store.FinishReminderJob(ctx, job); err != nil {
+			s.logger.Log("msg", "failed to finish reminder job", "job", job.Id.Hex(), "err", err)
+		}
+	}
+}
+
+// send sends the claimed job and sets its status
+func (s *Scheduler) send(ctx context.Context, job *repository.ReminderJob, now time.Time) {
+	due := job.Start.Add(-time.Duration(job.Reminder.Minutes) * time.Minute)
+	if now.Sub(due) > s.grace {
+		s.finish(job, repository.ReminderCancelled, errors.New("too late to send"))
+		return
+	}
+	if job.Attempts > s.maxAttempts {
+		s.finish(job, repository.ReminderFailed, errors.New("too many attempts"))
+		return
+	}
+
+	event, err := s.current(ctx, job)
+	if err != nil {
+		if errors.Is(err, errStale) {
+			s.finish(job, repository.ReminderCancelled, err)
+		} else {
+			s.retry(job, now, err)
+		}
+		return
+	}
+
+	notifier, ok := s.notifiers[job.Reminder.Method]
+	if !ok {
+		s.finish(job, repository.ReminderFailed, errors.Errorf("no notifier for %q", job.Reminder.Method))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.lease/2)
+	defer cancel()
+
+	err = notifier.Notify(ctx, Notification{UserId: job.UserId, Event: event, Reminder: job.Reminder})
+	if err != nil {
+		s.retry(job, now, err)
+		return
+	}
+	s.finish(job, repository.ReminderSent, nil)
+}
+
+// current gets the event or the occurrence of the job. It returns errStale if the event is
+// deleted or moved, or the reminder is removed.
+func (s *Scheduler) current(ctx context.Context, job *repository.ReminderJob) (repository.Event, error) {
+	event, err := s.store.GetEvent(ctx, job.EventId.Hex(), job.UserId)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return repository.Event{}, errStale
+		}
+		return repository.Event{}, err
+	}
+
+	current := *event
+	if job.RecurrenceId != nil {
+		if !event.IsRecurring() || !event.HasOccurrence(*job.RecurrenceId) {
+			return repository.Event{}, errStale
+		}
+
+		// The overridden instance has its own job
+		overrides, err := s.store.ListOverrides(ctx, job.UserId, []primitive.ObjectID{event.Id})
+		if err != nil {
+			return repository.Event{}, err
+		}
+		if repository.FindOverride(overrides, *job.RecurrenceId) != nil {
+			return repository.Event{}, errStale
+		}
+		current = event.Occurrence(*job.RecurrenceId)
+	}
+
+	if !current.Start.Equal(job.Start) || !current.HasReminder(job.Reminder) {
+		return repository.Event{}, errStale
+	}
+	return current, nil
+}
+
+// retry sends the job again after a backoff, or fails it after the last attempt
+func (s *Scheduler) retry(job *repository.ReminderJob, now time.Time, err error) {
+	s.logger.Log("msg", "failed to send reminder", "job", job.Id.Hex(), "attempt", job.Attempts, "err", err)
+	if job.Attempts >= s.maxAttempts {
+		s.finish(job, repository.ReminderFailed, err)
+		return
+	}
+
+	job.Status = repository.ReminderPending
+	job.DueAt = now.Add(time.Duration(job.Attempts) * s.interval)
+	job.Err = err.Error()
+}
+
+// finish sets the final status of the job
+func (s *Scheduler) finish(job *repository.ReminderJob, status string, err error) {
+	job.Status = status
+	job.Err = ""
+	if err != nil {
+		job.Err = err.Error()
+	}
+}
@@ -0,0 +1,91 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SMTPConfig holds the SMTP server which the reminder emails are sent through
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// smtpNotifier sends the notifications as emails to the users
+type smtpNotifier struct {
+	addr string
+	auth smtp.Auth
+	// from is the From header, sender is its address which the envelope uses
+	from   string
+	sender string
+	emails EmailResolver
+	// send is smtp.SendMail, it is replaced in the tests
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPNotifier creates a Notifier which sends emails through the SMTP server. The server
+// has to support STARTTLS to use the authentication.
+func NewSMTPNotifier(cfg SMTPConfig, emails EmailResolver) Notifier {
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	sender := cfg.From
+	if addr, err := mail.ParseAddress(cfg.From); err == nil {
+		sender = addr.Address
+	}
+	return &smtpNotifier{
+		addr:   net.JoinHostPort(cfg.Host, cfg.Port),
+		auth:   auth,
+		from:   cfg.From,
+		sender: sender,
+		emails: emails,
+		send:   smtp.SendMail,
+	}
+}
+
+func (s *smtpNotifier) Notify(ctx context.Context, n Notification) error {
+	to, err := s.emails.Email(ctx, n.UserId)
+	if err != nil {
+		return err
+	}
+
+	if err = s.send(s.addr, s.auth, s.sender, []string{to}, s.message(to, n)); err != nil {
+		return errors.Wrap(err, "failed to send email")
+	}
+	return nil
+}
+
+// message creates the email. The subject is encoded, so the event name can not inject headers.
+func (s *smtpNotifier) message(to string, n Notification) []byte {
+	start := n.Event.Start.In(n.Event.Location())
+	when := start.Format("Monday, January 2, 2006 15:04 MST")
+	if n.Event.AllDay {
+		when = start.Format("Monday, January 2, 2006")
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "Reminder: "+n.Event.Name))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	fmt.Fprintf(&msg, "%s starts on %s.\r\n", n.Event.Name, when)
+	if n.Event.Details != "" {
+		fmt.Fprintf(&msg, "\r\n%s\r\n", n.Event.Details)
+	}
+	return msg.Bytes()
+}
@@ -0,0 +1,80 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// webhookTimeout bounds a webhook request
+const webhookTimeout = 10 * time.Second
+
+// webhookPayload is the JSON body which is posted to the webhook
+type webhookPayload struct {
+	UserId       uint64     `json:"user_id"`
+	EventId      string     `json:"event_id"`
+	RecurrenceId *time.Time `json:"recurrence_id,omitempty"`
+	Name         string     `json:"name"`
+	Details      string     `json:"details,omitempty"`
+	Start        time.Time  `json:"start"`
+	End          time.Time  `json:"end"`
+	TimeZone     string     `json:"time_zone,omitempty"`
+	AllDay       bool       `json:"all_day,omitempty"`
+	Minutes      int        `json:"minutes"`
+}
+
+// webhookNotifier posts the notifications to a URL as JSON
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a Notifier which posts the notifications to the URL. The
+// responses other than 2xx are errors.
+func NewWebhookNotifier(url string) Notifier {
+	return &webhookNotifier{url: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, n Notification) error {
+	eventId := n.Event.Id
+	if n.Event.RecurringEventId != nil {
+		eventId = *n.Event.RecurringEventId
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		UserId:       n.UserId,
+		EventId:      eventId.Hex(),
+		RecurrenceId: n.Event.RecurrenceId,
+		Name:         n.Event.Name,
+		Details:      n.Event.Details,
+		Start:        n.Event.Start,
+		End:          n.Event.End,
+		TimeZone:     n.Event.TimeZone,
+		AllDay:       n.Event.AllDay,
+		Minutes:      n.Reminder.Minutes,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode webhook payload")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to post webhook")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
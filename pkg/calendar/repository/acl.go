@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/validator"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The roles which a calendar is shared with. The owners manage the calendar and its grants,
+// the editors change its events, the viewers see its events, and the free/busy role only
+// sees when the events are.
+const (
+	RoleOwner    = "owner"
+	RoleEditor   = "editor"
+	RoleViewer   = "viewer"
+	RoleFreeBusy = "freebusy"
+)
+
+// Roles holds the valid roles of a grant
+var Roles = []string{RoleOwner, RoleEditor, RoleViewer, RoleFreeBusy}
+
+// roleRanks orders the roles, a role has the access of the lower ones
+var roleRanks = map[string]int{RoleFreeBusy: 1, RoleViewer: 2, RoleEditor: 3, RoleOwner: 4}
+
+var ErrAccessDenied = errors.New("the calendar is not shared with the required access")
+
+// Grant shares the calendar with the user in the role
+type Grant struct {
+	CalendarId primitive.ObjectID `json:"calendar_id" bson:"calendar_id"`
+	UserId     uint64             `json:"user_id" bson:"user_id"`
+	Role       string             `json:"role" bson:"role"`
+}
+
+// ValidateGrant rules the grant validation
+func ValidateGrant(v *validator.Validator, grant Grant) {
+	v.Check(grant.UserId != 0, "user_id", "must be provided")
+	v.Check(validator.In(grant.Role, Roles...), "role", "must be owner, editor, viewer or freebusy")
+}
+
+// Allows reports whether the role has the access of the required role
+func Allows(role, required string) bool {
+	return roleRanks[role] >= roleRanks[required]
+}
+
+// Busy gives the free/busy information of the event, the other fields are hidden
+func (e Event) Busy() Event {
+	return Event{
+		Id:               e.Id,
+		UserId:           e.UserId,
+		CalendarId:       e.CalendarId,
+		Start:            e.Start,
+		End:              e.End,
+		TimeZone:         e.TimeZone,
+		AllDay:           e.AllDay,
+		Transparent:      e.Transparent,
+		Recurrence:       e.Recurrence,
+		RecurringEventId: e.RecurringEventId,
+		RecurrenceId:     e.RecurrenceId,
+	}
+}
+
+// FindCalendar -> Gets the calendar according to given calendarId whoever owns it
+func (c *calendarRepository) FindCalendar(ctx context.Context, calendarId string) (*Calendar, error) {
+	id, err := primitive.ObjectIDFromHex(calendarId)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var calendar Calendar
+	err = c.calendars.FindOne(ctx, bson.M{"_id": id}).Decode(&calendar)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get calendar from database")
+	}
+	return &calendar, nil
+}
+
+// FindEvent -> Gets the event according to given eventId whoever organizes it
+func (c *calendarRepository) FindEvent(ctx context.Context, eventId string) (*Event, error) {
+	id, err := primitive.ObjectIDFromHex(eventId)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var event Event
+	err = c.collection.FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get event from database")
+	}
+	return &event, nil
+}
+
+// SetGrant -> Adds the grant to the acl database, or changes the role of the user if the
+// calendar is already shared with the user
+func (c *calendarRepository) SetGrant(ctx context.Context, grant *Grant) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := c.acl.UpdateOne(ctx, bson.M{"calendar_id": grant.CalendarId, "user_id": grant.UserId},
+		bson.M{"$set": bson.M{"role": grant.Role}}, options.Update().SetUpsert(true))
+	if err != nil {
+		return errors.Wrap(err, "failed to set grant")
+	}
+	return nil
+}
+
+// GetGrant -> Gets the grant of the user on the calendar
+func (c *calendarRepository) GetGrant(ctx context.Context, calendarId primitive.ObjectID, userId uint64) (*Grant, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var grant Grant
+	err := c.acl.FindOne(ctx, bson.M{"calendar_id": calendarId, "user_id": userId}).Decode(&grant)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get grant from database")
+	}
+	return &grant, nil
+}
+
+// ListGrants -> Gets the grants of the calendar ordered by the user id
+func (c *calendarRepository) ListGrants(ctx context.Context, calendarId primitive.ObjectID) ([]Grant, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cursor, err := c.acl.Find(ctx, bson.M{"calendar_id": calendarId}, options.Find().SetSort(bson.M{"user_id": 1}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get grants from database")
+	}
+
+	var grants []Grant
+	if err = cursor.All(ctx, &grants); err != nil {
+		return nil, errors.Wrap(err, "failed to get grants from database")
+	}
+	return grants, nil
+}
+
+// DeleteGrant -> Deletes the grant of the user on the calendar
+func (c *calendarRepository) DeleteGrant(ctx context.Context, calendarId primitive.ObjectID, userId uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	res, err := c.acl.DeleteOne(ctx, bson.M{"calendar_id": calendarId, "user_id": userId})
+	if err != nil {
+		return errors.Wrap(err, "failed to delete grant")
+	}
+	if res.DeletedCount == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListSharedCalendars -> Gets the calendars of the other users which are shared with the given
+// userId, together with the role of the user on them
+func (c *calendarRepository) ListSharedCalendars(ctx context.Context, userId uint64) ([]Calendar, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cursor, err := c.acl.Find(ctx, bson.M{"user_id": userId})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get grants from database")
+	}
+	var grants []Grant
+	if err = cursor.All(ctx, &grants); err != nil {
+		return nil, errors.Wrap(err, "failed to get grants from database")
+	}
+	if len(grants) == 0 {
+		return nil, nil
+	}
+
+	roles := make(map[primitive.ObjectID]string, len(grants))
+	ids := make([]primitive.ObjectID, 0, len(grants))
+	for _, grant := range grants {
+		roles[grant.CalendarId] = grant.Role
+		ids = append(ids, grant.CalendarId)
+	}
+
+	cursor, err = c.calendars.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get calendars from database")
+	}
+	var calendars []Calendar
+	if err = cursor.All(ctx, &calendars); err != nil {
+		return nil, errors.Wrap(err, "failed to get calendars from database")
+	}
+	for i := range calendars {
+		calendars[i].Role = roles[calendars[i].Id]
+	}
+	return calendars, nil
+}
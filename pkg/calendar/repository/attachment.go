@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/validator"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The limits of the attachments of an event
+const (
+	MaxAttachments    = 10
+	MaxAttachmentSize = 10 << 20
+	// MaxMessageSize is the size of the gRPC messages which carry an attachment with its metadata
+	MaxMessageSize = MaxAttachmentSize + 1<<20
+)
+
+// AttachmentTypes are the content types which can be attached to the events, the documents and
+// the images which the browsers do not run
+var AttachmentTypes = map[string]bool{
+	"application/pdf":    true,
+	"text/plain":         true,
+	"text/csv":           true,
+	"text/calendar":      true,
+	"image/png":          true,
+	"image/jpeg":         true,
+	"image/gif":          true,
+	"image/webp":         true,
+	"application/msword": true,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
+	"application/vnd.ms-excel": true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         true,
+	"application/vnd.ms-powerpoint":                                             true,
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
+	"application/vnd.oasis.opendocument.text":                                   true,
+	"application/vnd.oasis.opendocument.spreadsheet":                            true,
+	"application/vnd.oasis.opendocument.presentation":                           true,
+}
+
+// Attachment is the metadata of a file which is attached to an event, the content of the file is
+// kept in the blob store with the id of the attachment
+type Attachment struct {
+	Id          primitive.ObjectID `json:"id" bson:"_id"`
+	Name        string             `json:"name" bson:"name"`
+	ContentType string             `json:"content_type" bson:"content_type"`
+	Size        int64              `json:"size" bson:"size"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// BlobStore keeps the contents of the attachments
+type BlobStore interface {
+	// Put stores the content with the id
+	Put(ctx context.Context, id string, content io.Reader) error
+	// Open gets the content with the id, ErrRecordNotFound if there is no content with the id
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+	// Delete removes the content with the id, it is not an error if there is no content with the id
+	Delete(ctx context.Context, id string) error
+}
+
+// ValidateAttachment rules the attachment validation
+func ValidateAttachment(v *validator.Validator, attachment Attachment) {
+	v.Check(attachment.Name != "", "name", "must be provided")
+	v.Check(len(attachment.Name) <= 255, "name", "must not be more than 255 bytes long")
+	v.Check(!strings.ContainsAny(attachment.Name, "/\\"), "name", "must not contain a path")
+
+	v.Check(AttachmentTypes[attachment.ContentType], "content_type", "must be a document or an image type")
+
+	v.Check(attachment.Size > 0, "size", "must not be empty")
+	v.Check(attachment.Size <= MaxAttachmentSize, "size", "must not be more than 10 MiB")
+}
+
+// FindAttachment returns the attachment of the event with the id, nil if there is no such attachment
+func (e Event) FindAttachment(attachmentId string) *Attachment {
+	for i := range e.Attachments {
+		if e.Attachments[i].Id.Hex() == attachmentId {
+			return &e.Attachments[i]
+		}
+	}
+	return nil
+}
+
+// AddAttachment -> Stores the content of the attachment and adds the attachment to the event
+// according to given userId and eventId, and returns the updated event. Only the recurring events
+// and the single events have attachments, the overridden instances have the attachments of their
+// recurring events.
+func (c *calendarRepository) AddAttachment(ctx context.Context, eventId string, userId uint64,
+	attachment *Attachment, content io.Reader) (*Event, error) {
+	id, err := primitive.ObjectIDFromHex(eventId)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+
+	attachment.Id = primitive.NewObjectID()
+	attachment.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err = c.blobs.Put(ctx, attachment.Id.Hex(), content); err != nil {
+		return nil, errors.Wrap(err, "failed to store attachment")
+	}
+
+	updateCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var event Event
+	err = c.collection.FindOneAndUpdate(updateCtx,
+		bson.M{"user_id": userId, "_id": id, "deleted_at": nil, "recurring_event_id": nil},
+		bson.M{"$push": bson.M{"attachments": attachment}, "$inc": incVersion},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&event)
+	if err != nil {
+		// The content is not referenced by any event, the error of the update is the one to return
+		_ = c.blobs.Delete(ctx, attachment.Id.Hex())
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to add attachment")
+	}
+	return &event, nil
+}
+
+// OpenAttachment -> Gets the content of the attachment according to attachmentId
+func (c *calendarRepository) OpenAttachment(ctx context.Context, attachmentId string) (io.ReadCloser, error) {
+	return c.blobs.Open(ctx, attachmentId)
+}
+
+// DeleteAttachment -> Removes the attachment from the event according to given userId and
+// eventId, deletes its content and returns the updated event
+func (c *calendarRepository) DeleteAttachment(ctx context.Context, eventId string, userId uint64,
+	attachmentId string) (*Event, error) {
+	id, err := primitive.ObjectIDFromHex(eventId)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+	attachment, err := primitive.ObjectIDFromHex(attachmentId)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var event Event
+	err = c.collection.FindOneAndUpdate(ctx,
+		bson.M{"user_id": userId, "_id": id, "deleted_at": nil, "attachments._id": attachment},
+		bson.M{"$pull": bson.M{"attachments": bson.M{"_id": attachment}}, "$inc": incVersion},
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to delete attachment")
+	}
+
+	if err = c.deleteBlobs(ctx, []Attachment{{Id: attachment}}); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// deleteBlobs deletes the contents of the attachments, all of them are tried before the error of
+// the last failed one is returned
+func (c *calendarRepository) deleteBlobs(ctx context.Context, attachments []Attachment) error {
+	var err error
+	for _, attachment := range attachments {
+		if derr := c.blobs.Delete(ctx, attachment.Id.Hex()); derr != nil {
+			err = errors.Wrapf(derr, "failed to delete content of attachment %s", attachment.Id.Hex())
+		}
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/config"
+	"github.com/3n0ugh/kalenderium/pkg/calendar/blob"
+	"github.com/3n0ugh/kalenderium/pkg/calendar/database"
+	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+)
+
+// testRepository connects to the MongoDB of KALENDERIUM_TEST_DB_URI with the contents of the
+// attachments in the directory, the test is skipped without it
+func testRepository(t *testing.T, dir string) repository.CalendarRepository {
+	uri := os.Getenv("KALENDERIUM_TEST_DB_URI")
+	if uri == "" {
+		t.Skip("KALENDERIUM_TEST_DB_URI is not set")
+	}
+
+	conn, err := database.NewConnection(config.CalendarServiceConfigurations{URI: uri})
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := blob.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := repository.NewCalendarRepository(conn, blobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestCalendarRepository_DeleteCalendar_Attachments(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo := testRepository(t, dir)
+
+	// A user of its own, so the other data in the database is not touched
+	userId := uint64(time.Now().UnixNano())
+
+	calendar := &repository.Calendar{UserId: userId, Name: "Trip", Color: "#00FF00",
+		Visibility: repository.VisibilityPrivate}
+	if err := repo.CreateCalendar(ctx, calendar); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2022, time.June, 1, 9, 0, 0, 0, time.UTC)
+	event := &repository.Event{UserId: userId, CalendarId: calendar.Id, Name: "Flight", Start: start,
+		End: start.Add(3 * time.Hour), Color: "#00FF00"}
+	if err := repo.CreateEvent(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+	attachment := &repository.Attachment{Name: "ticket.pdf", ContentType: "application/pdf", Size: 5}
+	if _, err := repo.AddAttachment(ctx, event.Id.Hex(), userId, attachment, strings.NewReader("%PDF-")); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := repo.DeleteCalendar(ctx, calendar.Id, userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0].Id != event.Id || len(deleted[0].Attachments) != 1 {
+		t.Fatalf("expected the event with its attachment, got %v", deleted)
+	}
+
+	// The event is in the trash, its attachment is kept to be restored
+	trashed, _, err := repo.ListTrash(ctx, userId, repository.Page{Size: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trashed) != 1 || trashed[0].Id != event.Id {
+		t.Fatalf("expected the event in the trash, got %v", trashed)
+	}
+	content, err := repo.OpenAttachment(ctx, attachment.Id.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	content.Close()
+
+	if err = repo.PurgeEvent(ctx, event.Id.Hex(), userId); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expected an empty attachment store, got %d files", len(entries))
+	}
+}
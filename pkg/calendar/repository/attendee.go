@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/validator"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MaxAttendees limits the attendees of an event
+const MaxAttendees = 100
+
+var ErrNotOrganizer = errors.New("only the organizer can change the event")
+
+// The participation statuses of an attendee
+const (
+	NeedsAction = "needs-action"
+	Accepted    = "accepted"
+	Declined    = "declined"
+	Tentative   = "tentative"
+)
+
+// Responses holds the statuses which the attendees can respond with
+var Responses = []string{Accepted, Declined, Tentative}
+
+// Attendee is a kalenderium user or an external email address which is invited to the event.
+// The organizer of the event is its UserId, and is not an attendee.
+type Attendee struct {
+	UserId uint64 `json:"user_id,omitempty" bson:"user_id,omitempty"`
+	Email  string `json:"email,omitempty" bson:"email,omitempty"`
+	Status string `json:"status" bson:"status"`
+}
+
+// Key identifies the attendee, the emails are case-insensitive
+func (a Attendee) Key() string {
+	if a.UserId != 0 {
+		return "user:" + strconv.FormatUint(a.UserId, 10)
+	}
+	return "email:" + strings.ToLower(a.Email)
+}
+
+// ValidateAttendees rules the attendees validation
+func ValidateAttendees(v *validator.Validator, organizerId uint64, attendees []Attendee) {
+	v.Check(len(attendees) <= MaxAttendees, "attendees", "must not have more than 100 attendees")
+
+	seen := make(map[string]bool, len(attendees))
+	for _, attendee := range attendees {
+		v.Check((attendee.UserId != 0) != (attendee.Email != ""), "attendees", "must have either a user id or an email")
+		if attendee.Email != "" {
+			v.Check(validator.Matches(attendee.Email, validator.EmailRX), "attendees", "must have valid emails")
+		}
+		v.Check(attendee.UserId == 0 || attendee.UserId != organizerId, "attendees", "must not include the organizer")
+		v.Check(attendee.Status == "" || attendee.Status == NeedsAction || validator.In(attendee.Status, Responses...),
+			"attendees", "must have a valid status")
+		v.Check(!seen[attendee.Key()], "attendees", "must not have duplicates")
+		seen[attendee.Key()] = true
+	}
+}
+
+// IsAttendee returns true if the user is invited to the event
+func (e *Event) IsAttendee(userId uint64) bool {
+	for _, attendee := range e.Attendees {
+		if attendee.UserId != 0 && attendee.UserId == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// GetInvitedEvent -> Gets the event which the user is invited to according to given eventId
+func (c *calendarRepository) GetInvitedEvent(ctx context.Context, eventId string, userId uint64) (*Event, error) {
+	id, err := primitive.ObjectIDFromHex(eventId)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var event Event
+	err = c.collection.FindOne(ctx, bson.M{"_id": id, "attendees.user_id": userId, "deleted_at": nil}).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get event from database")
+	}
+	return &event, nil
+}
+
+// SetAttendees -> Replaces the attendees of the event which belongs to the given userId, and
+// of its overridden instances if it is a recurring event
+func (c *calendarRepository) SetAttendees(ctx context.Context, userId uint64, eventId primitive.ObjectID,
+	attendees []Attendee) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	res, err := c.collection.UpdateMany(ctx,
+		bson.M{"user_id": userId, "deleted_at": nil,
+			"$or": bson.A{bson.M{"_id": eventId}, bson.M{"recurring_event_id": eventId}}},
+		bson.M{"$set": bson.M{"attendees": attendees}, "$inc": incVersion})
+	if err != nil {
+		return errors.Wrap(err, "failed to set attendees")
+	}
+	if res.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetAttendeeStatus -> Sets the participation status of the user on the event, and on its
+// overridden instances if it is a recurring event
+func (c *calendarRepository) SetAttendeeStatus(ctx context.Context, eventId primitive.ObjectID, userId uint64,
+	status string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	res, err := c.collection.UpdateMany(ctx,
+		bson.M{"attendees.user_id": userId, "deleted_at": nil,
+			"$or": bson.A{bson.M{"_id": eventId}, bson.M{"recurring_event_id": eventId}}},
+		bson.M{"$set": bson.M{"attendees.$.status": status}, "$inc": incVersion})
+	if err != nil {
+		return errors.Wrap(err, "failed to set attendee status")
+	}
+	if res.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/validator"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MaxBatchSize limits the events which are created or deleted at once
+const MaxBatchSize = 1000
+
+// BatchResult reports the outcome of an item of a batch, the event id is set if it succeeds and
+// the err otherwise
+type BatchResult struct {
+	EventId string `json:"event_id,omitempty"`
+	Err     string `json:"err,omitempty"`
+}
+
+// ValidateBatchSize rules the batch size validation
+func ValidateBatchSize(v *validator.Validator, key string, size int) {
+	v.Check(size > 0, key, "must be provided")
+	v.Check(size <= MaxBatchSize, key, fmt.Sprintf("must not have more than %d items", MaxBatchSize))
+}
+
+// CreateEvents -> Adds the events to the events database at once and sets their ids. The events
+// are inserted independently, so a failed event does not stop the others. The errors of the
+// failed events are returned by their indexes.
+func (c *calendarRepository) CreateEvents(ctx context.Context, events []*Event) (map[int]error, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// The ids are set before the insert, so they are known for the inserted events on failure
+	documents := make([]interface{}, len(events))
+	for i, event := range events {
+		event.Id, event.Version = primitive.NewObjectID(), 1
+		documents[i] = event
+	}
+
+	_, err := c.collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		var bulkErr mongo.BulkWriteException
+		if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+			return nil, errors.Wrap(err, "failed to insert events")
+		}
+
+		failed := make(map[int]error, len(bulkErr.WriteErrors))
+		for _, writeErr := range bulkErr.WriteErrors {
+			failed[writeErr.Index] = errors.Wrap(writeErr, "failed to insert event")
+		}
+		return failed, nil
+	}
+	return nil, nil
+}
+
+// DeleteEvents -> Moves the events according to given userId and eventIds to the trash at once,
+// together with the overridden instances of the recurring events, and returns the events as they
+// were before. The events which are not found are not returned.
+func (c *calendarRepository) DeleteEvents(ctx context.Context, userId uint64, eventIds []primitive.ObjectID) ([]Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cursor, err := c.collection.Find(ctx, bson.M{"user_id": userId, "_id": bson.M{"$in": eventIds}, "deleted_at": nil})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get events from database")
+	}
+
+	var events []Event
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, errors.Wrap(err, "failed to get events from database")
+	}
+	if len(events) == 0 {
+		return events, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(events))
+	for i, event := range events {
+		ids[i] = event.Id
+	}
+
+	// The database keeps the times in milliseconds, the overrides are matched by the same time
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	_, err = c.collection.UpdateMany(ctx,
+		bson.M{"user_id": userId, "deleted_at": nil,
+			"$or": bson.A{bson.M{"_id": bson.M{"$in": ids}}, bson.M{"recurring_event_id": bson.M{"$in": ids}}}},
+		bson.M{"$set": bson.M{"deleted_at": now}, "$inc": incVersion})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete events")
+	}
+	return events, nil
+}
@@ -1,48 +1,359 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"github.com/3n0ugh/kalenderium/internal/validator"
 	db "github.com/3n0ugh/kalenderium/pkg/calendar/database"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"io"
 	"strings"
 	"time"
 )
 
-var ErrRecordNotFound = errors.New("record not found")
+var (
+	ErrRecordNotFound   = errors.New("record not found")
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
 
 type Event struct {
-	Id      primitive.ObjectID `json:"id"`
-	UserId  uint64             `json:"user_id"`
-	Name    string             `json:"name"`
-	Details string             `json:"details,omitempty"`
-	Start   time.Time          `json:"start"`
-	End     time.Time          `json:"end"`
-	Color   string             `json:"color"`
+	Id      primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserId  uint64             `json:"user_id" bson:"user_id"`
+	Name    string             `json:"name" bson:"name"`
+	Details string             `json:"details,omitempty" bson:"details"`
+	Start   time.Time          `json:"start" bson:"start"`
+	End     time.Time          `json:"end" bson:"end"`
+	Color   string             `json:"color" bson:"color"`
+	// CalendarId is the calendar of the organizer which the event is in
+	CalendarId primitive.ObjectID `json:"calendar_id" bson:"calendar_id,omitempty"`
+
+	// TimeZone is the IANA time zone of the event, the occurrences of a recurring event keep
+	// their wall-clock time in it across the DST changes. UTC if empty.
+	TimeZone string `json:"time_zone,omitempty" bson:"time_zone,omitempty"`
+	// AllDay events have date-only start and end at midnight UTC, and the end is exclusive
+	AllDay bool `json:"all_day,omitempty" bson:"all_day,omitempty"`
+	// Transparent events are shown as free, they are not counted in the free/busy time
+	Transparent bool `json:"transparent,omitempty" bson:"transparent,omitempty"`
+	// Tags categorize the events of the user, they are normalized to lower case
+	Tags []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	// Reminders notify the user before the start of the event or its occurrences
+	Reminders []Reminder `json:"reminders,omitempty" bson:"reminders,omitempty"`
+	// Attendees are invited by the organizer, the event is listed for the invited users too
+	Attendees []Attendee `json:"attendees,omitempty" bson:"attendees,omitempty"`
+	// Attachments are the files which the organizer attaches to the event, they are not copied
+	// to the overridden instances
+	Attachments []Attachment `json:"attachments,omitempty" bson:"attachments,omitempty"`
+
+	// Recurrence is set on the master of a recurring event
+	Recurrence *Recurrence `json:"recurrence,omitempty" bson:"recurrence,omitempty"`
+	// RecurringEventId and RecurrenceId are set on the occurrences of a recurring event,
+	// the recurrence id is the original start of the occurrence
+	RecurringEventId *primitive.ObjectID `json:"recurring_event_id,omitempty" bson:"recurring_event_id,omitempty"`
+	RecurrenceId     *time.Time          `json:"recurrence_id,omitempty" bson:"recurrence_id,omitempty"`
+
+	// UID of the imported events, the re-imports update the events with the same UID
+	UID string `json:"uid,omitempty" bson:"uid,omitempty"`
+	// Resource is the name of the CalDAV resource of the imported event, it is set only if the
+	// client does not name the resource after the UID
+	Resource string `json:"-" bson:"resource,omitempty"`
+
+	// DeletedAt is set on the events in the trash, they are purged after the retention period
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+
+	// Version is increased on every change of the event, it starts with 1. The writes which
+	// expect a version fail if the event has been changed since then.
+	Version int64 `json:"version" bson:"version"`
+}
+
+// MarshalJSON leaves the id of the unmodified occurrences of the recurring events empty, they are
+// identified by the recurring event id and the recurrence id
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	if !e.Id.IsZero() {
+		return json.Marshal(event(e))
+	}
+	return json.Marshal(struct {
+		Id string `json:"id"`
+		event
+	}{event: event(e)})
+}
+
+// EventFilter narrows down the listed events. Zero values mean no bound.
+type EventFilter struct {
+	From time.Time `json:"from,omitempty"`
+	To   time.Time `json:"to,omitempty"`
+	// CalendarIds selects the events in the calendars, all the calendars if empty
+	CalendarIds []primitive.ObjectID `json:"calendar_ids,omitempty"`
+	// SharedCalendarIds are the calendars of the other users which are shared with the user,
+	// their events are listed too. The service sets them after checking the access of the user.
+	SharedCalendarIds []primitive.ObjectID `json:"-"`
+	// Tags selects the events which have all the tags, all the events if empty
+	Tags []string `json:"tags,omitempty"`
+	// NoExpand keeps the recurring events unexpanded in a window with an end, the exports and
+	// the CalDAV resources have the recurring events with their overridden instances
+	NoExpand bool `json:"-"`
+}
+
+// Page selects the part of the listed events. Events are ordered by start and id,
+// and the token points to the last event of the previous page.
+type Page struct {
+	Size  int    `json:"page_size,omitempty"`
+	Token string `json:"page_token,omitempty"`
+}
+
+// pageCursor is the content of the opaque page token. The occurrences of the expanded recurring
+// events have the id of their recurring event and their recurrence id.
+type pageCursor struct {
+	Start        time.Time          `json:"s"`
+	Id           primitive.ObjectID `json:"i"`
+	RecurrenceId *time.Time         `json:"r,omitempty"`
+}
+
+// newPageCursor creates the cursor which points to the given event
+func newPageCursor(event Event) pageCursor {
+	if event.RecurringEventId != nil {
+		return pageCursor{Start: event.Start, Id: *event.RecurringEventId, RecurrenceId: event.RecurrenceId}
+	}
+	return pageCursor{Start: event.Start, Id: event.Id}
+}
+
+// before returns true if the cursor is ordered before the other one
+func (p pageCursor) before(other pageCursor) bool {
+	if !p.Start.Equal(other.Start) {
+		return p.Start.Before(other.Start)
+	}
+	if p.Id != other.Id {
+		return bytes.Compare(p.Id[:], other.Id[:]) < 0
+	}
+	if p.RecurrenceId == nil || other.RecurrenceId == nil {
+		return p.RecurrenceId == nil && other.RecurrenceId != nil
+	}
+	return p.RecurrenceId.Before(*other.RecurrenceId)
+}
+
+// encodePageToken creates the page token which points to the given event
+func encodePageToken(event Event) string {
+	js, _ := json.Marshal(newPageCursor(event))
+	return base64.RawURLEncoding.EncodeToString(js)
+}
+
+// decodePageToken gets the cursor from the page token
+func decodePageToken(token string) (pageCursor, error) {
+	var cursor pageCursor
+
+	js, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, ErrInvalidPageToken
+	}
+	if err = json.Unmarshal(js, &cursor); err != nil || cursor.Id.IsZero() {
+		return cursor, ErrInvalidPageToken
+	}
+	return cursor, nil
+}
+
+// UpdatableFields holds the event fields that can be used in an update mask
+var UpdatableFields = []string{"name", "details", "start", "end", "color", "calendar_id", "time_zone", "all_day",
+	"transparent", "tags", "reminders", "recurrence"}
+
+// MergeEvent copies the given fields of the src event to the dst event
+func MergeEvent(dst *Event, src Event, fields []string) {
+	for _, field := range fields {
+		switch field {
+		case "name":
+			dst.Name = src.Name
+		case "details":
+			dst.Details = src.Details
+		case "start":
+			dst.Start = src.Start
+		case "end":
+			dst.End = src.End
+		case "color":
+			dst.Color = src.Color
+		case "calendar_id":
+			dst.CalendarId = src.CalendarId
+		case "time_zone":
+			dst.TimeZone = src.TimeZone
+		case "all_day":
+			dst.AllDay = src.AllDay
+		case "transparent":
+			dst.Transparent = src.Transparent
+		case "tags":
+			dst.Tags = src.Tags
+		case "reminders":
+			dst.Reminders = src.Reminders
+		case "recurrence":
+			dst.Recurrence = src.Recurrence
+		}
+	}
 }
 
 type CalendarRepository interface {
 	CreateEvent(ctx context.Context, event *Event) error
-	ListEvent(ctx context.Context, userId uint64) ([]Event, error)
-	DeleteEvent(ctx context.Context, eventId string, userId uint64) error
+	CreateEvents(ctx context.Context, events []*Event) (map[int]error, error)
+	ListEvent(ctx context.Context, userId uint64, filter EventFilter, page Page) ([]Event, string, error)
+	ListTags(ctx context.Context, userId uint64) ([]TagCount, error)
+	SearchEvents(ctx context.Context, userId uint64, query string, filter EventFilter, page Page) ([]Event, string, error)
+	GetEvent(ctx context.Context, eventId string, userId uint64) (*Event, error)
+	UpdateEvent(ctx context.Context, event *Event, fields []string) error
+	DeleteEvent(ctx context.Context, eventId string, userId uint64, version int64) (*Event, error)
+	DeleteEvents(ctx context.Context, userId uint64, eventIds []primitive.ObjectID) ([]Event, error)
+	ListTrash(ctx context.Context, userId uint64, page Page) ([]Event, string, error)
+	RestoreEvent(ctx context.Context, eventId string, userId uint64) (*Event, error)
+	PurgeEvent(ctx context.Context, eventId string, userId uint64) error
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+	AddHistory(ctx context.Context, entry *HistoryEntry) error
+	AddHistories(ctx context.Context, entries []*HistoryEntry) error
+	ListHistory(ctx context.Context, eventId string, page Page) ([]HistoryEntry, string, error)
+	WatchChanges(ctx context.Context, userId uint64, resumeToken string, send func(HistoryEntry, string) error) error
+	GetHistoryEntry(ctx context.Context, eventId, entryId string) (*HistoryEntry, error)
+	GetEventByUID(ctx context.Context, userId uint64, uid string) (*Event, error)
+	GetEventByResource(ctx context.Context, userId uint64, name string) (*Event, error)
+	ListOverrides(ctx context.Context, userId uint64, eventIds []primitive.ObjectID) ([]Event, error)
+	MoveOverrides(ctx context.Context, userId uint64, fromId, toId primitive.ObjectID, since time.Time) error
+	DeleteOverrides(ctx context.Context, userId uint64, eventId primitive.ObjectID, since time.Time) error
+	GetInvitedEvent(ctx context.Context, eventId string, userId uint64) (*Event, error)
+	SetAttendees(ctx context.Context, userId uint64, eventId primitive.ObjectID, attendees []Attendee) error
+	SetAttendeeStatus(ctx context.Context, eventId primitive.ObjectID, userId uint64, status string) error
+	ListRemindedEvents(ctx context.Context, from, to time.Time) ([]Event, error)
+	CreateReminderJobs(ctx context.Context, jobs []ReminderJob) error
+	ClaimReminderJob(ctx context.Context, owner string, now time.Time, lease time.Duration) (*ReminderJob, error)
+	FinishReminderJob(ctx context.Context, job *ReminderJob) error
+	CreateCalendar(ctx context.Context, calendar *Calendar) error
+	ListCalendars(ctx context.Context, userId uint64) ([]Calendar, error)
+	GetCalendar(ctx context.Context, calendarId string, userId uint64) (*Calendar, error)
+	DefaultCalendar(ctx context.Context, userId uint64) (*Calendar, error)
+	UpdateCalendar(ctx context.Context, calendar *Calendar, fields []string) error
+	DeleteCalendar(ctx context.Context, calendarId primitive.ObjectID, userId uint64) ([]Event, error)
+	SetOverridesCalendar(ctx context.Context, userId uint64, eventId, calendarId primitive.ObjectID) error
+	FindCalendar(ctx context.Context, calendarId string) (*Calendar, error)
+	FindEvent(ctx context.Context, eventId string) (*Event, error)
+	SetGrant(ctx context.Context, grant *Grant) error
+	GetGrant(ctx context.Context, calendarId primitive.ObjectID, userId uint64) (*Grant, error)
+	ListGrants(ctx context.Context, calendarId primitive.ObjectID) ([]Grant, error)
+	DeleteGrant(ctx context.Context, calendarId primitive.ObjectID, userId uint64) error
+	ListSharedCalendars(ctx context.Context, userId uint64) ([]Calendar, error)
+	AddAttachment(ctx context.Context, eventId string, userId uint64, attachment *Attachment,
+		content io.Reader) (*Event, error)
+	OpenAttachment(ctx context.Context, attachmentId string) (io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, eventId string, userId uint64, attachmentId string) (*Event, error)
 	ServiceStatus(ctx context.Context) error
 }
 
 type calendarRepository struct {
 	collection *mongo.Collection
+	reminders  *mongo.Collection
+	calendars  *mongo.Collection
+	acl        *mongo.Collection
+	history    *mongo.Collection
 	db         *mongo.Client
+	blobs      BlobStore
 }
 
-func NewCalendarRepository(conn db.Connection) CalendarRepository {
-	coll := conn.DB().Database("kalenderium").Collection("calendar")
-	return &calendarRepository{
-		collection: coll,
+func NewCalendarRepository(conn db.Connection, blobs BlobStore) (CalendarRepository, error) {
+	database := conn.DB().Database("kalenderium")
+	c := &calendarRepository{
+		collection: database.Collection("calendar"),
+		reminders:  database.Collection("reminders"),
+		calendars:  database.Collection("calendars"),
+		acl:        database.Collection("acl"),
+		history:    database.Collection("history"),
 		db:         conn.DB(),
+		blobs:      blobs,
+	}
+
+	if err := c.createIndexes(context.Background()); err != nil {
+		return nil, err
+	}
+	if err := c.migrateCalendars(context.Background()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// createIndexes creates the indexes which the queries rely on, if they are not exist
+func (c *calendarRepository) createIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := c.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "start", Value: 1}, {Key: "end", Value: 1}}},
+		{Keys: bson.D{{Key: "recurring_event_id", Value: 1}, {Key: "recurrence_id", Value: 1}}},
+		{Keys: bson.D{{Key: "attendees.user_id", Value: 1}, {Key: "start", Value: 1}}},
+		{Keys: bson.D{{Key: "calendar_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "tags", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "uid", Value: 1}, {Key: "recurrence_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"uid": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "resource", Value: 1}},
+			Options: options.Index().SetPartialFilterExpression(bson.M{"resource": bson.M{"$exists": true}}),
+		},
+		textIndex,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create indexes")
+	}
+
+	if _, err = c.collection.Indexes().CreateMany(ctx, trashIndexes); err != nil {
+		return errors.Wrap(err, "failed to create trash indexes")
+	}
+
+	_, err = c.reminders.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "recurrence_id", Value: 1}, {Key: "start", Value: 1},
+				{Key: "reminder", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "due_at", Value: 1}}},
+		{Keys: bson.D{{Key: "finished_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(finishedJobTTL)},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create reminder indexes")
+	}
+
+	_, err = c.calendars.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "default", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"default": true}),
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create calendar indexes")
+	}
+
+	_, err = c.acl.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "calendar_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to create acl indexes")
+	}
+
+	if _, err = c.history.Indexes().CreateMany(ctx, historyIndexes); err != nil {
+		return errors.Wrap(err, "failed to create history indexes")
+	}
+	return nil
+}
+
+// ValidateEventFilter rules the event filter validation
+func ValidateEventFilter(v *validator.Validator, filter EventFilter) {
+	if !filter.From.IsZero() && !filter.To.IsZero() {
+		v.Check(filter.From.Before(filter.To), "to", "must be after from")
 	}
+	v.Check(len(filter.Tags) <= MaxTags, "tags", "must not have more than 20 tags")
 }
 
 func ValidateEvent(v *validator.Validator, event Event) {
@@ -57,6 +368,40 @@ func ValidateEvent(v *validator.Validator, event Event) {
 
 	v.Check(!event.Start.IsZero(), "start", "must be provided")
 	v.Check(!event.End.IsZero(), "end", "must be provided")
+	v.Check(!event.End.Before(event.Start), "end", "must not be before start")
+
+	if event.TimeZone != "" {
+		_, err := time.LoadLocation(event.TimeZone)
+		v.Check(err == nil && event.TimeZone != "Local", "time_zone", "must be a valid IANA time zone")
+	}
+
+	if event.AllDay {
+		v.Check(isDate(event.Start), "start", "must be a date at midnight UTC for all-day events")
+		v.Check(isDate(event.End), "end", "must be a date at midnight UTC for all-day events")
+		v.Check(event.End.After(event.Start), "end", "must be after start for all-day events")
+	}
+
+	ValidateTags(v, event.Tags)
+	ValidateReminders(v, event.Reminders)
+	ValidateAttendees(v, event.UserId, event.Attendees)
+	ValidateRecurrence(v, event.Recurrence)
+}
+
+// isDate returns true if the time is at midnight UTC
+func isDate(t time.Time) bool {
+	return t.Equal(t.UTC().Truncate(24 * time.Hour))
+}
+
+// Location returns the time zone of the event. The all-day events are in UTC.
+func (e *Event) Location() *time.Location {
+	if e.AllDay || e.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(e.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
 }
 
 // CreateEvent -> Adds event to the events database with given userId
@@ -64,16 +409,8 @@ func (c *calendarRepository) CreateEvent(ctx context.Context, event *Event) erro
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	e := bson.D{
-		{"user_id", event.UserId},
-		{"name", event.Name},
-		{"details", event.Details},
-		{"start", event.Start},
-		{"end", event.End},
-		{"color", event.Color},
-	}
-
-	i, err := c.collection.InsertOne(ctx, e)
+	event.Version = 1
+	i, err := c.collection.InsertOne(ctx, event)
 	if err != nil {
 		return errors.Wrap(err, "failed to insert event")
 	}
@@ -82,47 +419,254 @@ func (c *calendarRepository) CreateEvent(ctx context.Context, event *Event) erro
 	return nil
 }
 
-// ListEvent -> Gets events from database according to given userId
-func (c *calendarRepository) ListEvent(ctx context.Context, userId uint64) ([]Event, error) {
+// ListEvent -> Gets a page of events from database which the given userId organizes or is
+// invited to, or which are in the shared calendars of the filter, and returns the next page
+// token. If the filter has a time window, only the events overlapping the window are returned,
+// and if it has calendars, only the events in them, and if it has tags, only the events having
+// all of them. The next page token is empty on the last page.
+func (c *calendarRepository) ListEvent(ctx context.Context, userId uint64, filter EventFilter, page Page) ([]Event, string, error) {
 	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
 	defer cancel()
 
-	cursor, err := c.collection.Find(ctx, bson.D{{"user_id", userId}})
+	// The overridden instances are listed with their recurring events
+	owned := userCondition(userId)
+	if len(filter.SharedCalendarIds) > 0 {
+		owned = bson.M{"$or": bson.A{owned, bson.M{"calendar_id": bson.M{"$in": filter.SharedCalendarIds}}}}
+	}
+	conditions := bson.A{bson.M{"recurring_event_id": nil, "deleted_at": nil}, owned, windowCondition(filter),
+		tagCondition(filter)}
+	if len(filter.CalendarIds) > 0 {
+		conditions = append(conditions, bson.M{"calendar_id": bson.M{"$in": filter.CalendarIds}})
+	}
+	if page.Token != "" {
+		cursor, err := decodePageToken(page.Token)
+		if err != nil {
+			return nil, "", err
+		}
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"start": bson.M{"$gt": cursor.Start}},
+			bson.M{"start": cursor.Start, "_id": bson.M{"$gt": cursor.Id}},
+		}})
+	}
+
+	// One more event is fetched to know whether there is a next page
+	opts := options.Find().
+		SetSort(bson.D{{Key: "start", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(page.Size) + 1)
+
+	cursor, err := c.collection.Find(ctx, bson.M{"$and": conditions}, opts)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to get events from database")
+		return nil, "", errors.Wrap(err, "failed to get events from database")
 	}
 	if cursor.Err() != nil {
-		return nil, errors.Wrap(cursor.Err(), "failed to get events from database")
+		return nil, "", errors.Wrap(cursor.Err(), "failed to get events from database")
 	}
 
 	var events []Event
-	var results []bson.M
-	if err = cursor.All(ctx, &results); err != nil {
-		return nil, errors.Wrap(err, "failed to get events from database")
-	}
-	for _, result := range results {
-		event := Event{
-			Id:      result["_id"].(primitive.ObjectID),
-			UserId:  uint64(result["user_id"].(int64)),
-			Name:    result["name"].(string),
-			Details: result["details"].(string),
-			Start:   result["start"].(primitive.DateTime).Time(),
-			End:     result["end"].(primitive.DateTime).Time(),
-			Color:   result["color"].(string),
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, "", errors.Wrap(err, "failed to get events from database")
+	}
+
+	var nextPageToken string
+	if len(events) > page.Size {
+		events = events[:page.Size]
+		nextPageToken = encodePageToken(events[len(events)-1])
+	}
+
+	return events, nextPageToken, nil
+}
+
+// userCondition matches the events which the user organizes or is invited to
+func userCondition(userId uint64) bson.M {
+	return bson.M{"$or": bson.A{bson.M{"user_id": userId}, bson.M{"attendees.user_id": userId}}}
+}
+
+// windowCondition matches the events which overlap the window. Single events have to overlap
+// the window, recurring events have to have occurrences in the window. Events without
+// duration are matched by the start.
+func windowCondition(filter EventFilter) bson.M {
+	single := bson.A{bson.M{"recurrence.rrule": bson.M{"$exists": false}}}
+	recurring := bson.A{bson.M{"recurrence.rrule": bson.M{"$exists": true}}}
+	if !filter.From.IsZero() {
+		single = append(single, bson.M{"$or": bson.A{
+			bson.M{"end": bson.M{"$gt": filter.From}},
+			bson.M{"start": bson.M{"$gte": filter.From}},
+		}})
+		recurring = append(recurring, bson.M{"$or": bson.A{
+			bson.M{"recurrence.last": nil},
+			bson.M{"recurrence.last": bson.M{"$gte": filter.From}},
+		}})
+	}
+	if !filter.To.IsZero() {
+		single = append(single, bson.M{"start": bson.M{"$lt": filter.To}})
+		recurring = append(recurring, bson.M{"start": bson.M{"$lt": filter.To}})
+	}
+	return bson.M{"$or": bson.A{bson.M{"$and": single}, bson.M{"$and": recurring}}}
+}
+
+// GetEvent -> Gets the event according to given userId and eventId
+func (c *calendarRepository) GetEvent(ctx context.Context, eventId string, userId uint64) (*Event, error) {
+	id, err := primitive.ObjectIDFromHex(eventId)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var event Event
+	err = c.collection.FindOne(ctx, bson.M{"user_id": userId, "_id": id, "deleted_at": nil}).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get event from database")
+	}
+
+	return &event, nil
+}
+
+// UpdateEvent -> Sets the given fields of the event which belongs to the event's userId,
+// and fills the event with the updated document. If the event has a version, it is updated
+// only if it is still at that version.
+func (c *calendarRepository) UpdateEvent(ctx context.Context, event *Event, fields []string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	values := bson.M{
+		"name":    event.Name,
+		"details": event.Details,
+		"start":   event.Start,
+		"end":     event.End,
+		"color":   event.Color,
+
+		"calendar_id": event.CalendarId,
+		"time_zone":   event.TimeZone,
+		"all_day":     event.AllDay,
+		"transparent": event.Transparent,
+		"tags":        event.Tags,
+		"reminders":   event.Reminders,
+		"recurrence":  event.Recurrence,
+	}
+
+	set := bson.M{}
+	for _, field := range fields {
+		value, ok := values[field]
+		if !ok {
+			return errors.Errorf("unknown event field %q", field)
+		}
+		set[field] = value
+	}
+
+	filter := bson.M{"user_id": event.UserId, "_id": event.Id, "deleted_at": nil}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := c.collection.FindOneAndUpdate(ctx, withVersion(filter, event.Version),
+		bson.M{"$set": set, "$inc": incVersion}, opts).Decode(event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return c.notMatched(ctx, filter, event.Version)
+		}
+		return errors.Wrap(err, "failed to update event")
+	}
+	return nil
+}
+
+// GetEventByUID -> Gets the imported event according to given userId and UID. The overridden
+// instances of recurring events share the UID, so they are not matched. The trashed events are
+// matched too, the UID of an event is unique until it is purged.
+func (c *calendarRepository) GetEventByUID(ctx context.Context, userId uint64, uid string) (*Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var event Event
+	err := c.collection.FindOne(ctx, bson.M{"user_id": userId, "uid": uid, "recurring_event_id": nil}).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get event from database")
+	}
+	return &event, nil
+}
+
+// GetEventByResource -> Gets the imported event according to given userId and the name of its
+// CalDAV resource. Only the resources which are not named after the UID are matched, the
+// trashed events are not.
+func (c *calendarRepository) GetEventByResource(ctx context.Context, userId uint64, name string) (*Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var event Event
+	err := c.collection.FindOne(ctx, bson.M{"user_id": userId, "resource": name, "deleted_at": nil}).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
 		}
-		events = append(events, event)
+		return nil, errors.Wrap(err, "failed to get event from database")
+	}
+	return &event, nil
+}
+
+// ListOverrides -> Gets the overridden instances of the given recurring events which the given
+// userId organizes or is invited to
+func (c *calendarRepository) ListOverrides(ctx context.Context, userId uint64, eventIds []primitive.ObjectID) ([]Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var events []Event
+	if len(eventIds) == 0 {
+		return events, nil
+	}
+
+	cursor, err := c.collection.Find(ctx, bson.M{"$and": bson.A{
+		bson.M{"recurring_event_id": bson.M{"$in": eventIds}, "deleted_at": nil},
+		userCondition(userId),
+	}})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get overrides from database")
+	}
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, errors.Wrap(err, "failed to get overrides from database")
 	}
 
 	return events, nil
 }
 
-// DeleteEvent -> Deletes event according to given userId and eventId
-func (c *calendarRepository) DeleteEvent(ctx context.Context, eventId string, userId uint64) error {
-	id, err := primitive.ObjectIDFromHex(eventId)
+// MoveOverrides -> Moves the overridden instances since the given recurrence id to another recurring event
+func (c *calendarRepository) MoveOverrides(ctx context.Context, userId uint64, fromId, toId primitive.ObjectID, since time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := c.collection.UpdateMany(ctx,
+		bson.M{"user_id": userId, "recurring_event_id": fromId, "recurrence_id": bson.M{"$gte": since}},
+		bson.M{"$set": bson.M{"recurring_event_id": toId}, "$inc": incVersion})
+	if err != nil {
+		return errors.Wrap(err, "failed to move overrides")
+	}
+	return nil
+}
+
+// DeleteOverrides -> Moves the overridden instances since the given recurrence id to the trash.
+// If the recurrence id is zero, all the overridden instances of the recurring event are moved.
+// They are not restored with the recurring event, and they are purged from the trash with the
+// other events.
+func (c *calendarRepository) DeleteOverrides(ctx context.Context, userId uint64, eventId primitive.ObjectID, since time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := bson.M{"user_id": userId, "recurring_event_id": eventId, "deleted_at": nil}
+	if !since.IsZero() {
+		query["recurrence_id"] = bson.M{"$gte": since}
+	}
+
+	// The UID is dropped, so the occurrences can be overridden again by the imports
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	_, err := c.collection.UpdateMany(ctx, query,
+		bson.M{"$set": bson.M{"deleted_at": now}, "$unset": bson.M{"uid": ""}, "$inc": incVersion})
 	if err != nil {
-		return errors.Wrap(err, "failed to convert eventId to ObjectID")
+		return errors.Wrap(err, "failed to delete overrides")
 	}
-	return c.collection.FindOneAndDelete(ctx, bson.M{"user_id": userId, "_id": id}).Err()
+	return nil
 }
 
 // ServiceStatus -> A health check mechanism
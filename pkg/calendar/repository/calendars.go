@@ -0,0 +1,297 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/validator"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The default calendar is created for every user, the events are added to it if they are
+// created without a calendar
+const (
+	DefaultCalendarName  = "Calendar"
+	DefaultCalendarColor = "#1E90FF"
+)
+
+// The visibilities of a calendar
+const (
+	VisibilityPrivate = "private"
+	VisibilityPublic  = "public"
+)
+
+// Visibilities holds the valid calendar visibilities
+var Visibilities = []string{VisibilityPrivate, VisibilityPublic}
+
+var ErrDefaultCalendar = errors.New("the default calendar can not be deleted")
+
+// Calendar groups the events of a user, e.g. Work, Personal or On-call
+type Calendar struct {
+	Id     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserId uint64             `json:"user_id" bson:"user_id"`
+	Name   string             `json:"name" bson:"name"`
+	// Color is shown for the calendar, the clients suggest it for the new events of the calendar
+	Color      string `json:"color" bson:"color"`
+	Visibility string `json:"visibility" bson:"visibility"`
+	// TimeZone is the IANA time zone of the calendar, the time zone of the default calendar is
+	// the time zone of the user. UTC if empty.
+	TimeZone string `json:"time_zone,omitempty" bson:"time_zone,omitempty"`
+	// Default is set on the calendar which the user has from the start, it can not be deleted
+	Default bool `json:"default,omitempty" bson:"default,omitempty"`
+	// Role of the user on the calendars of the other users which are shared with the user
+	Role string `json:"role,omitempty" bson:"-"`
+}
+
+// UpdatableCalendarFields holds the calendar fields that can be used in an update mask
+var UpdatableCalendarFields = []string{"name", "color", "visibility", "time_zone"}
+
+// ValidateCalendar rules the calendar validation
+func ValidateCalendar(v *validator.Validator, calendar Calendar) {
+	v.Check(calendar.Name != "", "name", "must be provided")
+	v.Check(len(calendar.Name) <= 80, "name", "must not be more than 80 bytes long")
+
+	v.Check(calendar.Color != "", "color", "must be provided")
+	v.Check(strings.HasPrefix(calendar.Color, "#"), "color", "must be start with #")
+	v.Check(len(calendar.Color) == 7, "color", "must be 7 bytes long")
+
+	v.Check(validator.In(calendar.Visibility, Visibilities...), "visibility", "must be private or public")
+
+	if calendar.TimeZone != "" {
+		_, err := time.LoadLocation(calendar.TimeZone)
+		v.Check(err == nil && calendar.TimeZone != "Local", "time_zone", "must be a valid IANA time zone")
+	}
+}
+
+// Location returns the time zone of the calendar
+func (c Calendar) Location() *time.Location {
+	if c.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// MergeCalendar copies the given fields of the src calendar to the dst calendar
+func MergeCalendar(dst *Calendar, src Calendar, fields []string) {
+	for _, field := range fields {
+		switch field {
+		case "name":
+			dst.Name = src.Name
+		case "color":
+			dst.Color = src.Color
+		case "visibility":
+			dst.Visibility = src.Visibility
+		case "time_zone":
+			dst.TimeZone = src.TimeZone
+		}
+	}
+}
+
+// CreateCalendar -> Adds the calendar to the calendars database
+func (c *calendarRepository) CreateCalendar(ctx context.Context, calendar *Calendar) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	i, err := c.calendars.InsertOne(ctx, calendar)
+	if err != nil {
+		return errors.Wrap(err, "failed to insert calendar")
+	}
+
+	calendar.Id = i.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// ListCalendars -> Gets the calendars of the given userId in the creation order
+func (c *calendarRepository) ListCalendars(ctx context.Context, userId uint64) ([]Calendar, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cursor, err := c.calendars.Find(ctx, bson.M{"user_id": userId}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get calendars from database")
+	}
+
+	var calendars []Calendar
+	if err = cursor.All(ctx, &calendars); err != nil {
+		return nil, errors.Wrap(err, "failed to get calendars from database")
+	}
+	return calendars, nil
+}
+
+// GetCalendar -> Gets the calendar according to given userId and calendarId
+func (c *calendarRepository) GetCalendar(ctx context.Context, calendarId string, userId uint64) (*Calendar, error) {
+	id, err := primitive.ObjectIDFromHex(calendarId)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var calendar Calendar
+	err = c.calendars.FindOne(ctx, bson.M{"_id": id, "user_id": userId}).Decode(&calendar)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get calendar from database")
+	}
+	return &calendar, nil
+}
+
+// DefaultCalendar -> Gets the default calendar of the given userId, it is created on the first use
+func (c *calendarRepository) DefaultCalendar(ctx context.Context, userId uint64) (*Calendar, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userId, "default": true}
+	update := bson.M{"$setOnInsert": bson.M{
+		"name":       DefaultCalendarName,
+		"color":      DefaultCalendarColor,
+		"visibility": VisibilityPrivate,
+	}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var calendar Calendar
+	err := c.calendars.FindOneAndUpdate(ctx, filter, update, opts).Decode(&calendar)
+	if mongo.IsDuplicateKeyError(err) {
+		// The calendar is created by a concurrent request
+		err = c.calendars.FindOne(ctx, filter).Decode(&calendar)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get default calendar")
+	}
+	return &calendar, nil
+}
+
+// UpdateCalendar -> Sets the given fields of the calendar which belongs to the calendar's
+// userId, and fills the calendar with the updated document
+func (c *calendarRepository) UpdateCalendar(ctx context.Context, calendar *Calendar, fields []string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	values := bson.M{
+		"name":       calendar.Name,
+		"color":      calendar.Color,
+		"visibility": calendar.Visibility,
+		"time_zone":  calendar.TimeZone,
+	}
+
+	set := bson.M{}
+	for _, field := range fields {
+		value, ok := values[field]
+		if !ok {
+			return errors.Errorf("unknown calendar field %q", field)
+		}
+		set[field] = value
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := c.calendars.FindOneAndUpdate(ctx, bson.M{"_id": calendar.Id, "user_id": calendar.UserId},
+		bson.M{"$set": set}, opts).Decode(calendar)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrRecordNotFound
+		}
+		return errors.Wrap(err, "failed to update calendar")
+	}
+	return nil
+}
+
+// DeleteCalendar -> Deletes the calendar according to given userId and calendarId together
+// with its grants, moves its events to the trash and returns the events as they were before.
+// The overridden instances are not returned, they are deleted with their recurring events. The
+// default calendar is not deleted.
+func (c *calendarRepository) DeleteCalendar(ctx context.Context, calendarId primitive.ObjectID, userId uint64) ([]Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	res, err := c.calendars.DeleteOne(ctx, bson.M{"_id": calendarId, "user_id": userId, "default": nil})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete calendar")
+	}
+	if res.DeletedCount == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	filter := bson.M{"user_id": userId, "calendar_id": calendarId, "deleted_at": nil}
+	cursor, err := c.collection.Find(ctx, bson.M{"$and": bson.A{filter, bson.M{"recurring_event_id": nil}}})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the events of calendar")
+	}
+	var events []Event
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, errors.Wrap(err, "failed to get the events of calendar")
+	}
+
+	// The overridden instances have the calendar of their recurring events, so they share the
+	// deleted_at of their recurring events and are restored together
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	_, err = c.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"deleted_at": now}, "$inc": incVersion})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete the events of calendar")
+	}
+	_, err = c.acl.DeleteMany(ctx, bson.M{"calendar_id": calendarId})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete the grants of calendar")
+	}
+	return events, nil
+}
+
+// SetOverridesCalendar -> Moves the overridden instances of the recurring event to the calendar
+func (c *calendarRepository) SetOverridesCalendar(ctx context.Context, userId uint64, eventId,
+	calendarId primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	_, err := c.collection.UpdateMany(ctx, bson.M{"user_id": userId, "recurring_event_id": eventId},
+		bson.M{"$set": bson.M{"calendar_id": calendarId}, "$inc": incVersion})
+	if err != nil {
+		return errors.Wrap(err, "failed to move overrides")
+	}
+	return nil
+}
+
+// migrateCalendars moves the events which are created before the calendars into the default
+// calendars of their users. It does nothing if all the events have a calendar.
+func (c *calendarRepository) migrateCalendars(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	userIds, err := c.collection.Distinct(ctx, "user_id", bson.M{"calendar_id": nil})
+	if err != nil {
+		return errors.Wrap(err, "failed to migrate events to calendars")
+	}
+
+	for _, value := range userIds {
+		var userId uint64
+		switch id := value.(type) {
+		case int64:
+			userId = uint64(id)
+		case int32:
+			userId = uint64(id)
+		default:
+			continue
+		}
+
+		calendar, err := c.DefaultCalendar(ctx, userId)
+		if err != nil {
+			return err
+		}
+		_, err = c.collection.UpdateMany(ctx, bson.M{"user_id": userId, "calendar_id": nil},
+			bson.M{"$set": bson.M{"calendar_id": calendar.Id}})
+		if err != nil {
+			return errors.Wrap(err, "failed to migrate events to calendars")
+		}
+	}
+	return nil
+}
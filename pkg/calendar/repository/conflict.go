@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"github.com/3n0ugh/kalenderium/internal/validator"
+	"github.com/pkg/errors"
+)
+
+// The policies of the events which overlap the other events of the organizer on create. The
+// overlapping events are allowed without checking, allowed with the conflicts in the reply,
+// or rejected.
+const (
+	ConflictAllow  = "allow"
+	ConflictWarn   = "warn"
+	ConflictReject = "reject"
+)
+
+// ConflictPolicies holds the valid conflict policies
+var ConflictPolicies = []string{ConflictAllow, ConflictWarn, ConflictReject}
+
+var ErrConflict = errors.New("the event overlaps the other events of the organizer")
+
+// ConflictError rejects the event with the events which it overlaps
+type ConflictError struct {
+	Conflicts []Event
+}
+
+func (e *ConflictError) Error() string {
+	return ErrConflict.Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// ValidateConflictPolicy rules the conflict policy validation, empty means warn
+func ValidateConflictPolicy(v *validator.Validator, policy string) {
+	v.Check(policy == "" || validator.In(policy, ConflictPolicies...), "conflict_policy", "must be allow, warn or reject")
+}
+
+// Conflicts returns the events which overlap any of the occurrences. The events which are not
+// busy for the user, such as the transparent or the declined ones, do not conflict.
+func Conflicts(userId uint64, occurrences []Event, events []Event) []Event {
+	var conflicts []Event
+	for _, event := range events {
+		if event.Transparent || event.hasDeclined(userId) || !event.Start.Before(event.End) {
+			continue
+		}
+		for _, occurrence := range occurrences {
+			if occurrence.Start.Before(event.End) && event.Start.Before(occurrence.End) {
+				conflicts = append(conflicts, event)
+				break
+			}
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/validator"
+)
+
+// MaxFreeBusyUsers limits the users of a free/busy query
+const MaxFreeBusyUsers = 50
+
+// MaxFreeBusyWindow limits the time window of a free/busy query
+const MaxFreeBusyWindow = 366 * 24 * time.Hour
+
+// Interval is a busy time range, the end is exclusive
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// FreeBusy holds the busy intervals of the user. Err is set if the free/busy of the user can
+// not be seen, e.g. none of the calendars of the user is shared.
+type FreeBusy struct {
+	UserId uint64     `json:"user_id"`
+	Busy   []Interval `json:"busy"`
+	Err    string     `json:"err,omitempty"`
+}
+
+// ValidateFreeBusy rules the free/busy query validation
+func ValidateFreeBusy(v *validator.Validator, userIds []uint64, from, to time.Time) {
+	v.Check(len(userIds) > 0, "user_ids", "must be provided")
+	v.Check(len(userIds) <= MaxFreeBusyUsers, "user_ids", "must not have more than 50 users")
+	for _, userId := range userIds {
+		v.Check(userId != 0, "user_ids", "must not have zero ids")
+	}
+
+	v.Check(!from.IsZero(), "from", "must be provided")
+	v.Check(!to.IsZero(), "to", "must be provided")
+	if !from.IsZero() && !to.IsZero() {
+		v.Check(from.Before(to), "to", "must be after from")
+		v.Check(to.Sub(from) <= MaxFreeBusyWindow, "to", "must not be more than 366 days after from")
+	}
+}
+
+// BusyIntervals merges the times of the events into the busy intervals of the user in the
+// window. The transparent events, the events which the user declined and the events without
+// duration are not busy.
+func BusyIntervals(userId uint64, events []Event, from, to time.Time) []Interval {
+	var intervals []Interval
+	for _, event := range events {
+		if event.Transparent || event.hasDeclined(userId) {
+			continue
+		}
+
+		start, end := event.Start, event.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if !start.Before(end) {
+			continue
+		}
+		intervals = append(intervals, Interval{Start: start.UTC(), End: end.UTC()})
+	}
+	return MergeIntervals(intervals)
+}
+
+// MergeIntervals sorts the intervals and merges the overlapping and the adjacent ones
+func MergeIntervals(intervals []Interval) []Interval {
+	if len(intervals) == 0 {
+		return []Interval{}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+
+	merged := []Interval{intervals[0]}
+	for _, interval := range intervals[1:] {
+		last := &merged[len(merged)-1]
+		if interval.Start.After(last.End) {
+			merged = append(merged, interval)
+			continue
+		}
+		if interval.End.After(last.End) {
+			last.End = interval.End
+		}
+	}
+	return merged
+}
+
+// hasDeclined returns true if the user is invited to the event and declined it
+func (e Event) hasDeclined(userId uint64) bool {
+	for _, attendee := range e.Attendees {
+		if attendee.UserId != 0 && attendee.UserId == userId {
+			return attendee.Status == Declined
+		}
+	}
+	return false
+}
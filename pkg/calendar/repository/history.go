@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The actions which the history of an event records
+const (
+	HistoryCreate  = "create"
+	HistoryUpdate  = "update"
+	HistoryDelete  = "delete"
+	HistoryRestore = "restore"
+	HistoryRevert  = "revert"
+)
+
+// HistoryFields are the event fields which the changes are recorded for
+var HistoryFields = append(UpdatableFields[:len(UpdatableFields):len(UpdatableFields)], "attendees", "attachments")
+
+// HistoryEntry is a change of an event. The history of an event is append-only.
+type HistoryEntry struct {
+	Id      primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventId primitive.ObjectID `json:"event_id" bson:"event_id"`
+	// UserId is the user who made the change, the organizer or an editor of the event's calendar,
+	// or an attendee who responded
+	UserId  uint64   `json:"user_id" bson:"user_id"`
+	Action  string   `json:"action" bson:"action"`
+	Changes []Change `json:"changes,omitempty" bson:"changes,omitempty"`
+	// Event is the event after the change, or before the change if it is deleted
+	Event     Event     `json:"event" bson:"event"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+// Change is the JSON encoded values of an event field before and after the change. The value is
+// empty if the field is not set.
+type Change struct {
+	Field  string          `json:"field" bson:"field"`
+	Before json.RawMessage `json:"before,omitempty" bson:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// historyIndexes are the indexes which the history listing relies on
+var historyIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "_id", Value: -1}}},
+}
+
+// Diff returns the changes of the history fields from the before event to the after event. A nil
+// event has no fields set, so all the set fields of a created or a deleted event are changed.
+func Diff(before, after *Event) []Change {
+	var changes []Change
+	for _, field := range HistoryFields {
+		var b, a interface{}
+		if before != nil {
+			b = fieldValue(*before, field)
+		}
+		if after != nil {
+			a = fieldValue(*after, field)
+		}
+
+		bjs, ajs := encodeValue(b), encodeValue(a)
+		if string(bjs) != string(ajs) {
+			changes = append(changes, Change{Field: field, Before: bjs, After: ajs})
+		}
+	}
+	return changes
+}
+
+// fieldValue returns the value of the event field in the history fields
+func fieldValue(e Event, field string) interface{} {
+	switch field {
+	case "name":
+		return e.Name
+	case "details":
+		return e.Details
+	case "start":
+		return e.Start
+	case "end":
+		return e.End
+	case "color":
+		return e.Color
+	case "calendar_id":
+		return e.CalendarId
+	case "time_zone":
+		return e.TimeZone
+	case "all_day":
+		return e.AllDay
+	case "transparent":
+		return e.Transparent
+	case "tags":
+		return e.Tags
+	case "reminders":
+		return e.Reminders
+	case "recurrence":
+		return e.Recurrence
+	case "attendees":
+		return e.Attendees
+	case "attachments":
+		return e.Attachments
+	}
+	return nil
+}
+
+// encodeValue encodes the value as JSON, the zero values and the empty slices are not set
+func encodeValue(value interface{}) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0) {
+		return nil
+	}
+	js, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return js
+}
+
+// Clone returns a copy of the event which does not share the slices and the recurrence with it
+func (e Event) Clone() Event {
+	if e.Recurrence != nil {
+		recurrence := *e.Recurrence
+		recurrence.ExDates = append([]time.Time(nil), recurrence.ExDates...)
+		recurrence.RDates = append([]time.Time(nil), recurrence.RDates...)
+		e.Recurrence = &recurrence
+	}
+	e.Tags = append([]string(nil), e.Tags...)
+	e.Reminders = append([]Reminder(nil), e.Reminders...)
+	e.Attendees = append([]Attendee(nil), e.Attendees...)
+	e.Attachments = append([]Attachment(nil), e.Attachments...)
+	return e
+}
+
+// AddHistory -> Appends the entry to the history of its event
+func (c *calendarRepository) AddHistory(ctx context.Context, entry *HistoryEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	res, err := c.history.InsertOne(ctx, entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to add history")
+	}
+	entry.Id = res.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// AddHistories -> Appends the entries to the histories of their events at once
+func (c *calendarRepository) AddHistories(ctx context.Context, entries []*HistoryEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	documents := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		entry.Id = primitive.NewObjectID()
+		documents[i] = entry
+	}
+
+	if _, err := c.history.InsertMany(ctx, documents); err != nil {
+		return errors.Wrap(err, "failed to add histories")
+	}
+	return nil
+}
+
+// ListHistory -> Gets a page of the history of the event, the last change is the first, and
+// returns the next page token
+func (c *calendarRepository) ListHistory(ctx context.Context, eventId string, page Page) ([]HistoryEntry, string, error) {
+	id, err := primitive.ObjectIDFromHex(eventId)
+	if err != nil {
+		return nil, "", ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	filter := bson.M{"event_id": id}
+	if page.Token != "" {
+		cursor, err := decodePageToken(page.Token)
+		if err != nil {
+			return nil, "", err
+		}
+		filter["_id"] = bson.M{"$lt": cursor.Id}
+	}
+
+	// One more entry is fetched to know whether there is a next page
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(page.Size) + 1)
+
+	cursor, err := c.history.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to get history from database")
+	}
+
+	var entries []HistoryEntry
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, "", errors.Wrap(err, "failed to get history from database")
+	}
+
+	var nextPageToken string
+	if len(entries) > page.Size {
+		entries = entries[:page.Size]
+		nextPageToken = encodePageToken(Event{Id: entries[len(entries)-1].Id})
+	}
+	return entries, nextPageToken, nil
+}
+
+// GetHistoryEntry -> Gets the entry in the history of the event according to entryId
+func (c *calendarRepository) GetHistoryEntry(ctx context.Context, eventId, entryId string) (*HistoryEntry, error) {
+	id, err := primitive.ObjectIDFromHex(eventId)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+	entry, err := primitive.ObjectIDFromHex(entryId)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var result HistoryEntry
+	err = c.history.FindOne(ctx, bson.M{"_id": entry, "event_id": id}).Decode(&result)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get history entry")
+	}
+	return &result, nil
+}
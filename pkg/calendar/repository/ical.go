@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/ical"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// uidDomain makes the UIDs derived from the event ids globally unique
+const uidDomain = "@kalenderium"
+
+// MaxImportEvents limits the events which are imported at once
+const MaxImportEvents = 1000
+
+// DefaultColor is given to the imported events which have no color
+const DefaultColor = "#1976D2"
+
+// ImportResult reports the outcome of importing a VEVENT
+type ImportResult struct {
+	UID          string     `json:"uid"`
+	RecurrenceId *time.Time `json:"recurrence_id,omitempty"`
+	EventId      string     `json:"event_id,omitempty"`
+	Created      bool       `json:"created"`
+	Err          string     `json:"err,omitempty"`
+}
+
+// ICalUID returns the iCalendar UID of the event. The UID of the events which are not
+// imported is derived from the event id, and the occurrences of a recurring event share the
+// UID of the recurring event.
+func (e *Event) ICalUID() string {
+	switch {
+	case e.UID != "":
+		return e.UID
+	case e.RecurringEventId != nil:
+		return e.RecurringEventId.Hex() + uidDomain
+	default:
+		return e.Id.Hex() + uidDomain
+	}
+}
+
+// EventIdFromUID returns the event id which the UID is derived from, if any
+func EventIdFromUID(uid string) (primitive.ObjectID, bool) {
+	if !strings.HasSuffix(uid, uidDomain) {
+		return primitive.NilObjectID, false
+	}
+	id, err := primitive.ObjectIDFromHex(strings.TrimSuffix(uid, uidDomain))
+	return id, err == nil
+}
+
+// ToICal converts the event to an iCalendar VEVENT stamped with the given time
+func ToICal(event Event, stamp time.Time) ical.Event {
+	e := ical.Event{
+		UID:         event.ICalUID(),
+		Stamp:       stamp,
+		Summary:     event.Name,
+		Description: event.Details,
+		Color:       event.Color,
+		Start:       event.Start,
+		End:         event.End,
+		TimeZone:    event.TimeZone,
+		AllDay:      event.AllDay,
+		Transparent: event.Transparent,
+		Categories:  event.Tags,
+	}
+
+	if event.Recurrence != nil {
+		e.RRule = event.Recurrence.RRule
+		e.ExDates = event.Recurrence.ExDates
+		e.RDates = event.Recurrence.RDates
+	}
+	if event.RecurrenceId != nil {
+		e.RecurrenceId = *event.RecurrenceId
+	}
+	return e
+}
+
+// FromICal converts the iCalendar VEVENT to an event of the user
+func FromICal(e ical.Event, userId uint64) Event {
+	event := Event{
+		UserId:  userId,
+		Name:    e.Summary,
+		Details: e.Description,
+		Start:   e.Start,
+		End:     e.End,
+		Color:   e.Color,
+		UID:     e.UID,
+		AllDay:  e.AllDay,
+
+		Transparent: e.Transparent,
+		Tags:        NormalizeTags(e.Categories),
+	}
+	if event.Color == "" {
+		event.Color = DefaultColor
+	}
+	if !e.AllDay {
+		event.TimeZone = e.TimeZone
+	}
+
+	if e.RRule != "" {
+		event.Recurrence = &Recurrence{
+			RRule:   strings.TrimPrefix(e.RRule, "RRULE:"),
+			ExDates: e.ExDates,
+			RDates:  e.RDates,
+		}
+	}
+	if !e.RecurrenceId.IsZero() {
+		recurrenceId := e.RecurrenceId
+		event.RecurrenceId = &recurrenceId
+	}
+	return event
+}
@@ -1,45 +1,261 @@
 package mock
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
 )
 
+// DefaultCalendar is the default calendar of the user 22, WorkCalendar is the other one
+var DefaultCalendar = &repository.Calendar{
+	Id:         primitive.NewObjectID(),
+	UserId:     22,
+	Name:       repository.DefaultCalendarName,
+	Color:      repository.DefaultCalendarColor,
+	Visibility: repository.VisibilityPrivate,
+	Default:    true,
+}
+
+var WorkCalendar = &repository.Calendar{
+	Id:         primitive.NewObjectID(),
+	UserId:     22,
+	Name:       "Work",
+	Color:      "#FF8800",
+	Visibility: repository.VisibilityPublic,
+}
+
+// Grants share WorkCalendar with the users 27, 28, 29 and 31
+var Grants = []repository.Grant{
+	{CalendarId: WorkCalendar.Id, UserId: 27, Role: repository.RoleEditor},
+	{CalendarId: WorkCalendar.Id, UserId: 28, Role: repository.RoleFreeBusy},
+	{CalendarId: WorkCalendar.Id, UserId: 29, Role: repository.RoleViewer},
+	{CalendarId: WorkCalendar.Id, UserId: 31, Role: repository.RoleOwner},
+}
+
 var Event = &repository.Event{
-	Id:      primitive.NewObjectID(),
-	UserId:  22,
-	Name:    "Spring Time",
-	Details: "Spring adds new life and new beauty to all that is.",
-	Start:   time.Time{}.Add(time.Second),
-	End:     time.Time{}.Add(time.Second),
-	Color:   "#00FF00",
+	Id:         primitive.NewObjectID(),
+	UserId:     22,
+	Name:       "Spring Time",
+	Details:    "Spring adds new life and new beauty to all that is.",
+	Start:      time.Time{}.Add(time.Second),
+	End:        time.Time{}.Add(time.Second),
+	Color:      "#00FF00",
+	CalendarId: DefaultCalendar.Id,
+	Tags:       []string{"personal", "music"},
+}
+
+// Event2Attachment is attached to Event2, its content is AttachmentContent
+var Event2Attachment = repository.Attachment{
+	Id:          primitive.NewObjectID(),
+	Name:        "agenda.txt",
+	ContentType: "text/plain",
+	Size:        int64(len(AttachmentContent)),
+	CreatedAt:   time.Date(2022, time.January, 10, 9, 0, 0, 0, time.UTC),
 }
 
+var AttachmentContent = []byte("1. Summer plans")
+
 var Event2 = &repository.Event{
-	Id:      primitive.NewObjectID(),
-	UserId:  22,
-	Name:    "Summer Time",
-	Details: "Oh, the summer night, has a smile of light, and she sits on a sapphire throne",
-	Start:   time.Time{}.Add(time.Second),
-	End:     time.Time{}.Add(time.Second),
-	Color:   "#00FFFF",
+	Id:         primitive.NewObjectID(),
+	CalendarId: WorkCalendar.Id,
+	UserId:     22,
+	Name:       "Summer Time",
+	Details:    "Oh, the summer night, has a smile of light, and she sits on a sapphire throne",
+	Start:      time.Time{}.Add(time.Second),
+	End:        time.Time{}.Add(time.Second),
+	Color:      "#00FFFF",
+	Tags:       []string{"work", "music"},
+	Attendees: []repository.Attendee{
+		{UserId: 26, Status: repository.NeedsAction},
+		{Email: "guest@example.com", Status: repository.NeedsAction},
+	},
+	Attachments: []repository.Attachment{Event2Attachment},
+	Version:     2,
+}
+
+var deletedAt = time.Date(2022, time.February, 1, 12, 0, 0, 0, time.UTC)
+
+// TrashedEvent is in the trash of the user 22
+var TrashedEvent = &repository.Event{
+	Id:         primitive.NewObjectID(),
+	CalendarId: DefaultCalendar.Id,
+	UserId:     22,
+	Name:       "Autumn Time",
+	Details:    "Autumn is a second spring when every leaf is a flower.",
+	Start:      time.Date(2022, time.January, 20, 9, 0, 0, 0, time.UTC),
+	End:        time.Date(2022, time.January, 20, 10, 0, 0, 0, time.UTC),
+	Color:      "#FF8800",
+	DeletedAt:  &deletedAt,
+	Version:    2,
+}
+
+var createdEvent2 = repository.Event{
+	Id:         Event2.Id,
+	CalendarId: WorkCalendar.Id,
+	UserId:     22,
+	Name:       "Summer Meeting",
+	Start:      time.Time{}.Add(time.Second),
+	End:        time.Time{}.Add(time.Second),
+	Color:      "#00FFFF",
+	Version:    1,
+}
+
+// Event2History is the history of Event2, the last change is the first. The organizer creates
+// the event and an editor of the work calendar updates it.
+var Event2History = []repository.HistoryEntry{
+	{
+		Id:      primitive.NewObjectID(),
+		EventId: Event2.Id,
+		UserId:  27,
+		Action:  repository.HistoryUpdate,
+		Changes: repository.Diff(&createdEvent2, Event2),
+		Event:   *Event2,
+	},
+	{
+		Id:      primitive.NewObjectID(),
+		EventId: Event2.Id,
+		UserId:  22,
+		Action:  repository.HistoryCreate,
+		Changes: repository.Diff(nil, &createdEvent2),
+		Event:   createdEvent2,
+	},
+}
+
+var recurringStart = time.Date(2022, time.January, 3, 9, 0, 0, 0, time.UTC)
+
+var recurringLast = recurringStart.AddDate(0, 0, 21).Add(time.Hour)
+
+// RecurringEvent repeats weekly four times, the second occurrence is overridden by Override
+var RecurringEvent = &repository.Event{
+	Id:       primitive.NewObjectID(),
+	UserId:   24,
+	Name:     "Weekly Meeting",
+	Details:  "What are you waiting for? Winter is coming.",
+	Start:    recurringStart,
+	End:      recurringStart.Add(time.Hour),
+	Color:    "#0000FF",
+	UID:      "weekly-meeting@example.com",
+	Resource: "weekly-meeting",
+	Recurrence: &repository.Recurrence{
+		RRule: "FREQ=WEEKLY;COUNT=4",
+		Last:  &recurringLast,
+	},
+}
+
+var overriddenId = RecurringEvent.Id
+
+var overriddenStart = recurringStart.AddDate(0, 0, 7)
+
+var Override = &repository.Event{
+	Id:               primitive.NewObjectID(),
+	UserId:           24,
+	Name:             "Weekly Meeting",
+	Details:          "Moved to the afternoon.",
+	Start:            overriddenStart.Add(5 * time.Hour),
+	End:              overriddenStart.Add(6 * time.Hour),
+	Color:            "#0000FF",
+	RecurringEventId: &overriddenId,
+	RecurrenceId:     &overriddenStart,
+	UID:              "weekly-meeting@example.com",
+}
+
+var berlin, _ = time.LoadLocation("Europe/Berlin")
+
+var timeZoneStart = time.Date(2022, time.March, 21, 9, 0, 0, 0, berlin)
+
+var timeZoneLast = time.Date(2022, time.April, 4, 10, 0, 0, 0, berlin)
+
+// TimeZoneEvent repeats weekly at 09:00 in Berlin, the summer time starts after the first occurrence
+var TimeZoneEvent = &repository.Event{
+	Id:       primitive.NewObjectID(),
+	UserId:   25,
+	Name:     "Stand-up",
+	Start:    timeZoneStart.UTC(),
+	End:      timeZoneStart.Add(time.Hour).UTC(),
+	Color:    "#FF0000",
+	TimeZone: "Europe/Berlin",
+	Recurrence: &repository.Recurrence{
+		RRule: "FREQ=WEEKLY;COUNT=3",
+		Last:  &timeZoneLast,
+	},
 }
 
 type CalendarRepository interface {
 	CreateEvent(ctx context.Context, event *repository.Event) error
-	ListEvent(ctx context.Context, userId uint64) ([]repository.Event, error)
-	DeleteEvent(ctx context.Context, eventId string, userId uint64) error
+	CreateEvents(ctx context.Context, events []*repository.Event) (map[int]error, error)
+	ListEvent(ctx context.Context, userId uint64, filter repository.EventFilter, page repository.Page) ([]repository.Event, string, error)
+	ListTags(ctx context.Context, userId uint64) ([]repository.TagCount, error)
+	SearchEvents(ctx context.Context, userId uint64, query string, filter repository.EventFilter, page repository.Page) ([]repository.Event, string, error)
+	GetEvent(ctx context.Context, eventId string, userId uint64) (*repository.Event, error)
+	UpdateEvent(ctx context.Context, event *repository.Event, fields []string) error
+	DeleteEvent(ctx context.Context, eventId string, userId uint64, version int64) (*repository.Event, error)
+	DeleteEvents(ctx context.Context, userId uint64, eventIds []primitive.ObjectID) ([]repository.Event, error)
+	ListTrash(ctx context.Context, userId uint64, page repository.Page) ([]repository.Event, string, error)
+	RestoreEvent(ctx context.Context, eventId string, userId uint64) (*repository.Event, error)
+	PurgeEvent(ctx context.Context, eventId string, userId uint64) error
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+	AddHistory(ctx context.Context, entry *repository.HistoryEntry) error
+	AddHistories(ctx context.Context, entries []*repository.HistoryEntry) error
+	ListHistory(ctx context.Context, eventId string, page repository.Page) ([]repository.HistoryEntry, string, error)
+	WatchChanges(ctx context.Context, userId uint64, resumeToken string, send func(repository.HistoryEntry, string) error) error
+	GetHistoryEntry(ctx context.Context, eventId, entryId string) (*repository.HistoryEntry, error)
+	GetEventByUID(ctx context.Context, userId uint64, uid string) (*repository.Event, error)
+	GetEventByResource(ctx context.Context, userId uint64, name string) (*repository.Event, error)
+	ListOverrides(ctx context.Context, userId uint64, eventIds []primitive.ObjectID) ([]repository.Event, error)
+	MoveOverrides(ctx context.Context, userId uint64, fromId, toId primitive.ObjectID, since time.Time) error
+	DeleteOverrides(ctx context.Context, userId uint64, eventId primitive.ObjectID, since time.Time) error
+	GetInvitedEvent(ctx context.Context, eventId string, userId uint64) (*repository.Event, error)
+	SetAttendees(ctx context.Context, userId uint64, eventId primitive.ObjectID, attendees []repository.Attendee) error
+	SetAttendeeStatus(ctx context.Context, eventId primitive.ObjectID, userId uint64, status string) error
+	ListRemindedEvents(ctx context.Context, from, to time.Time) ([]repository.Event, error)
+	CreateReminderJobs(ctx context.Context, jobs []repository.ReminderJob) error
+	ClaimReminderJob(ctx context.Context, owner string, now time.Time, lease time.Duration) (*repository.ReminderJob, error)
+	FinishReminderJob(ctx context.Context, job *repository.ReminderJob) error
+	CreateCalendar(ctx context.Context, calendar *repository.Calendar) error
+	ListCalendars(ctx context.Context, userId uint64) ([]repository.Calendar, error)
+	GetCalendar(ctx context.Context, calendarId string, userId uint64) (*repository.Calendar, error)
+	DefaultCalendar(ctx context.Context, userId uint64) (*repository.Calendar, error)
+	UpdateCalendar(ctx context.Context, calendar *repository.Calendar, fields []string) error
+	DeleteCalendar(ctx context.Context, calendarId primitive.ObjectID, userId uint64) ([]repository.Event, error)
+	SetOverridesCalendar(ctx context.Context, userId uint64, eventId, calendarId primitive.ObjectID) error
+	FindCalendar(ctx context.Context, calendarId string) (*repository.Calendar, error)
+	FindEvent(ctx context.Context, eventId string) (*repository.Event, error)
+	SetGrant(ctx context.Context, grant *repository.Grant) error
+	GetGrant(ctx context.Context, calendarId primitive.ObjectID, userId uint64) (*repository.Grant, error)
+	ListGrants(ctx context.Context, calendarId primitive.ObjectID) ([]repository.Grant, error)
+	DeleteGrant(ctx context.Context, calendarId primitive.ObjectID, userId uint64) error
+	ListSharedCalendars(ctx context.Context, userId uint64) ([]repository.Calendar, error)
+	AddAttachment(ctx context.Context, eventId string, userId uint64, attachment *repository.Attachment,
+		content io.Reader) (*repository.Event, error)
+	OpenAttachment(ctx context.Context, attachmentId string) (io.ReadCloser, error)
+	DeleteAttachment(ctx context.Context, eventId string, userId uint64, attachmentId string) (*repository.Event, error)
 	ServiceStatus(ctx context.Context) error
 }
 
+// calendarRepository keeps the added history entries as the change log of the watches, the log
+// starts with Event2History
 type calendarRepository struct {
+	mu      sync.Mutex
+	changes []repository.HistoryEntry
+	changed chan struct{}
 }
 
 func NewCalendarRepository() CalendarRepository {
-	return &calendarRepository{}
+	c := &calendarRepository{changed: make(chan struct{})}
+	for i := len(Event2History) - 1; i >= 0; i-- {
+		c.changes = append(c.changes, Event2History[i])
+	}
+	return c
 }
 
 func (c *calendarRepository) CreateEvent(_ context.Context, event *repository.Event) error {
@@ -48,24 +264,665 @@ func (c *calendarRepository) CreateEvent(_ context.Context, event *repository.Ev
 	return nil
 }
 
-func (c *calendarRepository) ListEvent(_ context.Context, userId uint64) ([]repository.Event, error) {
-	if userId == Event.UserId {
-		var eventList = []repository.Event{
-			*Event,
-			*Event2,
+func (c *calendarRepository) CreateEvents(_ context.Context, events []*repository.Event) (map[int]error, error) {
+	for _, event := range events {
+		event.Id, event.Version = primitive.NewObjectID(), 1
+	}
+	return nil, nil
+}
+
+// ListEvent uses the index of the next event as the page token
+func (c *calendarRepository) ListEvent(_ context.Context, userId uint64, filter repository.EventFilter,
+	page repository.Page) ([]repository.Event, string, error) {
+	if userId != Event.UserId && userId != RecurringEvent.UserId && userId != TimeZoneEvent.UserId &&
+		!Event2.IsAttendee(userId) && len(filter.SharedCalendarIds) == 0 {
+		return nil, "", repository.ErrRecordNotFound
+	}
+
+	var eventList []repository.Event
+	for _, event := range []*repository.Event{Event, Event2, RecurringEvent, TimeZoneEvent} {
+		if event.UserId != userId && !event.IsAttendee(userId) && !hasCalendar(filter.SharedCalendarIds, event.CalendarId) {
+			continue
 		}
-		return eventList, nil
+		if len(filter.CalendarIds) > 0 && !hasCalendar(filter.CalendarIds, event.CalendarId) {
+			continue
+		}
+		if !hasTags(event, filter.Tags) {
+			continue
+		}
+		end := event.End
+		if event.IsRecurring() {
+			end = *event.Recurrence.Last
+		}
+		if !filter.From.IsZero() && end.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !event.Start.Before(filter.To) {
+			continue
+		}
+		eventList = append(eventList, *event)
+	}
+
+	offset := 0
+	if page.Token != "" {
+		var err error
+		if offset, err = strconv.Atoi(page.Token); err != nil || offset > len(eventList) {
+			return nil, "", repository.ErrInvalidPageToken
+		}
+	}
+	eventList = eventList[offset:]
+
+	var nextPageToken string
+	if len(eventList) > page.Size {
+		eventList = eventList[:page.Size]
+		nextPageToken = strconv.Itoa(offset + page.Size)
+	}
+	return eventList, nextPageToken, nil
+}
+
+// SearchEvents ranks the events by the words of the query in their names and details, a word in
+// the name weighs three times a word in the details. It uses the index of the next event as the
+// page token.
+func (c *calendarRepository) SearchEvents(_ context.Context, userId uint64, query string, filter repository.EventFilter,
+	page repository.Page) ([]repository.Event, string, error) {
+	type result struct {
+		event repository.Event
+		score int
+	}
+	var results []result
+	for _, event := range []*repository.Event{Event, Event2, RecurringEvent, Override, TimeZoneEvent} {
+		if event.UserId != userId && !event.IsAttendee(userId) || !hasTags(event, filter.Tags) {
+			continue
+		}
+		end := event.End
+		if event.IsRecurring() {
+			end = *event.Recurrence.Last
+		}
+		if !filter.From.IsZero() && end.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !event.Start.Before(filter.To) {
+			continue
+		}
+
+		score := 0
+		for _, word := range strings.Fields(strings.ToLower(query)) {
+			score += 3*countWord(event.Name, word) + countWord(event.Details, word)
+		}
+		if score > 0 {
+			results = append(results, result{event: *event, score: score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+
+	offset := 0
+	if page.Token != "" {
+		var err error
+		if offset, err = strconv.Atoi(page.Token); err != nil || offset <= 0 || offset > len(results) {
+			return nil, "", repository.ErrInvalidPageToken
+		}
+	}
+	results = results[offset:]
+
+	var nextPageToken string
+	if len(results) > page.Size {
+		results = results[:page.Size]
+		nextPageToken = strconv.Itoa(offset + page.Size)
+	}
+	events := []repository.Event{}
+	for _, r := range results {
+		events = append(events, r.event)
+	}
+	return events, nextPageToken, nil
+}
+
+// countWord counts the words of the text which are the given lower case word
+func countWord(text, word string) int {
+	count := 0
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if w == word {
+			count++
+		}
+	}
+	return count
+}
+
+// hasTags reports whether the event has all the tags
+func hasTags(event *repository.Event, tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, t := range event.Tags {
+			found = found || t == tag
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ListTags counts the tags of the events which the user organizes
+func (c *calendarRepository) ListTags(_ context.Context, userId uint64) ([]repository.TagCount, error) {
+	counts := map[string]int{}
+	for _, event := range []*repository.Event{Event, Event2, RecurringEvent, TimeZoneEvent} {
+		if event.UserId != userId {
+			continue
+		}
+		for _, tag := range event.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := []repository.TagCount{}
+	for tag, count := range counts {
+		tags = append(tags, repository.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags, nil
+}
+
+// hasCalendar reports whether the ids have the calendar id
+func hasCalendar(ids []primitive.ObjectID, calendarId primitive.ObjectID) bool {
+	for _, id := range ids {
+		if id == calendarId {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *calendarRepository) GetEvent(_ context.Context, eventId string, userId uint64) (*repository.Event, error) {
+	for _, event := range []*repository.Event{Event, Event2, RecurringEvent, Override} {
+		if userId == event.UserId && eventId == event.Id.Hex() {
+			return copyEvent(event), nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) GetEventByUID(_ context.Context, userId uint64, uid string) (*repository.Event, error) {
+	if userId == RecurringEvent.UserId && uid == RecurringEvent.UID {
+		return copyEvent(RecurringEvent), nil
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) GetEventByResource(_ context.Context, userId uint64, name string) (*repository.Event, error) {
+	if userId == RecurringEvent.UserId && RecurringEvent.Resource != "" && name == RecurringEvent.Resource {
+		return copyEvent(RecurringEvent), nil
 	}
 	return nil, repository.ErrRecordNotFound
 }
 
-func (c *calendarRepository) DeleteEvent(_ context.Context, eventId string, userId uint64) error {
+// copyEvent copies the event, so the callers can not change the mock events
+func copyEvent(event *repository.Event) *repository.Event {
+	e := *event
+	if e.Recurrence != nil {
+		recurrence := *e.Recurrence
+		e.Recurrence = &recurrence
+	}
+	e.Attendees = append([]repository.Attendee(nil), e.Attendees...)
+	e.Attachments = append([]repository.Attachment(nil), e.Attachments...)
+	return &e
+}
+
+func (c *calendarRepository) UpdateEvent(_ context.Context, event *repository.Event, fields []string) error {
+	for _, e := range []*repository.Event{Event, Event2, RecurringEvent, Override} {
+		if event.UserId == e.UserId && event.Id == e.Id {
+			if event.Version != 0 && event.Version != e.Version {
+				return repository.ErrVersionMismatch
+			}
+			updated := *e
+			repository.MergeEvent(&updated, *event, fields)
+			updated.Version++
+			*event = updated
+			return nil
+		}
+	}
+	return repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) DeleteEvent(_ context.Context, eventId string, userId uint64,
+	version int64) (*repository.Event, error) {
 	if userId == Event.UserId && eventId == Event.Id.String() {
+		return copyEvent(Event), nil
+	}
+	for _, event := range []*repository.Event{Event2, RecurringEvent, Override} {
+		if userId == event.UserId && eventId == event.Id.Hex() {
+			if version != 0 && version != event.Version {
+				return nil, repository.ErrVersionMismatch
+			}
+			return copyEvent(event), nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) DeleteEvents(_ context.Context, userId uint64, eventIds []primitive.ObjectID) ([]repository.Event, error) {
+	var events []repository.Event
+	for _, event := range []*repository.Event{Event, Event2, RecurringEvent, Override, TimeZoneEvent} {
+		for _, id := range eventIds {
+			if userId == event.UserId && id == event.Id {
+				events = append(events, *copyEvent(event))
+				break
+			}
+		}
+	}
+	return events, nil
+}
+
+// ListTrash uses the index of the next event as the page token
+func (c *calendarRepository) ListTrash(_ context.Context, userId uint64, page repository.Page) ([]repository.Event, string, error) {
+	events := []repository.Event{}
+	if userId == TrashedEvent.UserId {
+		events = append(events, *TrashedEvent)
+	}
+
+	offset := 0
+	if page.Token != "" {
+		var err error
+		if offset, err = strconv.Atoi(page.Token); err != nil || offset <= 0 || offset > len(events) {
+			return nil, "", repository.ErrInvalidPageToken
+		}
+	}
+	events = events[offset:]
+
+	var nextPageToken string
+	if len(events) > page.Size {
+		events = events[:page.Size]
+		nextPageToken = strconv.Itoa(offset + page.Size)
+	}
+	return events, nextPageToken, nil
+}
+
+func (c *calendarRepository) RestoreEvent(_ context.Context, eventId string, userId uint64) (*repository.Event, error) {
+	if userId != TrashedEvent.UserId || eventId != TrashedEvent.Id.Hex() {
+		return nil, repository.ErrRecordNotFound
+	}
+	event := copyEvent(TrashedEvent)
+	event.DeletedAt = nil
+	event.Version++
+	return event, nil
+}
+
+func (c *calendarRepository) PurgeEvent(_ context.Context, eventId string, userId uint64) error {
+	if userId != TrashedEvent.UserId || eventId != TrashedEvent.Id.Hex() {
+		return repository.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (c *calendarRepository) PurgeTrash(_ context.Context, before time.Time) (int64, error) {
+	if TrashedEvent.DeletedAt.Before(before) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (c *calendarRepository) AddHistory(_ context.Context, entry *repository.HistoryEntry) error {
+	entry.Id = primitive.NewObjectID()
+	c.addChanges(*entry)
+	return nil
+}
+
+func (c *calendarRepository) AddHistories(_ context.Context, entries []*repository.HistoryEntry) error {
+	changes := make([]repository.HistoryEntry, len(entries))
+	for i, entry := range entries {
+		entry.Id = primitive.NewObjectID()
+		changes[i] = *entry
+	}
+	c.addChanges(changes...)
+	return nil
+}
+
+// addChanges appends the entries to the change log and wakes up the watches
+func (c *calendarRepository) addChanges(entries ...repository.HistoryEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.changes = append(c.changes, entries...)
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// ListHistory uses the index of the next entry as the page token
+func (c *calendarRepository) ListHistory(_ context.Context, eventId string, page repository.Page) ([]repository.HistoryEntry,
+	string, error) {
+	entries := []repository.HistoryEntry{}
+	if eventId == Event2.Id.Hex() {
+		entries = append(entries, Event2History...)
+	}
+
+	offset := 0
+	if page.Token != "" {
+		var err error
+		if offset, err = strconv.Atoi(page.Token); err != nil || offset <= 0 || offset > len(entries) {
+			return nil, "", repository.ErrInvalidPageToken
+		}
+	}
+	entries = entries[offset:]
+
+	var nextPageToken string
+	if len(entries) > page.Size {
+		entries = entries[:page.Size]
+		nextPageToken = strconv.Itoa(offset + page.Size)
+	}
+	return entries, nextPageToken, nil
+}
+
+// ResumeToken returns the resume token of the entry of Event2History, the watch which is resumed
+// with it gets the changes after the entry
+func ResumeToken(entry repository.HistoryEntry) string {
+	for i := range Event2History {
+		if Event2History[i].Id == entry.Id {
+			return changeToken(len(Event2History) - i)
+		}
+	}
+	return ""
+}
+
+// changeToken is the resume token after the first n changes of the log
+func changeToken(n int) string {
+	raw, _ := bson.Marshal(bson.M{"_data": strconv.Itoa(n)})
+	return repository.EncodeResumeToken(raw)
+}
+
+// WatchChanges sends the changes of the change log which the user watches until the context is
+// done, starting after the resume token or from now
+func (c *calendarRepository) WatchChanges(ctx context.Context, userId uint64, resumeToken string,
+	send func(repository.HistoryEntry, string) error) error {
+	c.mu.Lock()
+	next, size := len(c.changes), len(c.changes)
+	c.mu.Unlock()
+
+	if resumeToken != "" {
+		raw, err := repository.DecodeResumeToken(resumeToken)
+		if err != nil {
+			return err
+		}
+		var token struct {
+			Data string `bson:"_data"`
+		}
+		if err = bson.Unmarshal(raw, &token); err != nil {
+			return repository.ErrInvalidPageToken
+		}
+		if next, err = strconv.Atoi(token.Data); err != nil || next < 0 || next > size {
+			return repository.ErrInvalidPageToken
+		}
+	}
+
+	for {
+		c.mu.Lock()
+		changes, changed := c.changes[next:], c.changed
+		c.mu.Unlock()
+
+		for _, entry := range changes {
+			next++
+			for _, watcher := range entry.Event.Watchers() {
+				if watcher != userId {
+					continue
+				}
+				if err := send(entry, changeToken(next)); err != nil {
+					return err
+				}
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+func (c *calendarRepository) GetHistoryEntry(_ context.Context, eventId, entryId string) (*repository.HistoryEntry, error) {
+	for _, entry := range Event2History {
+		if entry.EventId.Hex() == eventId && entry.Id.Hex() == entryId {
+			return &entry, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) ListOverrides(_ context.Context, userId uint64, eventIds []primitive.ObjectID) ([]repository.Event, error) {
+	var overrides []repository.Event
+	for _, id := range eventIds {
+		if userId == Override.UserId && id == *Override.RecurringEventId {
+			overrides = append(overrides, *Override)
+		}
+	}
+	return overrides, nil
+}
+
+func (c *calendarRepository) MoveOverrides(_ context.Context, _ uint64, _, _ primitive.ObjectID, _ time.Time) error {
+	return nil
+}
+
+func (c *calendarRepository) DeleteOverrides(_ context.Context, _ uint64, _ primitive.ObjectID, _ time.Time) error {
+	return nil
+}
+
+func (c *calendarRepository) GetInvitedEvent(_ context.Context, eventId string, userId uint64) (*repository.Event, error) {
+	if eventId == Event2.Id.Hex() && Event2.IsAttendee(userId) {
+		return copyEvent(Event2), nil
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) SetAttendees(_ context.Context, userId uint64, eventId primitive.ObjectID, _ []repository.Attendee) error {
+	for _, event := range []*repository.Event{Event, Event2, RecurringEvent} {
+		if userId == event.UserId && eventId == event.Id {
+			return nil
+		}
+	}
+	return repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) SetAttendeeStatus(_ context.Context, eventId primitive.ObjectID, userId uint64, _ string) error {
+	if eventId == Event2.Id && Event2.IsAttendee(userId) {
 		return nil
 	}
 	return repository.ErrRecordNotFound
 }
 
+func (c *calendarRepository) ListRemindedEvents(_ context.Context, _, _ time.Time) ([]repository.Event, error) {
+	return nil, nil
+}
+
+func (c *calendarRepository) CreateReminderJobs(_ context.Context, _ []repository.ReminderJob) error {
+	return nil
+}
+
+func (c *calendarRepository) ClaimReminderJob(_ context.Context, _ string, _ time.Time, _ time.Duration) (*repository.ReminderJob, error) {
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) FinishReminderJob(_ context.Context, _ *repository.ReminderJob) error {
+	return nil
+}
+
+func (c *calendarRepository) CreateCalendar(_ context.Context, calendar *repository.Calendar) error {
+	calendar.Id, _ = primitive.ObjectIDFromHex("62a1c0d4e1b2c3d4e5f60718")
+	return nil
+}
+
+func (c *calendarRepository) ListCalendars(_ context.Context, userId uint64) ([]repository.Calendar, error) {
+	if userId == DefaultCalendar.UserId {
+		return []repository.Calendar{*DefaultCalendar, *WorkCalendar}, nil
+	}
+	calendar, _ := c.DefaultCalendar(context.Background(), userId)
+	return []repository.Calendar{*calendar}, nil
+}
+
+func (c *calendarRepository) GetCalendar(_ context.Context, calendarId string, userId uint64) (*repository.Calendar, error) {
+	for _, calendar := range []*repository.Calendar{DefaultCalendar, WorkCalendar} {
+		if userId == calendar.UserId && calendarId == calendar.Id.Hex() {
+			copied := *calendar
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+// DefaultCalendar gives the users other than 22 a new default calendar, the calendar of the
+// user 25 is in the time zone of TimeZoneEvent
+func (c *calendarRepository) DefaultCalendar(_ context.Context, userId uint64) (*repository.Calendar, error) {
+	if userId == DefaultCalendar.UserId {
+		calendar := *DefaultCalendar
+		return &calendar, nil
+	}
+	calendar := &repository.Calendar{
+		Id:         primitive.NewObjectID(),
+		UserId:     userId,
+		Name:       repository.DefaultCalendarName,
+		Color:      repository.DefaultCalendarColor,
+		Visibility: repository.VisibilityPrivate,
+		Default:    true,
+	}
+	if userId == TimeZoneEvent.UserId {
+		calendar.TimeZone = TimeZoneEvent.TimeZone
+	}
+	return calendar, nil
+}
+
+func (c *calendarRepository) UpdateCalendar(_ context.Context, calendar *repository.Calendar, fields []string) error {
+	for _, cal := range []*repository.Calendar{DefaultCalendar, WorkCalendar} {
+		if calendar.UserId == cal.UserId && calendar.Id == cal.Id {
+			updated := *cal
+			repository.MergeCalendar(&updated, *calendar, fields)
+			*calendar = updated
+			return nil
+		}
+	}
+	return repository.ErrRecordNotFound
+}
+
+// DeleteCalendar returns Event2, the event of the user 22 in the work calendar
+func (c *calendarRepository) DeleteCalendar(_ context.Context, calendarId primitive.ObjectID, userId uint64) ([]repository.Event, error) {
+	if userId == WorkCalendar.UserId && calendarId == WorkCalendar.Id {
+		return []repository.Event{*copyEvent(Event2)}, nil
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) SetOverridesCalendar(_ context.Context, _ uint64, _, _ primitive.ObjectID) error {
+	return nil
+}
+
+func (c *calendarRepository) FindCalendar(_ context.Context, calendarId string) (*repository.Calendar, error) {
+	for _, calendar := range []*repository.Calendar{DefaultCalendar, WorkCalendar} {
+		if calendarId == calendar.Id.Hex() {
+			copied := *calendar
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) FindEvent(_ context.Context, eventId string) (*repository.Event, error) {
+	for _, event := range []*repository.Event{Event, Event2, RecurringEvent, Override, TimeZoneEvent} {
+		if eventId == event.Id.Hex() {
+			return copyEvent(event), nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) SetGrant(_ context.Context, _ *repository.Grant) error {
+	return nil
+}
+
+func (c *calendarRepository) GetGrant(_ context.Context, calendarId primitive.ObjectID, userId uint64) (*repository.Grant, error) {
+	for _, grant := range Grants {
+		if calendarId == grant.CalendarId && userId == grant.UserId {
+			copied := grant
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) ListGrants(_ context.Context, calendarId primitive.ObjectID) ([]repository.Grant, error) {
+	var grants []repository.Grant
+	for _, grant := range Grants {
+		if calendarId == grant.CalendarId {
+			grants = append(grants, grant)
+		}
+	}
+	return grants, nil
+}
+
+func (c *calendarRepository) DeleteGrant(ctx context.Context, calendarId primitive.ObjectID, userId uint64) error {
+	_, err := c.GetGrant(ctx, calendarId, userId)
+	return err
+}
+
+func (c *calendarRepository) ListSharedCalendars(ctx context.Context, userId uint64) ([]repository.Calendar, error) {
+	var calendars []repository.Calendar
+	for _, grant := range Grants {
+		if userId == grant.UserId {
+			calendar, err := c.FindCalendar(ctx, grant.CalendarId.Hex())
+			if err != nil {
+				return nil, err
+			}
+			calendar.Role = grant.Role
+			calendars = append(calendars, *calendar)
+		}
+	}
+	return calendars, nil
+}
+
+func (c *calendarRepository) AddAttachment(_ context.Context, eventId string, userId uint64,
+	attachment *repository.Attachment, content io.Reader) (*repository.Event, error) {
+	for _, event := range []*repository.Event{Event, Event2, RecurringEvent} {
+		if userId == event.UserId && eventId == event.Id.Hex() {
+			if _, err := io.ReadAll(content); err != nil {
+				return nil, err
+			}
+			attachment.Id, attachment.CreatedAt = primitive.NewObjectID(), time.Now().UTC()
+			updated := copyEvent(event)
+			updated.Attachments = append(updated.Attachments, *attachment)
+			updated.Version++
+			return updated, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) OpenAttachment(_ context.Context, attachmentId string) (io.ReadCloser, error) {
+	if attachmentId == Event2Attachment.Id.Hex() {
+		return io.NopCloser(bytes.NewReader(AttachmentContent)), nil
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *calendarRepository) DeleteAttachment(_ context.Context, eventId string, userId uint64,
+	attachmentId string) (*repository.Event, error) {
+	for _, event := range []*repository.Event{Event, Event2, RecurringEvent} {
+		if userId == event.UserId && eventId == event.Id.Hex() && event.FindAttachment(attachmentId) != nil {
+			updated := copyEvent(event)
+			updated.Attachments = nil
+			for _, attachment := range event.Attachments {
+				if attachment.Id.Hex() != attachmentId {
+					updated.Attachments = append(updated.Attachments, attachment)
+				}
+			}
+			updated.Version++
+			return updated, nil
+		}
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
 func (c *calendarRepository) ServiceStatus(_ context.Context) error {
 	return nil
 }
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/rrule"
+	"github.com/3n0ugh/kalenderium/internal/validator"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxOccurrences limits the expanded occurrences of a recurring event in one window
+const MaxOccurrences = 1000
+
+var ErrNotOccurrence = errors.New("recurrence id is not an occurrence of the event")
+
+// Recurrence holds the RFC 5545 recurrence properties of a recurring event. The
+// overridden instances are stored as separate events keyed by the recurrence id.
+type Recurrence struct {
+	RRule   string      `json:"rrule" bson:"rrule"`
+	ExDates []time.Time `json:"exdates,omitempty" bson:"exdates,omitempty"`
+	RDates  []time.Time `json:"rdates,omitempty" bson:"rdates,omitempty"`
+	// End of the last occurrence, nil if the event repeats forever
+	Last *time.Time `json:"-" bson:"last,omitempty"`
+}
+
+type RecurrenceScope int
+
+const (
+	AllOccurrences RecurrenceScope = iota
+	ThisOccurrence
+	ThisAndFollowing
+)
+
+// Target selects the occurrences of a recurring event which a change applies to
+type Target struct {
+	Scope        RecurrenceScope `json:"scope"`
+	RecurrenceId time.Time       `json:"recurrence_id,omitempty"`
+}
+
+// ValidateRecurrence rules the recurrence validation
+func ValidateRecurrence(v *validator.Validator, recurrence *Recurrence) {
+	if recurrence == nil {
+		return
+	}
+
+	_, err := rrule.Parse(recurrence.RRule)
+	v.Check(err == nil, "recurrence", "must have a valid RRULE")
+	v.Check(len(recurrence.ExDates) <= MaxOccurrences, "recurrence", "must not have more than 1000 EXDATE")
+	v.Check(len(recurrence.RDates) <= MaxOccurrences, "recurrence", "must not have more than 1000 RDATE")
+}
+
+// IsRecurring returns true if the event is the master of a recurring event
+func (e *Event) IsRecurring() bool {
+	return e.Recurrence != nil
+}
+
+// SetRecurrenceEnd computes the end of the last occurrence which the queries use
+func (e *Event) SetRecurrenceEnd() error {
+	if e.Recurrence == nil {
+		return nil
+	}
+
+	rule, err := rrule.Parse(e.Recurrence.RRule)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse rrule")
+	}
+
+	last, ok := rule.Last(e.Start.In(e.Location()))
+	if !ok {
+		e.Recurrence.Last = nil
+		return nil
+	}
+	for _, rdate := range e.Recurrence.RDates {
+		if rdate.After(last) {
+			last = rdate
+		}
+	}
+
+	end := last.Add(e.End.Sub(e.Start))
+	e.Recurrence.Last = &end
+	return nil
+}
+
+// occurrenceStarts returns the starts of the occurrences in [after, before), including
+// the RDATEs and excluding the EXDATEs.
+func (e *Event) occurrenceStarts(after, before time.Time) ([]time.Time, error) {
+	rule, err := rrule.Parse(e.Recurrence.RRule)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse rrule")
+	}
+
+	// The occurrences keep the wall-clock time of the start in the time zone of the event
+	starts := rule.Between(e.Start.In(e.Location()), after, before)
+	for _, rdate := range e.Recurrence.RDates {
+		if !rdate.Before(after) && rdate.Before(before) && !containsTime(starts, rdate) {
+			starts = append(starts, rdate)
+		}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	var occurrences []time.Time
+	for _, start := range starts {
+		if !containsTime(e.Recurrence.ExDates, start) {
+			occurrences = append(occurrences, start)
+		}
+	}
+	return occurrences, nil
+}
+
+// HasOccurrence returns true if the recurring event has an occurrence at the recurrence id
+func (e *Event) HasOccurrence(recurrenceId time.Time) bool {
+	if e.Recurrence == nil {
+		return false
+	}
+	starts, err := e.occurrenceStarts(recurrenceId, recurrenceId.Add(time.Nanosecond))
+	return err == nil && len(starts) == 1
+}
+
+// Occurrence returns the unmodified instance of the recurring event at the recurrence id. It is
+// not stored, so it has no id of its own, and it is identified by the recurring event id and the
+// recurrence id.
+func (e *Event) Occurrence(recurrenceId time.Time) Event {
+	id, rid := e.Id, recurrenceId
+	occurrence := *e
+	occurrence.Id, occurrence.Recurrence = primitive.NilObjectID, nil
+	occurrence.RecurringEventId = &id
+	occurrence.RecurrenceId = &rid
+	occurrence.Start = recurrenceId
+	occurrence.End = recurrenceId.Add(e.End.Sub(e.Start))
+	return occurrence
+}
+
+// Expand returns the occurrences of the recurring event which overlap the [from, to) window.
+// The occurrences which have an overridden instance are replaced by the instance.
+func (e *Event) Expand(overrides []Event, from, to time.Time) ([]Event, error) {
+	duration := e.End.Sub(e.Start)
+
+	// Occurrences started before the window may still be going on in the window
+	after := e.Start
+	if !from.IsZero() {
+		after = from.Add(-duration)
+	}
+
+	starts, err := e.occurrenceStarts(after, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(starts) > MaxOccurrences {
+		starts = starts[:MaxOccurrences]
+	}
+
+	var occurrences []Event
+	for _, start := range starts {
+		if start.Before(from) && !start.Add(duration).After(from) {
+			continue
+		}
+		if FindOverride(overrides, start) == nil {
+			occurrences = append(occurrences, e.Occurrence(start))
+		}
+	}
+
+	for _, override := range overrides {
+		if !override.Start.Before(to) {
+			continue
+		}
+		if override.Start.Before(from) && !override.End.After(from) {
+			continue
+		}
+		occurrences = append(occurrences, override)
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Start.Before(occurrences[j].Start) })
+	return occurrences, nil
+}
+
+// PageOccurrences gets a page of the expanded events of a window, and returns the next page
+// token. The events are ordered by start, id and recurrence id, the occurrences of a recurring
+// event are ordered by the id of the recurring event.
+func PageOccurrences(events []Event, page Page) ([]Event, string, error) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return newPageCursor(events[i]).before(newPageCursor(events[j]))
+	})
+
+	if page.Token != "" {
+		cursor, err := decodePageToken(page.Token)
+		if err != nil {
+			return nil, "", err
+		}
+		i := sort.Search(len(events), func(i int) bool { return cursor.before(newPageCursor(events[i])) })
+		events = events[i:]
+	}
+
+	var nextPageToken string
+	if len(events) > page.Size {
+		events = events[:page.Size]
+		nextPageToken = encodePageToken(events[len(events)-1])
+	}
+	return events, nextPageToken, nil
+}
+
+// SplitRecurrence ends the recurring event before the recurrence id, and returns the
+// recurrence of the following occurrences.
+func (e *Event) SplitRecurrence(recurrenceId time.Time) (*Recurrence, error) {
+	rule, err := rrule.Parse(e.Recurrence.RRule)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse rrule")
+	}
+	following := *rule
+
+	if rule.Count > 0 {
+		before := len(rule.Between(e.Start.In(e.Location()), e.Start, recurrenceId))
+		if before >= rule.Count {
+			return nil, ErrNotOccurrence
+		}
+		rule.Count = before
+		following.Count -= before
+	} else {
+		rule.Until = recurrenceId.Add(-time.Second).UTC()
+	}
+
+	var rest Recurrence
+	rest.RRule = following.String()
+	e.Recurrence.RRule = rule.String()
+	e.Recurrence.ExDates, rest.ExDates = splitTimes(e.Recurrence.ExDates, recurrenceId)
+	e.Recurrence.RDates, rest.RDates = splitTimes(e.Recurrence.RDates, recurrenceId)
+
+	return &rest, nil
+}
+
+// FindOverride returns the overridden instance of the occurrence at the recurrence id
+func FindOverride(overrides []Event, recurrenceId time.Time) *Event {
+	for i, override := range overrides {
+		if override.RecurrenceId != nil && override.RecurrenceId.Equal(recurrenceId) {
+			return &overrides[i]
+		}
+	}
+	return nil
+}
+
+// splitTimes splits the times into the ones before the pivot and the rest
+func splitTimes(times []time.Time, pivot time.Time) ([]time.Time, []time.Time) {
+	var before, rest []time.Time
+	for _, t := range times {
+		if t.Before(pivot) {
+			before = append(before, t)
+		} else {
+			rest = append(rest, t)
+		}
+	}
+	return before, rest
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, other := range times {
+		if other.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/validator"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// MaxReminders limits the reminders of an event
+	MaxReminders = 5
+	// MaxReminderMinutes is the longest time a reminder can be sent before the event, 4 weeks
+	MaxReminderMinutes = 4 * 7 * 24 * 60
+
+	// finishedJobTTL is the time in seconds the finished reminder jobs are kept for
+	finishedJobTTL = 7 * 24 * 60 * 60
+)
+
+// The methods which the reminders can be sent by
+const (
+	ReminderEmail   = "email"
+	ReminderWebhook = "webhook"
+)
+
+var ReminderMethods = []string{ReminderEmail, ReminderWebhook}
+
+// Reminder notifies the user the given minutes before the start of the event
+type Reminder struct {
+	Minutes int    `json:"minutes" bson:"minutes"`
+	Method  string `json:"method" bson:"method"`
+}
+
+// ValidateReminders rules the reminders validation
+func ValidateReminders(v *validator.Validator, reminders []Reminder) {
+	v.Check(len(reminders) <= MaxReminders, "reminders", "must not have more than 5 reminders")
+
+	seen := make(map[Reminder]bool, len(reminders))
+	for _, reminder := range reminders {
+		v.Check(reminder.Minutes >= 0 && reminder.Minutes <= MaxReminderMinutes, "reminders",
+			"must be between 0 and 40320 minutes before the event")
+		v.Check(validator.In(reminder.Method, ReminderMethods...), "reminders", "must be sent by email or webhook")
+		v.Check(!seen[reminder], "reminders", "must not have duplicates")
+		seen[reminder] = true
+	}
+}
+
+// HasReminder returns true if the event has the reminder
+func (e *Event) HasReminder(reminder Reminder) bool {
+	for _, r := range e.Reminders {
+		if r == reminder {
+			return true
+		}
+	}
+	return false
+}
+
+// The statuses of a reminder job
+const (
+	ReminderPending   = "pending"
+	ReminderSending   = "sending"
+	ReminderSent      = "sent"
+	ReminderFailed    = "failed"
+	ReminderCancelled = "cancelled"
+)
+
+// ReminderJob is a reminder of an event or an occurrence which is due to be sent. The jobs
+// are unique by the event, the occurrence, the start and the reminder, so planning the same
+// reminder again does not create another job.
+type ReminderJob struct {
+	Id     primitive.ObjectID `bson:"_id,omitempty"`
+	UserId uint64             `bson:"user_id"`
+	// EventId is the single event, the overridden instance or the master of the occurrence
+	EventId primitive.ObjectID `bson:"event_id"`
+	// RecurrenceId is set if the job is for an unmodified occurrence of a recurring event
+	RecurrenceId *time.Time `bson:"recurrence_id"`
+	// Start of the event when the job is planned, the job is cancelled if the event is moved
+	Start    time.Time `bson:"start"`
+	Reminder Reminder  `bson:"reminder"`
+
+	// DueAt is the time the job is sent or retried at
+	DueAt  time.Time `bson:"due_at"`
+	Status string    `bson:"status"`
+	// Owner is the scheduler which claimed the job, the claim expires at LeaseUntil
+	Owner      string     `bson:"owner,omitempty"`
+	LeaseUntil *time.Time `bson:"lease_until,omitempty"`
+	Attempts   int        `bson:"attempts"`
+	Err        string     `bson:"err,omitempty"`
+	FinishedAt *time.Time `bson:"finished_at,omitempty"`
+}
+
+// ListRemindedEvents -> Gets the events of all users which have reminders and overlap the
+// window. The overridden instances are not listed.
+func (c *calendarRepository) ListRemindedEvents(ctx context.Context, from, to time.Time) ([]Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := bson.M{"$and": bson.A{
+		bson.M{"recurring_event_id": nil, "deleted_at": nil, "reminders.0": bson.M{"$exists": true}},
+		windowCondition(EventFilter{From: from, To: to}),
+	}}
+
+	cursor, err := c.collection.Find(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get reminded events from database")
+	}
+
+	var events []Event
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, errors.Wrap(err, "failed to get reminded events from database")
+	}
+	return events, nil
+}
+
+// CreateReminderJobs -> Adds the reminder jobs which are not planned yet
+func (c *calendarRepository) CreateReminderJobs(ctx context.Context, jobs []ReminderJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	models := make([]mongo.WriteModel, len(jobs))
+	for i, job := range jobs {
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{
+				"event_id":      job.EventId,
+				"recurrence_id": job.RecurrenceId,
+				"start":         job.Start,
+				"reminder":      job.Reminder,
+			}).
+			SetUpdate(bson.M{"$setOnInsert": job}).
+			SetUpsert(true)
+	}
+
+	// The schedulers plan the same jobs concurrently, the losers of the race get duplicate
+	// key errors which mean the job is already planned
+	_, err := c.reminders.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return errors.Wrap(err, "failed to create reminder jobs")
+	}
+	return nil
+}
+
+// ClaimReminderJob -> Atomically takes the earliest due job for the owner until the lease
+// expires. The jobs of the owners whose lease has expired are claimed again. It returns
+// ErrRecordNotFound if no job is due.
+func (c *calendarRepository) ClaimReminderJob(ctx context.Context, owner string, now time.Time,
+	lease time.Duration) (*ReminderJob, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	query := bson.M{"$or": bson.A{
+		bson.M{"status": ReminderPending, "due_at": bson.M{"$lte": now}},
+		bson.M{"status": ReminderSending, "lease_until": bson.M{"$lt": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{"status": ReminderSending, "owner": owner, "lease_until": now.Add(lease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "due_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job ReminderJob
+	err := c.reminders.FindOneAndUpdate(ctx, query, update, opts).Decode(&job)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to claim reminder job")
+	}
+	return &job, nil
+}
+
+// FinishReminderJob -> Saves the status, the due time and the error of the claimed job. It
+// returns ErrRecordNotFound if the lease of the owner has expired and the job is claimed again.
+func (c *calendarRepository) FinishReminderJob(ctx context.Context, job *ReminderJob) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	set := bson.M{"status": job.Status, "due_at": job.DueAt, "err": job.Err}
+	if job.Status != ReminderPending {
+		now := time.Now()
+		job.FinishedAt = &now
+		set["finished_at"] = now
+	}
+
+	res, err := c.reminders.UpdateOne(ctx,
+		bson.M{"_id": job.Id, "owner": job.Owner, "status": ReminderSending},
+		bson.M{"$set": set, "$unset": bson.M{"lease_until": ""}})
+	if err != nil {
+		return errors.Wrap(err, "failed to finish reminder job")
+	}
+	if res.MatchedCount == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
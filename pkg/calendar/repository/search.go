@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/validator"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MaxSearchQuery limits the length of a search query in bytes
+const MaxSearchQuery = 256
+
+// The name of the text index over the names and the details of the events, a match in the name
+// weighs more than a match in the details
+const (
+	textIndexName  = "event_text"
+	nameWeight     = 3
+	detailsWeight  = 1
+	textScoreField = "score"
+)
+
+// textIndex is the index which the search queries rely on
+var textIndex = mongo.IndexModel{
+	Keys: bson.D{{Key: "name", Value: "text"}, {Key: "details", Value: "text"}},
+	Options: options.Index().SetName(textIndexName).
+		SetWeights(bson.M{"name": nameWeight, "details": detailsWeight}),
+}
+
+// searchCursor is the content of the opaque search page token. The results are ranked by the
+// relevance which can not be a cursor, so the token holds the number of the skipped events.
+type searchCursor struct {
+	Offset int `json:"o"`
+}
+
+// encodeSearchToken creates the page token which skips the given number of events
+func encodeSearchToken(offset int) string {
+	js, _ := json.Marshal(searchCursor{Offset: offset})
+	return base64.RawURLEncoding.EncodeToString(js)
+}
+
+// decodeSearchToken gets the number of the skipped events from the page token
+func decodeSearchToken(token string) (int, error) {
+	var cursor searchCursor
+
+	js, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, ErrInvalidPageToken
+	}
+	if err = json.Unmarshal(js, &cursor); err != nil || cursor.Offset <= 0 {
+		return 0, ErrInvalidPageToken
+	}
+	return cursor.Offset, nil
+}
+
+// ValidateSearchQuery rules the search query validation
+func ValidateSearchQuery(v *validator.Validator, query string) {
+	v.Check(query != "", "q", "must be provided")
+	v.Check(len(query) <= MaxSearchQuery, "q", "must not be more than 256 bytes long")
+}
+
+// SearchEvents -> Gets a page of the events which the given userId organizes or is invited to and
+// whose name or details match the query, ranked by the relevance, and returns the next page token.
+// The overridden instances are matched on their own. If the filter has a time window, only the
+// events overlapping the window are returned, and if it has tags, only the events having all of
+// them. The next page token is empty on the last page.
+func (c *calendarRepository) SearchEvents(ctx context.Context, userId uint64, query string, filter EventFilter,
+	page Page) ([]Event, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	offset := 0
+	if page.Token != "" {
+		var err error
+		if offset, err = decodeSearchToken(page.Token); err != nil {
+			return nil, "", err
+		}
+	}
+
+	conditions := bson.A{bson.M{"$text": bson.M{"$search": query}, "deleted_at": nil}, userCondition(userId),
+		windowCondition(filter), tagCondition(filter)}
+
+	// One more event is fetched to know whether there is a next page, the events with the same
+	// score are ordered by id to keep the pages stable
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{textScoreField: score}).
+		SetSort(bson.D{{Key: textScoreField, Value: score}, {Key: "_id", Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(page.Size) + 1)
+
+	cursor, err := c.collection.Find(ctx, bson.M{"$and": conditions}, opts)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to search events in database")
+	}
+	if cursor.Err() != nil {
+		return nil, "", errors.Wrap(cursor.Err(), "failed to search events in database")
+	}
+
+	var events []Event
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, "", errors.Wrap(err, "failed to search events in database")
+	}
+
+	var nextPageToken string
+	if len(events) > page.Size {
+		events = events[:page.Size]
+		nextPageToken = encodeSearchToken(offset + page.Size)
+	}
+
+	return events, nextPageToken, nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/validator"
+)
+
+// MaxSlotWindow limits the time window which the meeting slots are searched in
+const MaxSlotWindow = 31 * 24 * time.Hour
+
+// MaxSlots limits the candidate slots of a search, DefaultSlots are given if the limit is not set
+const (
+	MaxSlots     = 50
+	DefaultSlots = 10
+)
+
+// SlotStep is the interval of the candidate slot starts
+const SlotStep = 15 * time.Minute
+
+// hourFormat is the format of the working hours
+const hourFormat = "15:04"
+
+// WorkingHours limit the meeting slots to the hours of the days in the time zone of every
+// participant
+type WorkingHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Days are the weekdays from 0 (Sunday) to 6 (Saturday), Monday to Friday if empty
+	Days []int `json:"days,omitempty"`
+}
+
+// SlotQuery searches the meeting slots of the duration (in minutes) which all the users are free
+type SlotQuery struct {
+	UserIds      []uint64      `json:"user_ids"`
+	Duration     int           `json:"duration"`
+	From         time.Time     `json:"from"`
+	To           time.Time     `json:"to"`
+	WorkingHours *WorkingHours `json:"working_hours,omitempty"`
+	MaxResults   int           `json:"max_results,omitempty"`
+}
+
+// Participant is a user of the slot search with the busy intervals and the time zone of the user
+type Participant struct {
+	UserId   uint64
+	Busy     []Interval
+	Location *time.Location
+}
+
+// ValidateSlotQuery rules the slot query validation
+func ValidateSlotQuery(v *validator.Validator, query SlotQuery) {
+	v.Check(len(query.UserIds) <= MaxFreeBusyUsers, "user_ids", "must not have more than 50 users")
+	for _, userId := range query.UserIds {
+		v.Check(userId != 0, "user_ids", "must not have zero ids")
+	}
+
+	v.Check(query.Duration > 0 && query.Duration <= 24*60, "duration", "must be between 1 and 1440 minutes")
+	v.Check(query.MaxResults >= 0 && query.MaxResults <= MaxSlots, "max_results", "must be between 0 and 50")
+
+	v.Check(!query.From.IsZero(), "from", "must be provided")
+	v.Check(!query.To.IsZero(), "to", "must be provided")
+	if !query.From.IsZero() && !query.To.IsZero() {
+		v.Check(query.From.Before(query.To), "to", "must be after from")
+		v.Check(query.To.Sub(query.From) <= MaxSlotWindow, "to", "must not be more than 31 days after from")
+	}
+
+	if hours := query.WorkingHours; hours != nil {
+		start, startErr := time.Parse(hourFormat, hours.Start)
+		end, endErr := time.Parse(hourFormat, hours.End)
+		v.Check(startErr == nil, "working_hours", "must have a start as 15:04")
+		v.Check(endErr == nil, "working_hours", "must have an end as 15:04")
+		if startErr == nil && endErr == nil {
+			v.Check(start.Before(end), "working_hours", "must end after the start")
+		}
+		for _, day := range hours.Days {
+			v.Check(day >= 0 && day <= 6, "working_hours", "must have days between 0 and 6")
+		}
+	}
+}
+
+// FindSlots returns the slots of the query which all the participants are free in, ranked by
+// how close they are to the middle of the working hours (or to the noon) of the participants in
+// their time zones. The slots do not overlap each other.
+func FindSlots(participants []Participant, query SlotQuery) []Interval {
+	var busy []Interval
+	for _, participant := range participants {
+		busy = append(busy, participant.Busy...)
+	}
+	busy = MergeIntervals(busy)
+
+	duration := time.Duration(query.Duration) * time.Minute
+	type candidate struct {
+		slot  Interval
+		score time.Duration
+	}
+	var candidates []candidate
+	next := 0
+	for start := query.From.UTC().Truncate(SlotStep); !start.Add(duration).After(query.To); start = start.Add(SlotStep) {
+		if start.Before(query.From) {
+			continue
+		}
+		slot := Interval{Start: start, End: start.Add(duration)}
+
+		// The busy intervals which end before the slot do not overlap the next slots either
+		for next < len(busy) && !busy[next].End.After(slot.Start) {
+			next++
+		}
+		if next < len(busy) && busy[next].Start.Before(slot.End) {
+			continue
+		}
+
+		score, ok := scoreSlot(participants, slot, query.WorkingHours)
+		if ok {
+			candidates = append(candidates, candidate{slot: slot, score: score})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score < candidates[j].score })
+
+	limit := query.MaxResults
+	if limit == 0 {
+		limit = DefaultSlots
+	}
+	slots := []Interval{}
+	for _, c := range candidates {
+		if len(slots) == limit {
+			break
+		}
+		if !overlaps(slots, c.slot) {
+			slots = append(slots, c.slot)
+		}
+	}
+	return slots
+}
+
+// scoreSlot sums the distances of the middle of the slot to the middle of the day of the
+// participants. The slot is not ok if it is out of the working hours of a participant.
+func scoreSlot(participants []Participant, slot Interval, hours *WorkingHours) (time.Duration, bool) {
+	middle := slot.Start.Add(slot.End.Sub(slot.Start) / 2)
+
+	var score time.Duration
+	for _, participant := range participants {
+		local := slot.Start.In(participant.Location)
+		dayStart, dayEnd := localHours(local, hours)
+		if hours != nil {
+			if !workingDay(local.Weekday(), hours.Days) || slot.Start.Before(dayStart) || slot.End.After(dayEnd) {
+				return 0, false
+			}
+		}
+
+		distance := middle.Sub(dayStart.Add(dayEnd.Sub(dayStart) / 2))
+		if distance < 0 {
+			distance = -distance
+		}
+		score += distance
+	}
+	return score, true
+}
+
+// localHours returns the working hours on the local day, or the whole day without working hours
+func localHours(local time.Time, hours *WorkingHours) (time.Time, time.Time) {
+	year, month, day := local.Date()
+	if hours == nil {
+		return time.Date(year, month, day, 0, 0, 0, 0, local.Location()),
+			time.Date(year, month, day+1, 0, 0, 0, 0, local.Location())
+	}
+
+	start, _ := time.Parse(hourFormat, hours.Start)
+	end, _ := time.Parse(hourFormat, hours.End)
+	return time.Date(year, month, day, start.Hour(), start.Minute(), 0, 0, local.Location()),
+		time.Date(year, month, day, end.Hour(), end.Minute(), 0, 0, local.Location())
+}
+
+// workingDay reports whether the weekday is in the days, Monday to Friday if the days are empty
+func workingDay(weekday time.Weekday, days []int) bool {
+	if len(days) == 0 {
+		return weekday != time.Saturday && weekday != time.Sunday
+	}
+	for _, day := range days {
+		if time.Weekday(day) == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether the slot overlaps any of the slots
+func overlaps(slots []Interval, slot Interval) bool {
+	for _, s := range slots {
+		if s.Start.Before(slot.End) && slot.Start.Before(s.End) {
+			return true
+		}
+	}
+	return false
+}
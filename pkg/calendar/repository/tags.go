@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/internal/validator"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MaxTags limits the tags of an event, MaxTagLength limits the length of a tag in bytes
+const (
+	MaxTags      = 20
+	MaxTagLength = 32
+)
+
+// TagCount is a tag of the user with the number of the events which have it
+type TagCount struct {
+	Tag   string `json:"tag" bson:"_id"`
+	Count int    `json:"count" bson:"count"`
+}
+
+// NormalizeTags trims and lower-cases the tags and drops the duplicates, so the tags which
+// differ only in case are the same tag
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !validator.In(tag, normalized...) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// ValidateTags rules the tag validation
+func ValidateTags(v *validator.Validator, tags []string) {
+	v.Check(len(tags) <= MaxTags, "tags", "must not have more than 20 tags")
+	v.Check(validator.Unique(tags), "tags", "must not have duplicates")
+	for _, tag := range tags {
+		v.Check(strings.TrimSpace(tag) != "", "tags", "must not have empty tags")
+		v.Check(len(tag) <= MaxTagLength, "tags", "must not have tags more than 32 bytes long")
+		v.Check(!strings.ContainsAny(tag, ",\r\n"), "tags", "must not have commas or line breaks")
+	}
+}
+
+// tagCondition matches the events which have all the tags of the filter
+func tagCondition(filter EventFilter) bson.M {
+	if len(filter.Tags) == 0 {
+		return bson.M{}
+	}
+	return bson.M{"tags": bson.M{"$all": filter.Tags}}
+}
+
+// ListTags -> Gets the tags of the events which the given userId organizes with the number of
+// the events which have them, the most used tag is the first. The overridden instances of the
+// recurring events are not counted.
+func (c *calendarRepository) ListTags(ctx context.Context, userId uint64) ([]TagCount, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"user_id": userId, "recurring_event_id": nil, "deleted_at": nil,
+			"tags.0": bson.M{"$exists": true}}},
+		bson.M{"$unwind": "$tags"},
+		bson.M{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+
+	cursor, err := c.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tags from database")
+	}
+
+	tags := []TagCount{}
+	if err = cursor.All(ctx, &tags); err != nil {
+		return nil, errors.Wrap(err, "failed to get tags from database")
+	}
+	return tags, nil
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// trashed matches the events in the trash. The restored events have no deleted_at at all, so
+// the partial indexes of the trash can be used.
+var trashed = bson.M{"$exists": true}
+
+// trashIndexes are the indexes which the trash listing and the purge rely on, only the trashed
+// events are indexed
+var trashIndexes = []mongo.IndexModel{
+	{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "deleted_at", Value: -1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"deleted_at": trashed}),
+	},
+	{
+		Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"deleted_at": trashed}),
+	},
+}
+
+// encodeTrashToken creates the page token which points to the given trashed event
+func encodeTrashToken(event Event) string {
+	return encodePageToken(Event{Id: event.Id, Start: *event.DeletedAt})
+}
+
+// DeleteEvent -> Moves the event according to given userId and eventId to the trash, and returns
+// the event as it was before. The overridden instances of a recurring event go with it and share
+// its deleted_at, so they are restored together. If the version is not zero, the event is deleted
+// only if it is still at that version.
+func (c *calendarRepository) DeleteEvent(ctx context.Context, eventId string, userId uint64, version int64) (*Event, error) {
+	id, err := primitive.ObjectIDFromHex(eventId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert eventId to ObjectID")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	// The database keeps the times in milliseconds, the overrides are matched by the same time
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	set := bson.M{"$set": bson.M{"deleted_at": now}, "$inc": incVersion}
+
+	var event Event
+	filter := bson.M{"user_id": userId, "_id": id, "deleted_at": nil}
+	err = c.collection.FindOneAndUpdate(ctx, withVersion(filter, version), set).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, c.notMatched(ctx, filter, version)
+		}
+		return nil, errors.Wrap(err, "failed to delete event")
+	}
+
+	_, err = c.collection.UpdateMany(ctx, bson.M{"user_id": userId, "recurring_event_id": id, "deleted_at": nil}, set)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to delete overrides")
+	}
+	return &event, nil
+}
+
+// ListTrash -> Gets a page of the trashed events of the given userId, the last deleted event is
+// the first, and returns the next page token. The overridden instances are not listed, they are
+// restored and purged with their recurring events.
+func (c *calendarRepository) ListTrash(ctx context.Context, userId uint64, page Page) ([]Event, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	conditions := bson.A{bson.M{"user_id": userId, "deleted_at": trashed, "recurring_event_id": nil}}
+	if page.Token != "" {
+		cursor, err := decodePageToken(page.Token)
+		if err != nil {
+			return nil, "", err
+		}
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"deleted_at": bson.M{"$lt": cursor.Start}},
+			bson.M{"deleted_at": cursor.Start, "_id": bson.M{"$gt": cursor.Id}},
+		}})
+	}
+
+	// One more event is fetched to know whether there is a next page
+	opts := options.Find().
+		SetSort(bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(page.Size) + 1)
+
+	cursor, err := c.collection.Find(ctx, bson.M{"$and": conditions}, opts)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to get trash from database")
+	}
+
+	var events []Event
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, "", errors.Wrap(err, "failed to get trash from database")
+	}
+
+	var nextPageToken string
+	if len(events) > page.Size {
+		events = events[:page.Size]
+		nextPageToken = encodeTrashToken(events[len(events)-1])
+	}
+	return events, nextPageToken, nil
+}
+
+// RestoreEvent -> Takes the trashed event according to given userId and eventId out of the
+// trash together with the overridden instances which are deleted with it, and returns it. The
+// events of the deleted calendars are restored to the default calendar of the user.
+func (c *calendarRepository) RestoreEvent(ctx context.Context, eventId string, userId uint64) (*Event, error) {
+	id, err := primitive.ObjectIDFromHex(eventId)
+	if err != nil {
+		return nil, ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	filter := bson.M{"user_id": userId, "_id": id, "deleted_at": trashed, "recurring_event_id": nil}
+	restore := bson.M{"$unset": bson.M{"deleted_at": ""}, "$inc": incVersion}
+
+	var trashedEvent Event
+	err = c.collection.FindOne(ctx, filter,
+		options.FindOne().SetProjection(bson.M{"calendar_id": 1, "deleted_at": 1})).Decode(&trashedEvent)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get event from database")
+	}
+	err = c.calendars.FindOne(ctx, bson.M{"_id": trashedEvent.CalendarId, "user_id": userId}).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		calendar, err := c.DefaultCalendar(ctx, userId)
+		if err != nil {
+			return nil, err
+		}
+		restore["$set"] = bson.M{"calendar_id": calendar.Id}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get calendar from database")
+	}
+
+	// The overridden instances which are deleted with the event have its deleted_at
+	filter["deleted_at"] = trashedEvent.DeletedAt
+
+	var event Event
+	err = c.collection.FindOneAndUpdate(ctx, filter, restore,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to restore event")
+	}
+
+	_, err = c.collection.UpdateMany(ctx,
+		bson.M{"user_id": userId, "recurring_event_id": id, "deleted_at": trashedEvent.DeletedAt}, restore)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to restore overrides")
+	}
+	return &event, nil
+}
+
+// PurgeEvent -> Permanently deletes the trashed event according to given userId and eventId,
+// together with all the overridden instances of a recurring event and the contents of its
+// attachments
+func (c *calendarRepository) PurgeEvent(ctx context.Context, eventId string, userId uint64) error {
+	id, err := primitive.ObjectIDFromHex(eventId)
+	if err != nil {
+		return ErrRecordNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var event Event
+	err = c.collection.FindOneAndDelete(ctx,
+		bson.M{"user_id": userId, "_id": id, "deleted_at": trashed, "recurring_event_id": nil}).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrRecordNotFound
+		}
+		return errors.Wrap(err, "failed to purge event")
+	}
+
+	// The overridden instances which are trashed before the recurring event are purged too
+	_, err = c.collection.DeleteMany(ctx, bson.M{"user_id": userId, "recurring_event_id": id})
+	if err != nil {
+		return errors.Wrap(err, "failed to purge overrides")
+	}
+	return c.deleteBlobs(ctx, event.Attachments)
+}
+
+// PurgeTrash -> Permanently deletes the events of all the users which are trashed before the
+// given time together with the contents of their attachments, and returns the number of the
+// deleted events
+func (c *calendarRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// The attachments are read first, only the recurring events and the single events have them
+	cursor, err := c.collection.Find(ctx,
+		bson.M{"deleted_at": bson.M{"$lt": before}, "recurring_event_id": nil, "attachments.0": bson.M{"$exists": true}},
+		options.Find().SetProjection(bson.M{"attachments": 1}))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get trash from database")
+	}
+	var attached []Event
+	if err = cursor.All(ctx, &attached); err != nil {
+		return 0, errors.Wrap(err, "failed to get trash from database")
+	}
+
+	res, err := c.collection.DeleteMany(ctx, bson.M{"deleted_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to purge trash")
+	}
+	if len(attached) == 0 {
+		return res.DeletedCount, nil
+	}
+
+	// The events which are restored in between are kept with their attachments
+	ids := make([]primitive.ObjectID, len(attached))
+	for i, event := range attached {
+		ids[i] = event.Id
+	}
+	cursor, err = c.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return res.DeletedCount, errors.Wrap(err, "failed to get events from database")
+	}
+	var kept []Event
+	if err = cursor.All(ctx, &kept); err != nil {
+		return res.DeletedCount, errors.Wrap(err, "failed to get events from database")
+	}
+	restored := make(map[primitive.ObjectID]bool, len(kept))
+	for _, event := range kept {
+		restored[event.Id] = true
+	}
+
+	var attachments []Attachment
+	for _, event := range attached {
+		if !restored[event.Id] {
+			attachments = append(attachments, event.Attachments...)
+		}
+	}
+	return res.DeletedCount, c.deleteBlobs(ctx, attachments)
+}
@@ -0,0 +1,24 @@
+package repository
+
+import "github.com/pkg/errors"
+
+// ErrValidation marks the errors of the requests which do not pass the validation
+var ErrValidation = errors.New("validation failed")
+
+// ValidationError rejects the request with the message of its failed checks
+type ValidationError struct {
+	Message string
+}
+
+// NewValidationError creates the validation error with the message
+func NewValidationError(message string) error {
+	return &ValidationError{Message: message}
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrVersionMismatch = errors.New("the event has been changed since the given version")
+
+// incVersion increases the version of the changed events, every write to an event goes with it
+var incVersion = bson.M{"version": 1}
+
+// withVersion narrows the filter down to the expected version of the event, zero matches any version
+func withVersion(filter bson.M, version int64) bson.M {
+	if version == 0 {
+		return filter
+	}
+	versioned := bson.M{"version": version}
+	for key, value := range filter {
+		versioned[key] = value
+	}
+	return versioned
+}
+
+// notMatched returns the error of a write which has matched no event with the filter. If the
+// event is there with another version than the expected one, the write is stale.
+func (c *calendarRepository) notMatched(ctx context.Context, filter bson.M, version int64) error {
+	if version == 0 {
+		return ErrRecordNotFound
+	}
+
+	n, err := c.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return errors.Wrap(err, "failed to get event from database")
+	}
+	if n > 0 {
+		return ErrVersionMismatch
+	}
+	return ErrRecordNotFound
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The types of the event notifications
+const (
+	NotificationCreated = "created"
+	NotificationUpdated = "updated"
+	NotificationDeleted = "deleted"
+)
+
+// The codes of the change stream errors of the resume tokens which can not be resumed: the
+// malformed ones and the ones which are older than the oplog
+const (
+	codeChangeStreamFatalError  = 280
+	codeChangeStreamHistoryLost = 286
+)
+
+// Notification is a change of an event which is sent to the users watching the event. The
+// changes are the inserts into the history, so the token is the resume token of the insert.
+type Notification struct {
+	Token string `json:"token"`
+	Type  string `json:"type"`
+	// UserId is the user who made the change
+	UserId uint64 `json:"user_id"`
+	// Event is the event after the change, or before the change if it is deleted
+	Event     Event     `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewNotification creates the notification of the history entry with its resume token, the
+// restored events are created again and the reverted events are updated
+func NewNotification(entry HistoryEntry, token string) Notification {
+	notification := Notification{
+		Token:     token,
+		Type:      NotificationUpdated,
+		UserId:    entry.UserId,
+		Event:     entry.Event,
+		CreatedAt: entry.CreatedAt,
+	}
+	switch entry.Action {
+	case HistoryCreate, HistoryRestore:
+		notification.Type = NotificationCreated
+	case HistoryDelete:
+		notification.Type = NotificationDeleted
+	}
+	return notification
+}
+
+// Watchers returns the users who are notified of the changes of the event, the organizer and the
+// attendees who are users
+func (e Event) Watchers() []uint64 {
+	watchers := []uint64{e.UserId}
+	for _, attendee := range e.Attendees {
+		if attendee.UserId != 0 && attendee.UserId != e.UserId {
+			watchers = append(watchers, attendee.UserId)
+		}
+	}
+	return watchers
+}
+
+// EncodeResumeToken encodes the resume token of the change stream for the clients
+func EncodeResumeToken(token bson.Raw) string {
+	return base64.RawURLEncoding.EncodeToString(token)
+}
+
+// DecodeResumeToken gets the resume token of the change stream from the token of the client
+func DecodeResumeToken(token string) (bson.Raw, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	raw := bson.Raw(data)
+	if err = raw.Validate(); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	return raw, nil
+}
+
+// WatchChanges -> Sends the history entries of the events which the given userId organizes or
+// attends with their resume tokens until the context is done. The entries are read from the
+// change stream of the history, so the changes which any replica records are sent. The stream
+// starts after the given resume token, or from now without it.
+func (c *calendarRepository) WatchChanges(ctx context.Context, userId uint64, resumeToken string,
+	send func(HistoryEntry, string) error) error {
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType": "insert",
+		"$or": bson.A{
+			bson.M{"fullDocument.event.user_id": userId},
+			bson.M{"fullDocument.event.attendees.user_id": userId},
+		},
+	}}}}
+
+	opts := options.ChangeStream()
+	if resumeToken != "" {
+		token, err := DecodeResumeToken(resumeToken)
+		if err != nil {
+			return err
+		}
+		opts.SetResumeAfter(token)
+	}
+
+	stream, err := c.history.Watch(ctx, pipeline, opts)
+	if err != nil {
+		if isLostResumeToken(err) {
+			return ErrInvalidPageToken
+		}
+		return errors.Wrap(err, "failed to watch changes")
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var change struct {
+			FullDocument HistoryEntry `bson:"fullDocument"`
+		}
+		if err = stream.Decode(&change); err != nil {
+			return errors.Wrap(err, "failed to decode change")
+		}
+		if err = send(change.FullDocument, EncodeResumeToken(stream.ResumeToken())); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	if isLostResumeToken(stream.Err()) {
+		return ErrInvalidPageToken
+	}
+	return errors.Wrap(stream.Err(), "failed to watch changes")
+}
+
+// isLostResumeToken reports whether the change stream can not be resumed with the token
+func isLostResumeToken(err error) bool {
+	var cmdErr mongo.CommandError
+	if !errors.As(err, &cmdErr) {
+		return false
+	}
+	return cmdErr.Code == codeChangeStreamFatalError || cmdErr.Code == codeChangeStreamHistoryLost
+}
@@ -0,0 +1,110 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDecodeResumeToken(t *testing.T) {
+	raw, _ := bson.Marshal(bson.M{"_data": "8262A1"})
+
+	tests := map[string]struct {
+		token string
+		err   error
+	}{
+		"Change_Stream_Token": {token: repository.EncodeResumeToken(raw)},
+		"Not_Base64":          {token: "not a token", err: repository.ErrInvalidPageToken},
+		"Not_BSON":            {token: "aW52YWxpZA", err: repository.ErrInvalidPageToken},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			token, err := repository.DecodeResumeToken(tt.token)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Err -> Want: %v; Got: %v", tt.err, err)
+			}
+			if err == nil && !bytes.Equal(token, raw) {
+				t.Errorf("Token -> Want: %v; Got: %v", raw, token)
+			}
+		})
+	}
+}
+
+// TestCalendarRepository_WatchChanges records the changes with one repository and watches them
+// with another, like two replicas of the calendar service. The MongoDB must be a replica set.
+func TestCalendarRepository_WatchChanges(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	recorder, watcher := testRepository(t, t.TempDir()), testRepository(t, t.TempDir())
+
+	// A user of its own, so the other changes in the database are not watched
+	userId := uint64(time.Now().UnixNano())
+	event := repository.Event{Id: primitive.NewObjectID(), UserId: userId, Name: "Watched"}
+
+	type change struct {
+		entry repository.HistoryEntry
+		token string
+	}
+	watch := func(ctx context.Context, resumeToken string) (<-chan change, <-chan error) {
+		changes, done := make(chan change), make(chan error, 1)
+		go func() {
+			done <- watcher.WatchChanges(ctx, userId, resumeToken, func(entry repository.HistoryEntry, token string) error {
+				select {
+				case changes <- change{entry, token}:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		}()
+		return changes, done
+	}
+	receive := func(changes <-chan change, done <-chan error) change {
+		select {
+		case c := <-changes:
+			return c
+		case err := <-done:
+			t.Fatalf("the watch is ended: %v", err)
+		case <-ctx.Done():
+			t.Fatal("no change is received")
+		}
+		return change{}
+	}
+
+	watchCtx, stop := context.WithCancel(ctx)
+	changes, done := watch(watchCtx, "")
+	// The change stream is opened before the changes are recorded
+	time.Sleep(500 * time.Millisecond)
+
+	entries := []*repository.HistoryEntry{
+		{EventId: event.Id, UserId: userId, Action: repository.HistoryCreate, Event: event, CreatedAt: time.Now()},
+		{EventId: event.Id, UserId: userId, Action: repository.HistoryDelete, Event: event, CreatedAt: time.Now()},
+	}
+	for _, entry := range entries {
+		if err := recorder.AddHistory(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	created := receive(changes, done)
+	if created.entry.Id != entries[0].Id || created.token == "" {
+		t.Fatalf("expected the creation with a token, got %v", created)
+	}
+	if deleted := receive(changes, done); deleted.entry.Id != entries[1].Id {
+		t.Fatalf("expected the deletion, got %v", deleted)
+	}
+	stop()
+
+	// The watch which is resumed after the creation gets the deletion again
+	changes, done = watch(ctx, created.token)
+	if resumed := receive(changes, done); resumed.entry.Id != entries[1].Id {
+		t.Fatalf("expected the deletion after the resume, got %v", resumed)
+	}
+}
@@ -2,41 +2,411 @@ package calendar
 
 import (
 	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"time"
 )
 
 // CreateEventRequest -> CreateEvent endpoint's  input structures
 type CreateEventRequest struct {
-	Event repository.Event `json:"event"`
+	Event          repository.Event `json:"event"`
+	ConflictPolicy string           `json:"conflict_policy,omitempty"`
 }
 
 // CreateEventResponse -> CreateEvent endpoint's output structure
 type CreateEventResponse struct {
-	EventId string `json:"event_id,omitempty"`
-	Err     string `json:"err,omitempty"`
+	EventId   string             `json:"event_id,omitempty"`
+	Conflicts []repository.Event `json:"conflicts,omitempty"`
+	Err       string             `json:"err,omitempty"`
+}
+
+// BatchCreateEventsRequest -> BatchCreateEvents endpoint's  input structures
+type BatchCreateEventsRequest struct {
+	UserId uint64             `json:"user_id"`
+	Events []repository.Event `json:"events"`
+}
+
+// BatchCreateEventsResponse -> BatchCreateEvents endpoint's output structure
+type BatchCreateEventsResponse struct {
+	Results []repository.BatchResult `json:"results,omitempty"`
+	Err     string                   `json:"err,omitempty"`
 }
 
 // ListEventRequest -> ListEvent endpoint's  input structures
 type ListEventRequest struct {
-	UserId uint64 `json:"user_id"`
+	UserId uint64                 `json:"user_id"`
+	Filter repository.EventFilter `json:"filter"`
+	Page   repository.Page        `json:"page"`
 }
 
 // ListEventResponse -> ListEvent endpoint's output structure
 type ListEventResponse struct {
-	Events []repository.Event `json:"events,omitempty"`
-	Err    string             `json:"err,omitempty"`
+	Events        []repository.Event `json:"events,omitempty"`
+	NextPageToken string             `json:"next_page_token,omitempty"`
+	Err           string             `json:"err,omitempty"`
 }
 
-// DeleteEventRequest -> DeleteEvent endpoint's  input structures
-type DeleteEventRequest struct {
+// SearchEventsRequest -> SearchEvents endpoint's  input structures
+type SearchEventsRequest struct {
+	UserId uint64                 `json:"user_id"`
+	Query  string                 `json:"query"`
+	Filter repository.EventFilter `json:"filter"`
+	Page   repository.Page        `json:"page"`
+}
+
+// SearchEventsResponse -> SearchEvents endpoint's output structure
+type SearchEventsResponse struct {
+	Events        []repository.Event `json:"events,omitempty"`
+	NextPageToken string             `json:"next_page_token,omitempty"`
+	Err           string             `json:"err,omitempty"`
+}
+
+// ListTagsRequest -> ListTags endpoint's  input structures
+type ListTagsRequest struct {
+	UserId uint64 `json:"user_id"`
+}
+
+// ListTagsResponse -> ListTags endpoint's output structure
+type ListTagsResponse struct {
+	Tags []repository.TagCount `json:"tags"`
+	Err  string                `json:"err,omitempty"`
+}
+
+// GetEventRequest -> GetEvent endpoint's  input structures
+type GetEventRequest struct {
 	EventId string `json:"event_id"`
 	UserId  uint64 `json:"user_id"`
 }
 
+// GetEventResponse -> GetEvent endpoint's output structure
+type GetEventResponse struct {
+	Event repository.Event `json:"event"`
+	Err   string           `json:"err,omitempty"`
+}
+
+// UpdateEventRequest -> UpdateEvent endpoint's  input structures
+type UpdateEventRequest struct {
+	Event  repository.Event  `json:"event"`
+	Fields []string          `json:"fields"`
+	Target repository.Target `json:"target"`
+}
+
+// UpdateEventResponse -> UpdateEvent endpoint's output structure
+type UpdateEventResponse struct {
+	Event repository.Event `json:"event"`
+	Err   string           `json:"err,omitempty"`
+}
+
+// DeleteEventRequest -> DeleteEvent endpoint's  input structures
+type DeleteEventRequest struct {
+	EventId string            `json:"event_id"`
+	UserId  uint64            `json:"user_id"`
+	Target  repository.Target `json:"target"`
+	Version int64             `json:"version,omitempty"`
+}
+
 // DeleteEventResponse -> DeleteEvent endpoint's output structure
 type DeleteEventResponse struct {
 	Err string `json:"err,omitempty"`
 }
 
+// BatchDeleteEventsRequest -> BatchDeleteEvents endpoint's  input structures
+type BatchDeleteEventsRequest struct {
+	UserId   uint64   `json:"user_id"`
+	EventIds []string `json:"event_ids"`
+}
+
+// BatchDeleteEventsResponse -> BatchDeleteEvents endpoint's output structure
+type BatchDeleteEventsResponse struct {
+	Results []repository.BatchResult `json:"results,omitempty"`
+	Err     string                   `json:"err,omitempty"`
+}
+
+// ListTrashRequest -> ListTrash endpoint's  input structures
+type ListTrashRequest struct {
+	UserId uint64          `json:"user_id"`
+	Page   repository.Page `json:"page"`
+}
+
+// ListTrashResponse -> ListTrash endpoint's output structure
+type ListTrashResponse struct {
+	Events        []repository.Event `json:"events,omitempty"`
+	NextPageToken string             `json:"next_page_token,omitempty"`
+	Err           string             `json:"err,omitempty"`
+}
+
+// RestoreEventRequest -> RestoreEvent endpoint's  input structures
+type RestoreEventRequest struct {
+	EventId string `json:"event_id"`
+	UserId  uint64 `json:"user_id"`
+}
+
+// RestoreEventResponse -> RestoreEvent endpoint's output structure
+type RestoreEventResponse struct {
+	Event repository.Event `json:"event"`
+	Err   string           `json:"err,omitempty"`
+}
+
+// PurgeEventRequest -> PurgeEvent endpoint's  input structures
+type PurgeEventRequest struct {
+	EventId string `json:"event_id"`
+	UserId  uint64 `json:"user_id"`
+}
+
+// PurgeEventResponse -> PurgeEvent endpoint's output structure
+type PurgeEventResponse struct {
+	Err string `json:"err,omitempty"`
+}
+
+// ListHistoryRequest -> ListHistory endpoint's  input structures
+type ListHistoryRequest struct {
+	EventId string          `json:"event_id"`
+	UserId  uint64          `json:"user_id"`
+	Page    repository.Page `json:"page"`
+}
+
+// ListHistoryResponse -> ListHistory endpoint's output structure
+type ListHistoryResponse struct {
+	Entries       []repository.HistoryEntry `json:"entries,omitempty"`
+	NextPageToken string                    `json:"next_page_token,omitempty"`
+	Err           string                    `json:"err,omitempty"`
+}
+
+// RevertEventRequest -> RevertEvent endpoint's  input structures
+type RevertEventRequest struct {
+	EventId string `json:"event_id"`
+	EntryId string `json:"entry_id"`
+	UserId  uint64 `json:"user_id"`
+}
+
+// RevertEventResponse -> RevertEvent endpoint's output structure
+type RevertEventResponse struct {
+	Event repository.Event `json:"event"`
+	Err   string           `json:"err,omitempty"`
+}
+
+// WatchEventsRequest -> WatchEvents endpoint's  input structures
+type WatchEventsRequest struct {
+	UserId      uint64 `json:"user_id"`
+	ResumeToken string `json:"resume_token"`
+	// Send sends a notification to the stream of the watch
+	Send func(repository.Notification) error `json:"-"`
+}
+
+// WatchEventsResponse -> WatchEvents endpoint's output structure
+type WatchEventsResponse struct {
+	Err string `json:"err,omitempty"`
+}
+
+// AddAttachmentRequest -> AddAttachment endpoint's  input structures
+type AddAttachmentRequest struct {
+	EventId    string                `json:"event_id"`
+	UserId     uint64                `json:"user_id"`
+	Attachment repository.Attachment `json:"attachment"`
+	Content    []byte                `json:"content"`
+}
+
+// AddAttachmentResponse -> AddAttachment endpoint's output structure
+type AddAttachmentResponse struct {
+	Event repository.Event `json:"event"`
+	Err   string           `json:"err,omitempty"`
+}
+
+// GetAttachmentRequest -> GetAttachment endpoint's  input structures
+type GetAttachmentRequest struct {
+	EventId      string `json:"event_id"`
+	UserId       uint64 `json:"user_id"`
+	AttachmentId string `json:"attachment_id"`
+}
+
+// GetAttachmentResponse -> GetAttachment endpoint's output structure
+type GetAttachmentResponse struct {
+	Attachment repository.Attachment `json:"attachment"`
+	Content    []byte                `json:"content"`
+	Err        string                `json:"err,omitempty"`
+}
+
+// DeleteAttachmentRequest -> DeleteAttachment endpoint's  input structures
+type DeleteAttachmentRequest struct {
+	EventId      string `json:"event_id"`
+	UserId       uint64 `json:"user_id"`
+	AttachmentId string `json:"attachment_id"`
+}
+
+// DeleteAttachmentResponse -> DeleteAttachment endpoint's output structure
+type DeleteAttachmentResponse struct {
+	Event repository.Event `json:"event"`
+	Err   string           `json:"err,omitempty"`
+}
+
+// InviteAttendeesRequest -> InviteAttendees endpoint's  input structures
+type InviteAttendeesRequest struct {
+	EventId   string                `json:"event_id"`
+	UserId    uint64                `json:"user_id"`
+	Attendees []repository.Attendee `json:"attendees"`
+}
+
+// InviteAttendeesResponse -> InviteAttendees endpoint's output structure
+type InviteAttendeesResponse struct {
+	Event repository.Event `json:"event"`
+	Err   string           `json:"err,omitempty"`
+}
+
+// RespondEventRequest -> RespondEvent endpoint's  input structures
+type RespondEventRequest struct {
+	EventId string `json:"event_id"`
+	UserId  uint64 `json:"user_id"`
+	Status  string `json:"status"`
+}
+
+// RespondEventResponse -> RespondEvent endpoint's output structure
+type RespondEventResponse struct {
+	Err string `json:"err,omitempty"`
+}
+
+// ImportEventsRequest -> ImportEvents endpoint's  input structures
+type ImportEventsRequest struct {
+	UserId uint64             `json:"user_id"`
+	Events []repository.Event `json:"events"`
+}
+
+// ImportEventsResponse -> ImportEvents endpoint's output structure
+type ImportEventsResponse struct {
+	Results []repository.ImportResult `json:"results,omitempty"`
+	Err     string                    `json:"err,omitempty"`
+}
+
+// GetEventByUIDRequest -> GetEventByUID endpoint's  input structures
+type GetEventByUIDRequest struct {
+	UID    string `json:"uid"`
+	UserId uint64 `json:"user_id"`
+}
+
+// GetEventByUIDResponse -> GetEventByUID endpoint's output structure
+type GetEventByUIDResponse struct {
+	Event     repository.Event   `json:"event"`
+	Overrides []repository.Event `json:"overrides,omitempty"`
+	Err       string             `json:"err,omitempty"`
+}
+
+// GetEventByResourceRequest -> GetEventByResource endpoint's  input structures
+type GetEventByResourceRequest struct {
+	Name   string `json:"name"`
+	UserId uint64 `json:"user_id"`
+}
+
+// GetEventByResourceResponse -> GetEventByResource endpoint's output structure
+type GetEventByResourceResponse struct {
+	Event     repository.Event   `json:"event"`
+	Overrides []repository.Event `json:"overrides,omitempty"`
+	Err       string             `json:"err,omitempty"`
+}
+
+// CreateCalendarRequest -> CreateCalendar endpoint's  input structures
+type CreateCalendarRequest struct {
+	Calendar repository.Calendar `json:"calendar"`
+}
+
+// CreateCalendarResponse -> CreateCalendar endpoint's output structure
+type CreateCalendarResponse struct {
+	Calendar repository.Calendar `json:"calendar"`
+	Err      string              `json:"err,omitempty"`
+}
+
+// ListCalendarsRequest -> ListCalendars endpoint's  input structures
+type ListCalendarsRequest struct {
+	UserId uint64 `json:"user_id"`
+}
+
+// ListCalendarsResponse -> ListCalendars endpoint's output structure
+type ListCalendarsResponse struct {
+	Calendars []repository.Calendar `json:"calendars"`
+	Err       string                `json:"err,omitempty"`
+}
+
+// UpdateCalendarRequest -> UpdateCalendar endpoint's  input structures
+type UpdateCalendarRequest struct {
+	Calendar repository.Calendar `json:"calendar"`
+	Fields   []string            `json:"fields,omitempty"`
+}
+
+// UpdateCalendarResponse -> UpdateCalendar endpoint's output structure
+type UpdateCalendarResponse struct {
+	Calendar repository.Calendar `json:"calendar"`
+	Err      string              `json:"err,omitempty"`
+}
+
+// DeleteCalendarRequest -> DeleteCalendar endpoint's  input structures
+type DeleteCalendarRequest struct {
+	CalendarId string `json:"calendar_id"`
+	UserId     uint64 `json:"user_id"`
+}
+
+// DeleteCalendarResponse -> DeleteCalendar endpoint's output structure
+type DeleteCalendarResponse struct {
+	Err string `json:"err,omitempty"`
+}
+
+// GrantAccessRequest -> GrantAccess endpoint's  input structures
+type GrantAccessRequest struct {
+	Grant  repository.Grant `json:"grant"`
+	UserId uint64           `json:"user_id"`
+}
+
+// GrantAccessResponse -> GrantAccess endpoint's output structure
+type GrantAccessResponse struct {
+	Grant repository.Grant `json:"grant"`
+	Err   string           `json:"err,omitempty"`
+}
+
+// ListGrantsRequest -> ListGrants endpoint's  input structures
+type ListGrantsRequest struct {
+	CalendarId string `json:"calendar_id"`
+	UserId     uint64 `json:"user_id"`
+}
+
+// ListGrantsResponse -> ListGrants endpoint's output structure
+type ListGrantsResponse struct {
+	Grants []repository.Grant `json:"grants"`
+	Err    string             `json:"err,omitempty"`
+}
+
+// RevokeAccessRequest -> RevokeAccess endpoint's  input structures
+type RevokeAccessRequest struct {
+	CalendarId string `json:"calendar_id"`
+	UserId     uint64 `json:"user_id"`
+	GranteeId  uint64 `json:"grantee_id"`
+}
+
+// RevokeAccessResponse -> RevokeAccess endpoint's output structure
+type RevokeAccessResponse struct {
+	Err string `json:"err,omitempty"`
+}
+
+// FreeBusyRequest -> FreeBusy endpoint's  input structures
+type FreeBusyRequest struct {
+	UserId  uint64    `json:"user_id"`
+	UserIds []uint64  `json:"user_ids"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+}
+
+// FreeBusyResponse -> FreeBusy endpoint's output structure
+type FreeBusyResponse struct {
+	FreeBusy []repository.FreeBusy `json:"free_busy"`
+	Err      string                `json:"err,omitempty"`
+}
+
+// FindMeetingSlotsRequest -> FindMeetingSlots endpoint's  input structures
+type FindMeetingSlotsRequest struct {
+	UserId uint64               `json:"user_id"`
+	Query  repository.SlotQuery `json:"query"`
+}
+
+// FindMeetingSlotsResponse -> FindMeetingSlots endpoint's output structure
+type FindMeetingSlotsResponse struct {
+	Slots []repository.Interval `json:"slots"`
+	Err   string                `json:"err,omitempty"`
+}
+
 // ServiceStatusRequest -> ServiceStatus endpoint's  input structures
 type ServiceStatusRequest struct{}
 
@@ -3,11 +3,41 @@ package calendar
 import (
 	"context"
 	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"time"
 )
 
 type Service interface {
-	CreateEvent(ctx context.Context, event repository.Event) (string, error)
-	ListEvent(ctx context.Context, userId uint64) ([]repository.Event, error)
-	DeleteEvent(ctx context.Context, eventId string, userId uint64) error
+	CreateEvent(ctx context.Context, event repository.Event, policy string) (string, []repository.Event, error)
+	BatchCreateEvents(ctx context.Context, userId uint64, events []repository.Event) ([]repository.BatchResult, error)
+	ListEvent(ctx context.Context, userId uint64, filter repository.EventFilter, page repository.Page) ([]repository.Event, string, error)
+	SearchEvents(ctx context.Context, userId uint64, query string, filter repository.EventFilter, page repository.Page) ([]repository.Event, string, error)
+	ListTags(ctx context.Context, userId uint64) ([]repository.TagCount, error)
+	GetEvent(ctx context.Context, eventId string, userId uint64) (repository.Event, error)
+	UpdateEvent(ctx context.Context, event repository.Event, fields []string, target repository.Target) (repository.Event, error)
+	DeleteEvent(ctx context.Context, eventId string, userId uint64, target repository.Target, version int64) error
+	BatchDeleteEvents(ctx context.Context, userId uint64, eventIds []string) ([]repository.BatchResult, error)
+	ListTrash(ctx context.Context, userId uint64, page repository.Page) ([]repository.Event, string, error)
+	RestoreEvent(ctx context.Context, eventId string, userId uint64) (repository.Event, error)
+	PurgeEvent(ctx context.Context, eventId string, userId uint64) error
+	ListHistory(ctx context.Context, eventId string, userId uint64, page repository.Page) ([]repository.HistoryEntry, string, error)
+	RevertEvent(ctx context.Context, eventId, entryId string, userId uint64) (repository.Event, error)
+	WatchEvents(ctx context.Context, userId uint64, resumeToken string, send func(repository.Notification) error) error
+	InviteAttendees(ctx context.Context, eventId string, userId uint64, attendees []repository.Attendee) (repository.Event, error)
+	RespondEvent(ctx context.Context, eventId string, userId uint64, status string) error
+	AddAttachment(ctx context.Context, eventId string, userId uint64, attachment repository.Attachment, content []byte) (repository.Event, error)
+	GetAttachment(ctx context.Context, eventId string, userId uint64, attachmentId string) (repository.Attachment, []byte, error)
+	DeleteAttachment(ctx context.Context, eventId string, userId uint64, attachmentId string) (repository.Event, error)
+	ImportEvents(ctx context.Context, userId uint64, events []repository.Event) ([]repository.ImportResult, error)
+	GetEventByUID(ctx context.Context, uid string, userId uint64) (repository.Event, []repository.Event, error)
+	GetEventByResource(ctx context.Context, name string, userId uint64) (repository.Event, []repository.Event, error)
+	CreateCalendar(ctx context.Context, calendar repository.Calendar) (repository.Calendar, error)
+	ListCalendars(ctx context.Context, userId uint64) ([]repository.Calendar, error)
+	UpdateCalendar(ctx context.Context, calendar repository.Calendar, fields []string) (repository.Calendar, error)
+	DeleteCalendar(ctx context.Context, calendarId string, userId uint64) error
+	GrantAccess(ctx context.Context, grant repository.Grant, userId uint64) (repository.Grant, error)
+	ListGrants(ctx context.Context, calendarId string, userId uint64) ([]repository.Grant, error)
+	RevokeAccess(ctx context.Context, calendarId string, userId, granteeId uint64) error
+	FreeBusy(ctx context.Context, userId uint64, userIds []uint64, from, to time.Time) ([]repository.FreeBusy, error)
+	FindMeetingSlots(ctx context.Context, userId uint64, query repository.SlotQuery) ([]repository.Interval, error)
 	ServiceStatus(ctx context.Context) (int, error)
 }
@@ -0,0 +1,86 @@
+package trash
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log"
+)
+
+const (
+	defaultInterval  = time.Hour
+	defaultRetention = 30 * 24 * time.Hour
+)
+
+// Store is the part of the calendar repository which the purger uses
+type Store interface {
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Options tunes the purger. Zero values mean the defaults.
+type Options struct {
+	// Interval between the runs
+	Interval time.Duration
+	// Retention is how long the deleted events are kept in the trash
+	Retention time.Duration
+}
+
+// Purger permanently deletes the events which have been in the trash longer than the
+// retention. The purge is idempotent, so the replicas may run it at the same time.
+type Purger struct {
+	store  Store
+	logger log.Logger
+
+	interval  time.Duration
+	retention time.Duration
+
+	now func() time.Time
+}
+
+// NewPurger creates a purger of the trash in the store
+func NewPurger(store Store, opts Options, logger log.Logger) *Purger {
+	p := &Purger{
+		store:     store,
+		logger:    logger,
+		interval:  opts.Interval,
+		retention: opts.Retention,
+		now:       time.Now,
+	}
+	if p.interval <= 0 {
+		p.interval = defaultInterval
+	}
+	if p.retention <= 0 {
+		p.retention = defaultRetention
+	}
+	return p
+}
+
+// Run runs the purger every interval until the context is cancelled
+func (p *Purger) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Tick(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Log("msg", "failed to purge trash", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick permanently deletes the events which are deleted before the retention
+func (p *Purger) Tick(ctx context.Context) error {
+	purged, err := p.store.PurgeTrash(ctx, p.now().Add(-p.retention))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		p.logger.Log("msg", "purged trash", "events", purged)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package trash
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+)
+
+// memoryStore keeps the deletion times of the trashed events in memory
+type memoryStore struct {
+	deleted []time.Time
+	err     error
+}
+
+func (m *memoryStore) PurgeTrash(_ context.Context, before time.Time) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	var kept []time.Time
+	for _, deletedAt := range m.deleted {
+		if !deletedAt.Before(before) {
+			kept = append(kept, deletedAt)
+		}
+	}
+	purged := len(m.deleted) - len(kept)
+	m.deleted = kept
+	return int64(purged), nil
+}
+
+func TestPurger_Tick(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	store := &memoryStore{deleted: []time.Time{
+		now.Add(-31 * 24 * time.Hour),
+		now.Add(-30*24*time.Hour - time.Second),
+		now.Add(-30 * 24 * time.Hour),
+		now.Add(-time.Hour),
+	}}
+	p := NewPurger(store, Options{}, log.NewNopLogger())
+	p.now = func() time.Time { return now }
+
+	if err := p.Tick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.deleted) != 2 {
+		t.Fatalf("expected 2 events in the trash, got %d", len(store.deleted))
+	}
+
+	// A shorter retention purges the recent events too
+	p = NewPurger(store, Options{Retention: time.Minute}, log.NewNopLogger())
+	p.now = func() time.Time { return now }
+	if err := p.Tick(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.deleted) != 0 {
+		t.Fatalf("expected an empty trash, got %d events", len(store.deleted))
+	}
+
+	store.err = errors.New("database is down")
+	if err := p.Tick(context.Background()); err == nil {
+		t.Fatal("expected the error of the store")
+	}
+}
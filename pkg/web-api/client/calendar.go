@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"github.com/3n0ugh/kalenderium/pkg/calendar/pb"
+	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -12,7 +13,10 @@ import (
 
 // NewCalendarClient makes connection between web-api and calendar service and return calendar client.
 func NewCalendarClient(grpcAddr string) (pb.CalendarClient, error) {
-	conn, err := grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	// The attachments are uploaded and downloaded in a single message
+	conn, err := grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(repository.MaxMessageSize),
+			grpc.MaxCallSendMsgSize(repository.MaxMessageSize)))
 	//defer conn.Close()
 	if err != nil {
 		return nil, errors.Wrap(err, "fail to dial")
@@ -9,8 +9,50 @@ import (
 type Set struct {
 	AddEventEndpoint    endpoint.Endpoint
 	ListEventEndpoint   endpoint.Endpoint
+	GetEventEndpoint    endpoint.Endpoint
+	UpdateEventEndpoint endpoint.Endpoint
 	DeleteEventEndpoint endpoint.Endpoint
 
+	BatchCreateEventsEndpoint endpoint.Endpoint
+	BatchDeleteEventsEndpoint endpoint.Endpoint
+
+	ListTrashEndpoint    endpoint.Endpoint
+	RestoreEventEndpoint endpoint.Endpoint
+	PurgeEventEndpoint   endpoint.Endpoint
+
+	ListHistoryEndpoint endpoint.Endpoint
+	RevertEventEndpoint endpoint.Endpoint
+
+	WatchEventsEndpoint endpoint.Endpoint
+
+	InviteAttendeesEndpoint endpoint.Endpoint
+	RespondEventEndpoint    endpoint.Endpoint
+
+	AddAttachmentEndpoint    endpoint.Endpoint
+	GetAttachmentEndpoint    endpoint.Endpoint
+	DeleteAttachmentEndpoint endpoint.Endpoint
+
+	SearchEventsEndpoint endpoint.Endpoint
+	ListTagsEndpoint     endpoint.Endpoint
+
+	ExportEventsEndpoint endpoint.Endpoint
+	ImportEventsEndpoint endpoint.Endpoint
+
+	GetEventByUIDEndpoint      endpoint.Endpoint
+	GetEventByResourceEndpoint endpoint.Endpoint
+
+	CreateCalendarEndpoint endpoint.Endpoint
+	ListCalendarsEndpoint  endpoint.Endpoint
+	UpdateCalendarEndpoint endpoint.Endpoint
+	DeleteCalendarEndpoint endpoint.Endpoint
+
+	GrantAccessEndpoint  endpoint.Endpoint
+	ListGrantsEndpoint   endpoint.Endpoint
+	RevokeAccessEndpoint endpoint.Endpoint
+
+	FreeBusyEndpoint         endpoint.Endpoint
+	FindMeetingSlotsEndpoint endpoint.Endpoint
+
 	SignUpEndpoint endpoint.Endpoint
 	LoginEndpoint  endpoint.Endpoint
 	LogoutEndpoint endpoint.Endpoint
@@ -20,8 +62,50 @@ func New(s webapi.Service) Set {
 	return Set{
 		AddEventEndpoint:    MakeAddEventEndpoint(s),
 		ListEventEndpoint:   MakeListEventEndpoint(s),
+		GetEventEndpoint:    MakeGetEventEndpoint(s),
+		UpdateEventEndpoint: MakeUpdateEventEndpoint(s),
 		DeleteEventEndpoint: MakeDeleteEventEndpoint(s),
 
+		BatchCreateEventsEndpoint: MakeBatchCreateEventsEndpoint(s),
+		BatchDeleteEventsEndpoint: MakeBatchDeleteEventsEndpoint(s),
+
+		ListTrashEndpoint:    MakeListTrashEndpoint(s),
+		RestoreEventEndpoint: MakeRestoreEventEndpoint(s),
+		PurgeEventEndpoint:   MakePurgeEventEndpoint(s),
+
+		ListHistoryEndpoint: MakeListHistoryEndpoint(s),
+		RevertEventEndpoint: MakeRevertEventEndpoint(s),
+
+		WatchEventsEndpoint: MakeWatchEventsEndpoint(s),
+
+		InviteAttendeesEndpoint: MakeInviteAttendeesEndpoint(s),
+		RespondEventEndpoint:    MakeRespondEventEndpoint(s),
+
+		AddAttachmentEndpoint:    MakeAddAttachmentEndpoint(s),
+		GetAttachmentEndpoint:    MakeGetAttachmentEndpoint(s),
+		DeleteAttachmentEndpoint: MakeDeleteAttachmentEndpoint(s),
+
+		SearchEventsEndpoint: MakeSearchEventsEndpoint(s),
+		ListTagsEndpoint:     MakeListTagsEndpoint(s),
+
+		ExportEventsEndpoint: MakeExportEventsEndpoint(s),
+		ImportEventsEndpoint: MakeImportEventsEndpoint(s),
+
+		GetEventByUIDEndpoint:      MakeGetEventByUIDEndpoint(s),
+		GetEventByResourceEndpoint: MakeGetEventByResourceEndpoint(s),
+
+		CreateCalendarEndpoint: MakeCreateCalendarEndpoint(s),
+		ListCalendarsEndpoint:  MakeListCalendarsEndpoint(s),
+		UpdateCalendarEndpoint: MakeUpdateCalendarEndpoint(s),
+		DeleteCalendarEndpoint: MakeDeleteCalendarEndpoint(s),
+
+		GrantAccessEndpoint:  MakeGrantAccessEndpoint(s),
+		ListGrantsEndpoint:   MakeListGrantsEndpoint(s),
+		RevokeAccessEndpoint: MakeRevokeAccessEndpoint(s),
+
+		FreeBusyEndpoint:         MakeFreeBusyEndpoint(s),
+		FindMeetingSlotsEndpoint: MakeFindMeetingSlotsEndpoint(s),
+
 		SignUpEndpoint: MakeSignUpEndpoint(s),
 		LoginEndpoint:  MakeLoginEndpoint(s),
 		LogoutEndpoint: MakeLogoutEndpoint(s),
@@ -34,11 +118,11 @@ func MakeAddEventEndpoint(s webapi.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(AddEventRequest)
 
-		eventId, err := s.AddEvent(ctx, req.Event)
+		eventId, conflicts, err := s.AddEvent(ctx, req.Event, req.ConflictPolicy)
 		if err != nil {
 			return AddEventResponse{EventId: eventId, Err: err.Error()}, err
 		}
-		return AddEventResponse{EventId: eventId, Err: ""}, nil
+		return AddEventResponse{EventId: eventId, Conflicts: conflicts, Err: ""}, nil
 	}
 }
 
@@ -48,12 +132,152 @@ func MakeListEventEndpoint(s webapi.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(ListEventRequest)
 
-		events, err := s.ListEvent(ctx, req.UserId)
+		events, nextPageToken, err := s.ListEvent(ctx, req.UserId, req.Filter, req.Page)
 		if err != nil {
 			return ListEventResponse{Events: events, Err: err.Error()}, err
 		}
-		return ListEventResponse{Events: events, Err: ""}, nil
+		return ListEventResponse{Events: events, NextPageToken: nextPageToken, Err: ""}, nil
+
+	}
+}
+
+// MakeSearchEventsEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeSearchEventsEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SearchEventsRequest)
+
+		events, nextPageToken, err := s.SearchEvents(ctx, req.UserId, req.Query, req.Filter, req.Page)
+		if err != nil {
+			return SearchEventsResponse{Events: events, Err: err.Error()}, err
+		}
+		return SearchEventsResponse{Events: events, NextPageToken: nextPageToken, Err: ""}, nil
+	}
+}
+
+// MakeListTagsEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeListTagsEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListTagsRequest)
+
+		tags, err := s.ListTags(ctx, req.UserId)
+		if err != nil {
+			return ListTagsResponse{Tags: tags, Err: err.Error()}, err
+		}
+		return ListTagsResponse{Tags: tags, Err: ""}, nil
+	}
+}
+
+// MakeExportEventsEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeExportEventsEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ExportEventsRequest)
+
+		events, err := s.ExportEvents(ctx, req.UserId, req.Filter)
+		if err != nil {
+			return ExportEventsResponse{Err: err.Error()}, err
+		}
+		return ExportEventsResponse{Events: events, Err: ""}, nil
+	}
+}
+
+// MakeBatchCreateEventsEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeBatchCreateEventsEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(BatchCreateEventsRequest)
+
+		results, err := s.BatchCreateEvents(ctx, req.UserId, req.Events)
+		if err != nil {
+			return BatchCreateEventsResponse{Err: err.Error()}, err
+		}
+		return BatchCreateEventsResponse{Results: results, Err: ""}, nil
+	}
+}
+
+// MakeBatchDeleteEventsEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeBatchDeleteEventsEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(BatchDeleteEventsRequest)
+
+		results, err := s.BatchDeleteEvents(ctx, req.UserId, req.EventIds)
+		if err != nil {
+			return BatchDeleteEventsResponse{Err: err.Error()}, err
+		}
+		return BatchDeleteEventsResponse{Results: results, Err: ""}, nil
+	}
+}
+
+// MakeImportEventsEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeImportEventsEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ImportEventsRequest)
+
+		results, err := s.ImportEvents(ctx, req.UserId, req.Events, req.Resource)
+		if err != nil {
+			return ImportEventsResponse{Err: err.Error()}, err
+		}
+		return ImportEventsResponse{Results: results, Err: ""}, nil
+	}
+}
+
+// MakeGetEventByUIDEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeGetEventByUIDEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetEventByUIDRequest)
+
+		events, err := s.GetEventByUID(ctx, req.UID, req.UserId)
+		if err != nil {
+			return GetEventByUIDResponse{Err: err.Error()}, err
+		}
+		return GetEventByUIDResponse{Events: events, Err: ""}, nil
+	}
+}
+
+// MakeGetEventByResourceEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeGetEventByResourceEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetEventByResourceRequest)
+
+		events, err := s.GetEventByResource(ctx, req.Name, req.UserId)
+		if err != nil {
+			return GetEventByResourceResponse{Err: err.Error()}, err
+		}
+		return GetEventByResourceResponse{Events: events, Err: ""}, nil
+	}
+}
+
+// MakeGetEventEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeGetEventEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetEventRequest)
+
+		event, err := s.GetEvent(ctx, req.EventId, req.UserId)
+		if err != nil {
+			return GetEventResponse{Err: err.Error()}, err
+		}
+		return GetEventResponse{Event: &event, Err: ""}, nil
+	}
+}
 
+// MakeUpdateEventEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeUpdateEventEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UpdateEventRequest)
+
+		event, err := s.UpdateEvent(ctx, req.Event, req.Fields, req.Target)
+		if err != nil {
+			return UpdateEventResponse{Err: err.Error()}, err
+		}
+		return UpdateEventResponse{Event: &event, Err: ""}, nil
 	}
 }
 
@@ -63,7 +287,7 @@ func MakeDeleteEventEndpoint(s webapi.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(DeleteEventRequest)
 
-		err := s.DeleteEvent(ctx, req.EventId, req.UserId)
+		err := s.DeleteEvent(ctx, req.EventId, req.UserId, req.Target, req.Version)
 		if err != nil {
 			return DeleteEventResponse{Err: err.Error()}, err
 		}
@@ -71,6 +295,286 @@ func MakeDeleteEventEndpoint(s webapi.Service) endpoint.Endpoint {
 	}
 }
 
+// MakeListTrashEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeListTrashEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListTrashRequest)
+
+		events, nextPageToken, err := s.ListTrash(ctx, req.UserId, req.Page)
+		if err != nil {
+			return ListTrashResponse{Events: events, Err: err.Error()}, err
+		}
+		return ListTrashResponse{Events: events, NextPageToken: nextPageToken, Err: ""}, nil
+	}
+}
+
+// MakeRestoreEventEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeRestoreEventEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RestoreEventRequest)
+
+		event, err := s.RestoreEvent(ctx, req.EventId, req.UserId)
+		if err != nil {
+			return RestoreEventResponse{Err: err.Error()}, err
+		}
+		return RestoreEventResponse{Event: &event, Err: ""}, nil
+	}
+}
+
+// MakePurgeEventEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakePurgeEventEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(PurgeEventRequest)
+
+		err := s.PurgeEvent(ctx, req.EventId, req.UserId)
+		if err != nil {
+			return PurgeEventResponse{Err: err.Error()}, err
+		}
+		return PurgeEventResponse{Err: ""}, nil
+	}
+}
+
+// MakeListHistoryEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeListHistoryEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListHistoryRequest)
+
+		entries, nextPageToken, err := s.ListHistory(ctx, req.EventId, req.UserId, req.Page)
+		if err != nil {
+			return ListHistoryResponse{Entries: entries, Err: err.Error()}, err
+		}
+		return ListHistoryResponse{Entries: entries, NextPageToken: nextPageToken, Err: ""}, nil
+	}
+}
+
+// MakeAddAttachmentEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeAddAttachmentEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AddAttachmentRequest)
+
+		event, err := s.AddAttachment(ctx, req.EventId, req.UserId, req.Attachment, req.Content)
+		if err != nil {
+			return AddAttachmentResponse{Err: err.Error()}, err
+		}
+		return AddAttachmentResponse{Event: &event, Err: ""}, nil
+	}
+}
+
+// MakeGetAttachmentEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeGetAttachmentEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetAttachmentRequest)
+
+		attachment, content, err := s.GetAttachment(ctx, req.EventId, req.UserId, req.AttachmentId)
+		if err != nil {
+			return GetAttachmentResponse{Err: err.Error()}, err
+		}
+		return GetAttachmentResponse{Attachment: attachment, Content: content, Err: ""}, nil
+	}
+}
+
+// MakeDeleteAttachmentEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeDeleteAttachmentEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteAttachmentRequest)
+
+		event, err := s.DeleteAttachment(ctx, req.EventId, req.UserId, req.AttachmentId)
+		if err != nil {
+			return DeleteAttachmentResponse{Err: err.Error()}, err
+		}
+		return DeleteAttachmentResponse{Event: &event, Err: ""}, nil
+	}
+}
+
+// MakeRevertEventEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeRevertEventEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RevertEventRequest)
+
+		event, err := s.RevertEvent(ctx, req.EventId, req.EntryId, req.UserId)
+		if err != nil {
+			return RevertEventResponse{Err: err.Error()}, err
+		}
+		return RevertEventResponse{Event: &event, Err: ""}, nil
+	}
+}
+
+// MakeWatchEventsEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeWatchEventsEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(WatchEventsRequest)
+
+		err := s.WatchEvents(ctx, req.UserId, req.ResumeToken, req.Send)
+		if err != nil {
+			return WatchEventsResponse{Err: err.Error()}, err
+		}
+		return WatchEventsResponse{Err: ""}, nil
+	}
+}
+
+// MakeInviteAttendeesEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeInviteAttendeesEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(InviteAttendeesRequest)
+
+		event, err := s.InviteAttendees(ctx, req.EventId, req.UserId, req.Attendees)
+		if err != nil {
+			return InviteAttendeesResponse{Err: err.Error()}, err
+		}
+		return InviteAttendeesResponse{Event: &event, Err: ""}, nil
+	}
+}
+
+// MakeRespondEventEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeRespondEventEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RespondEventRequest)
+
+		err := s.RespondEvent(ctx, req.EventId, req.UserId, req.Status)
+		if err != nil {
+			return RespondEventResponse{Err: err.Error()}, err
+		}
+		return RespondEventResponse{Err: ""}, nil
+	}
+}
+
+// MakeCreateCalendarEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeCreateCalendarEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateCalendarRequest)
+
+		calendar, err := s.CreateCalendar(ctx, req.Calendar)
+		if err != nil {
+			return CreateCalendarResponse{Err: err.Error()}, err
+		}
+		return CreateCalendarResponse{Calendar: &calendar, Err: ""}, nil
+	}
+}
+
+// MakeListCalendarsEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeListCalendarsEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListCalendarsRequest)
+
+		calendars, err := s.ListCalendars(ctx, req.UserId)
+		if err != nil {
+			return ListCalendarsResponse{Err: err.Error()}, err
+		}
+		return ListCalendarsResponse{Calendars: calendars, Err: ""}, nil
+	}
+}
+
+// MakeUpdateCalendarEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeUpdateCalendarEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UpdateCalendarRequest)
+
+		calendar, err := s.UpdateCalendar(ctx, req.Calendar, req.Fields)
+		if err != nil {
+			return UpdateCalendarResponse{Err: err.Error()}, err
+		}
+		return UpdateCalendarResponse{Calendar: &calendar, Err: ""}, nil
+	}
+}
+
+// MakeDeleteCalendarEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeDeleteCalendarEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteCalendarRequest)
+
+		err := s.DeleteCalendar(ctx, req.CalendarId, req.UserId)
+		if err != nil {
+			return DeleteCalendarResponse{Err: err.Error()}, err
+		}
+		return DeleteCalendarResponse{Err: ""}, nil
+	}
+}
+
+// MakeGrantAccessEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeGrantAccessEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GrantAccessRequest)
+
+		grant, err := s.GrantAccess(ctx, req.Grant, req.UserId)
+		if err != nil {
+			return GrantAccessResponse{Err: err.Error()}, err
+		}
+		return GrantAccessResponse{Grant: &grant, Err: ""}, nil
+	}
+}
+
+// MakeListGrantsEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeListGrantsEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListGrantsRequest)
+
+		grants, err := s.ListGrants(ctx, req.CalendarId, req.UserId)
+		if err != nil {
+			return ListGrantsResponse{Err: err.Error()}, err
+		}
+		return ListGrantsResponse{Grants: grants, Err: ""}, nil
+	}
+}
+
+// MakeRevokeAccessEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeRevokeAccessEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RevokeAccessRequest)
+
+		err := s.RevokeAccess(ctx, req.CalendarId, req.UserId, req.GranteeId)
+		if err != nil {
+			return RevokeAccessResponse{Err: err.Error()}, err
+		}
+		return RevokeAccessResponse{Err: ""}, nil
+	}
+}
+
+// MakeFreeBusyEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeFreeBusyEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(FreeBusyRequest)
+
+		freeBusy, err := s.FreeBusy(ctx, req.UserId, req.UserIds, req.From, req.To)
+		if err != nil {
+			return FreeBusyResponse{Err: err.Error()}, err
+		}
+		return FreeBusyResponse{FreeBusy: freeBusy, Err: ""}, nil
+	}
+}
+
+// MakeFindMeetingSlotsEndpoint will receive a request, convert to the desired
+// format, invoke the service and return the response structure
+func MakeFindMeetingSlotsEndpoint(s webapi.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(FindMeetingSlotsRequest)
+
+		slots, err := s.FindMeetingSlots(ctx, req.UserId, req.Query)
+		if err != nil {
+			return FindMeetingSlotsResponse{Err: err.Error()}, err
+		}
+		return FindMeetingSlotsResponse{Slots: slots, Err: ""}, nil
+	}
+}
+
 // MakeSignUpEndpoint will receive a request, convert to the desired
 // format, invoke the service and return the response structure
 func MakeSignUpEndpoint(s webapi.Service) endpoint.Endpoint {
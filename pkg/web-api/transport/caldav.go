@@ -0,0 +1,726 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	context2 "github.com/3n0ugh/kalenderium/internal/context"
+	"github.com/3n0ugh/kalenderium/internal/ical"
+	calendar "github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/3n0ugh/kalenderium/pkg/web-api/endpoints"
+	"github.com/pkg/errors"
+)
+
+// The CalDAV (RFC 4791) collections. The principal of the authenticated user is the root, and
+// its calendar home has a single calendar. The calendar object resources of the calendar are
+// named after the iCalendar UID of their events, unless the client puts them with other names.
+// The other names are stored with the events.
+const (
+	davPrincipalPath = "/v1/dav/"
+	davHomePath      = davPrincipalPath + "calendars/"
+	davCalendarPath  = davHomePath + "default/"
+)
+
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	nsCS     = "http://calendarserver.org/ns/"
+)
+
+const davMethods = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+
+const davContentType = "text/calendar; charset=utf-8; component=VEVENT"
+
+// maxDAVBytes limits the size of the CalDAV request bodies
+const maxDAVBytes = 1 << 20
+
+// davHandler serves the CalDAV clients over the endpoints of the web-api
+type davHandler struct {
+	ep endpoints.Set
+}
+
+func newDAVHandler(ep endpoints.Set) http.Handler {
+	return &davHandler{ep: ep}
+}
+
+func (h *davHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Body != nil {
+		r.Body = http.MaxBytesReader(w, r.Body, maxDAVBytes)
+	}
+
+	p := r.URL.EscapedPath()
+	if !strings.HasSuffix(p, "/") && !strings.HasSuffix(p, ".ics") {
+		p += "/"
+	}
+
+	var err error
+	switch {
+	case r.Method == http.MethodOptions:
+		w.Header().Set("DAV", "1, calendar-access")
+		w.Header().Set("Allow", davMethods)
+	case p == davPrincipalPath || p == davHomePath || p == davCalendarPath:
+		err = h.serveCollection(w, r, p)
+	case strings.HasPrefix(p, davCalendarPath) && strings.HasSuffix(p, ".ics") &&
+		!strings.Contains(strings.TrimPrefix(p, davCalendarPath), "/"):
+		var name string
+		if name, err = url.PathUnescape(strings.TrimSuffix(strings.TrimPrefix(p, davCalendarPath), ".ics")); err != nil {
+			http.Error(w, "invalid resource name", http.StatusBadRequest)
+			return
+		}
+		err = h.serveObject(w, r, name)
+	default:
+		http.NotFound(w, r)
+	}
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errBadRequest), errors.Is(err, calendar.ErrValidation):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, calendar.ErrRecordNotFound):
+			// The event is deleted after the resource is read
+			http.NotFound(w, r)
+		default:
+			logger.Log("msg", "caldav request failed", "method", r.Method, "path", r.URL.Path, "err", err)
+			http.Error(w, "the server encountered a problem and could not process your request",
+				http.StatusInternalServerError)
+		}
+	}
+}
+
+func (h *davHandler) serveCollection(w http.ResponseWriter, r *http.Request, p string) error {
+	switch {
+	case r.Method == "PROPFIND":
+		return h.propfind(w, r, p)
+	case r.Method == "REPORT" && p == davCalendarPath:
+		return h.report(w, r)
+	default:
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, REPORT")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return nil
+	}
+}
+
+func (h *davHandler) serveObject(w http.ResponseWriter, r *http.Request, name string) error {
+	userId := context2.GetUser(r).UserID
+
+	if r.Method == http.MethodPut {
+		return h.put(w, r, userId, name)
+	}
+
+	current, err := h.resource(r.Context(), userId, name)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		http.NotFound(w, r)
+		return nil
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		data := current.data()
+		w.Header().Set("Content-Type", davContentType)
+		w.Header().Set("ETag", current.etag())
+		if r.Method == http.MethodGet {
+			_, err = w.Write(data)
+		}
+		return err
+	case "PROPFIND":
+		return writeMultistatus(w, []davResponse{current.response(readPropNames(r), false)})
+	case http.MethodDelete:
+		if !davPreconditions(r, current) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return nil
+		}
+		if _, err = h.ep.DeleteEventEndpoint(r.Context(), endpoints.DeleteEventRequest{
+			EventId: current.events[0].Id.Hex(),
+			UserId:  userId,
+		}); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	default:
+		w.Header().Set("Allow", davMethods)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return nil
+	}
+}
+
+// put creates or replaces the calendar object resource. The VEVENTs of the resource are
+// imported, and the overridden instances which are not in the resource anymore are deleted.
+// The UID of a resource can not change, and a UID can not be in more than one resource.
+func (h *davHandler) put(w http.ResponseWriter, r *http.Request, userId uint64, name string) error {
+	events, err := ical.Decode(r.Body)
+	if err != nil {
+		return errors.Wrap(errBadRequest, err.Error())
+	}
+	if len(events) == 0 {
+		return errors.Wrap(errBadRequest, "the calendar object must have a VEVENT")
+	}
+	uid := events[0].UID
+	for _, e := range events {
+		if e.Err != nil {
+			return errors.Wrap(errBadRequest, e.Err.Error())
+		}
+		if e.UID != uid {
+			return errors.Wrap(errBadRequest, "the VEVENTs of the calendar object must have the same UID")
+		}
+	}
+
+	current, err := h.resource(r.Context(), userId, name)
+	if err != nil {
+		return err
+	}
+	if !davPreconditions(r, current) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return nil
+	}
+
+	switch {
+	case current != nil && current.uid != uid:
+		return writeUIDConflict(w, current)
+	case current == nil:
+		other, err := h.resourceByUID(r.Context(), userId, uid)
+		if err != nil {
+			return err
+		}
+		if other != nil {
+			return writeUIDConflict(w, other)
+		}
+	}
+
+	// The name is stored only if the UID does not give it
+	var resource string
+	if name != uid {
+		resource = name
+	}
+	resp, err := h.ep.ImportEventsEndpoint(r.Context(), endpoints.ImportEventsRequest{
+		UserId:   userId,
+		Events:   events,
+		Resource: resource,
+	})
+	if err != nil {
+		return err
+	}
+	for _, result := range resp.(endpoints.ImportEventsResponse).Results {
+		if result.Err != "" {
+			return errors.Wrap(errBadRequest, result.Err)
+		}
+	}
+
+	if current != nil {
+		for _, override := range current.events[1:] {
+			if davHasInstance(events, *override.RecurrenceId) {
+				continue
+			}
+			if _, err = h.ep.DeleteEventEndpoint(r.Context(), endpoints.DeleteEventRequest{
+				EventId: override.Id.Hex(),
+				UserId:  userId,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	updated, err := h.resource(r.Context(), userId, name)
+	if err != nil {
+		return err
+	}
+	if updated != nil {
+		w.Header().Set("ETag", updated.etag())
+	}
+	if current == nil {
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
+	return nil
+}
+
+// writeUIDConflict rejects the PUT with the no-uid-conflict precondition (RFC 4791 5.3.2.1),
+// the other resource has the UID or the resource has another UID
+func writeUIDConflict(w http.ResponseWriter, other *davResource) error {
+	body, err := xml.Marshal(struct {
+		XMLName  xml.Name `xml:"DAV: error"`
+		Conflict struct {
+			Href string `xml:"DAV: href"`
+		} `xml:"urn:ietf:params:xml:ns:caldav no-uid-conflict"`
+	}{Conflict: struct {
+		Href string `xml:"DAV: href"`
+	}{Href: other.href()}})
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusConflict)
+	_, err = w.Write(append([]byte(xml.Header), body...))
+	return err
+}
+
+// davHasInstance reports whether the VEVENTs have the overridden instance
+func davHasInstance(events []ical.Event, recurrenceId time.Time) bool {
+	for _, e := range events {
+		if e.RecurrenceId.Equal(recurrenceId) {
+			return true
+		}
+	}
+	return false
+}
+
+// davPreconditions checks the If-Match and If-None-Match headers against the current resource
+func davPreconditions(r *http.Request, current *davResource) bool {
+	if match := r.Header.Get("If-Match"); match != "" {
+		if current == nil || (strings.TrimSpace(match) != "*" && !etagMatches(match, current.etag())) {
+			return false
+		}
+	}
+	if noneMatch := r.Header.Get("If-None-Match"); noneMatch != "" && current != nil {
+		if strings.TrimSpace(noneMatch) == "*" || etagMatches(noneMatch, current.etag()) {
+			return false
+		}
+	}
+	return true
+}
+
+// etagMatches reports whether the list of entity tags in the header has the entity tag
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *davHandler) propfind(w http.ResponseWriter, r *http.Request, p string) error {
+	names := readPropNames(r)
+
+	var responses []davResponse
+	switch p {
+	case davPrincipalPath:
+		responses = append(responses, davCollection(davPrincipalPath, "principal", names))
+		if r.Header.Get("Depth") != "0" {
+			responses = append(responses, davCollection(davHomePath, "home", names))
+		}
+	case davHomePath:
+		responses = append(responses, davCollection(davHomePath, "home", names))
+		if r.Header.Get("Depth") == "0" {
+			break
+		}
+		fallthrough
+	case davCalendarPath:
+		// The resources are read only for the ctag and the members of the calendar
+		members := p == davCalendarPath && r.Header.Get("Depth") != "0"
+		getctag := xml.Name{Space: nsCS, Local: "getctag"}
+		if !members && !hasPropName(names, getctag) {
+			responses = append(responses, davCollection(davCalendarPath, "calendar", names))
+			break
+		}
+
+		resources, err := h.resources(r.Context(), context2.GetUser(r).UserID, calendar.EventFilter{})
+		if err != nil {
+			return err
+		}
+		responses = append(responses, davCollection(davCalendarPath, "calendar", names,
+			davProp{XMLName: getctag, InnerXML: ctag(resources)}))
+
+		if members {
+			for _, resource := range resources {
+				responses = append(responses, resource.response(names, false))
+			}
+		}
+	}
+	return writeMultistatus(w, responses)
+}
+
+// report answers the calendar-query and calendar-multiget reports of the calendar
+func (h *davHandler) report(w http.ResponseWriter, r *http.Request) error {
+	var req struct {
+		XMLName xml.Name
+		Prop    davPropNames `xml:"DAV: prop"`
+		Hrefs   []string     `xml:"DAV: href"`
+		Filter  struct {
+			CompFilter davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+		} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.Wrap(errBadRequest, "invalid report")
+	}
+	names := req.Prop.names()
+	userId := context2.GetUser(r).UserID
+
+	var responses []davResponse
+	switch req.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		filter, ok, err := req.Filter.CompFilter.eventFilter()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		resources, err := h.resources(r.Context(), userId, filter)
+		if err != nil {
+			return err
+		}
+		for _, resource := range resources {
+			responses = append(responses, resource.response(names, true))
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		for _, href := range req.Hrefs {
+			response := davResponse{Href: href, Status: "HTTP/1.1 404 Not Found"}
+
+			u, err := url.Parse(strings.TrimSpace(href))
+			if err == nil && strings.HasPrefix(u.Path, davCalendarPath) && strings.HasSuffix(u.Path, ".ics") {
+				name := strings.TrimSuffix(strings.TrimPrefix(u.Path, davCalendarPath), ".ics")
+				resource, err := h.resource(r.Context(), userId, name)
+				if err != nil {
+					return err
+				}
+				if resource != nil {
+					response = resource.response(names, true)
+				}
+			}
+			responses = append(responses, response)
+		}
+	default:
+		http.Error(w, "the report is not supported", http.StatusForbidden)
+		return nil
+	}
+	return writeMultistatus(w, responses)
+}
+
+// davCompFilter is the comp-filter of the calendar-query, only its VEVENT time range is used
+type davCompFilter struct {
+	Name        string          `xml:"name,attr"`
+	CompFilters []davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	TimeRange   *struct {
+		Start string `xml:"start,attr"`
+		End   string `xml:"end,attr"`
+	} `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+}
+
+// eventFilter returns the time range of the VEVENT comp-filter. It returns false if the
+// query is not for VEVENTs.
+func (f davCompFilter) eventFilter() (calendar.EventFilter, bool, error) {
+	var filter calendar.EventFilter
+	if f.Name != "VCALENDAR" {
+		return filter, false, nil
+	}
+	if len(f.CompFilters) == 0 {
+		return filter, true, nil
+	}
+
+	for _, event := range f.CompFilters {
+		if event.Name != "VEVENT" {
+			continue
+		}
+		if event.TimeRange == nil {
+			return filter, true, nil
+		}
+
+		var err error
+		for _, t := range []struct {
+			value string
+			time  *time.Time
+		}{{event.TimeRange.Start, &filter.From}, {event.TimeRange.End, &filter.To}} {
+			if t.value == "" {
+				continue
+			}
+			if *t.time, err = time.Parse("20060102T150405Z", t.value); err != nil {
+				return filter, false, errors.Wrap(errBadRequest, "invalid time-range")
+			}
+		}
+		return filter, true, nil
+	}
+	return filter, false, nil
+}
+
+// resources gets the calendar object resources of the user in the filter window. The events
+// which the user is invited to are not resources, the attendees can not change them.
+func (h *davHandler) resources(ctx context.Context, userId uint64, filter calendar.EventFilter) ([]*davResource, error) {
+	resp, err := h.ep.ExportEventsEndpoint(ctx, endpoints.ExportEventsRequest{UserId: userId, Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []*davResource
+	byUID := map[string]*davResource{}
+	for _, event := range resp.(endpoints.ExportEventsResponse).Events {
+		if event.UserId != userId {
+			continue
+		}
+		uid := event.ICalUID()
+		if resource, ok := byUID[uid]; ok {
+			resource.events = append(resource.events, event)
+			if event.Resource != "" {
+				resource.name = event.Resource
+			}
+			continue
+		}
+		byUID[uid] = newDAVResource([]calendar.Event{event})
+		resources = append(resources, byUID[uid])
+	}
+	return resources, nil
+}
+
+// resource gets the calendar object resource with the name, or nil if it does not exist
+func (h *davHandler) resource(ctx context.Context, userId uint64, name string) (*davResource, error) {
+	resp, err := h.ep.GetEventByResourceEndpoint(ctx, endpoints.GetEventByResourceRequest{Name: name, UserId: userId})
+	if err != nil {
+		if errors.Is(err, calendar.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return newDAVResource(resp.(endpoints.GetEventByResourceResponse).Events), nil
+}
+
+// resourceByUID gets the calendar object resource of the event with the UID whatever its name
+// is, or nil if it does not exist
+func (h *davHandler) resourceByUID(ctx context.Context, userId uint64, uid string) (*davResource, error) {
+	resp, err := h.ep.GetEventByUIDEndpoint(ctx, endpoints.GetEventByUIDRequest{UID: uid, UserId: userId})
+	if err != nil {
+		if errors.Is(err, calendar.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return newDAVResource(resp.(endpoints.GetEventByUIDResponse).Events), nil
+}
+
+// davResource is a calendar object resource: an event, followed by its overridden instances.
+// It is named after the UID of the event if the event has no resource name.
+type davResource struct {
+	name   string
+	uid    string
+	events []calendar.Event
+}
+
+func newDAVResource(events []calendar.Event) *davResource {
+	uid := events[0].ICalUID()
+	name := events[0].Resource
+	if name == "" {
+		name = uid
+	}
+	return &davResource{name: name, uid: uid, events: events}
+}
+
+func (c *davResource) href() string {
+	return davCalendarPath + url.PathEscape(c.name) + ".ics"
+}
+
+// data encodes the resource as an iCalendar object. It is stamped with the creation time of
+// the event, so the same events are always encoded to the same data.
+func (c *davResource) data() []byte {
+	stamp := c.events[0].Id.Timestamp()
+
+	events := make([]ical.Event, len(c.events))
+	for i, event := range c.events {
+		events[i] = calendar.ToICal(event, stamp)
+	}
+
+	var buf bytes.Buffer
+	_ = ical.Encode(&buf, events)
+	return buf.Bytes()
+}
+
+// etag is the strong entity tag of the resource data
+func (c *davResource) etag() string {
+	sum := sha256.Sum256(c.data())
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func (c *davResource) response(names []xml.Name, withData bool) davResponse {
+	props := []davProp{
+		{XMLName: xml.Name{Space: nsDAV, Local: "resourcetype"}},
+		{XMLName: xml.Name{Space: nsDAV, Local: "getetag"}, InnerXML: escapeXML(c.etag())},
+		{XMLName: xml.Name{Space: nsDAV, Local: "getcontenttype"}, InnerXML: davContentType},
+	}
+	if withData {
+		props = append(props, davProp{
+			XMLName:  xml.Name{Space: nsCalDAV, Local: "calendar-data"},
+			InnerXML: escapeXML(string(c.data())),
+		})
+	}
+	return newDAVResponse(c.href(), props, names)
+}
+
+// ctag changes whenever a resource of the calendar changes
+func ctag(resources []*davResource) string {
+	h := sha256.New()
+	for _, resource := range resources {
+		io.WriteString(h, resource.name+resource.etag())
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// davCollection returns the properties of the principal, the calendar home or the calendar
+func davCollection(href, kind string, names []xml.Name, extra ...davProp) davResponse {
+	principal := `<href xmlns="DAV:">` + davPrincipalPath + `</href>`
+	props := []davProp{
+		{XMLName: xml.Name{Space: nsDAV, Local: "current-user-principal"}, InnerXML: principal},
+		{XMLName: xml.Name{Space: nsDAV, Local: "principal-URL"}, InnerXML: principal},
+		{XMLName: xml.Name{Space: nsCalDAV, Local: "calendar-home-set"},
+			InnerXML: `<href xmlns="DAV:">` + davHomePath + `</href>`},
+	}
+
+	switch kind {
+	case "principal":
+		props = append(props,
+			davProp{XMLName: xml.Name{Space: nsDAV, Local: "resourcetype"},
+				InnerXML: `<collection xmlns="DAV:"/><principal xmlns="DAV:"/>`},
+			davProp{XMLName: xml.Name{Space: nsDAV, Local: "displayname"}, InnerXML: "kalenderium"})
+	case "home":
+		props = append(props,
+			davProp{XMLName: xml.Name{Space: nsDAV, Local: "resourcetype"}, InnerXML: `<collection xmlns="DAV:"/>`},
+			davProp{XMLName: xml.Name{Space: nsDAV, Local: "displayname"}, InnerXML: "Calendars"})
+	case "calendar":
+		props = append(props,
+			davProp{XMLName: xml.Name{Space: nsDAV, Local: "resourcetype"},
+				InnerXML: `<collection xmlns="DAV:"/><calendar xmlns="urn:ietf:params:xml:ns:caldav"/>`},
+			davProp{XMLName: xml.Name{Space: nsDAV, Local: "displayname"}, InnerXML: "Calendar"},
+			davProp{XMLName: xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"},
+				InnerXML: `<comp xmlns="urn:ietf:params:xml:ns:caldav" name="VEVENT"/>`},
+			davProp{XMLName: xml.Name{Space: nsDAV, Local: "supported-report-set"},
+				InnerXML: `<supported-report xmlns="DAV:"><report><calendar-query xmlns="urn:ietf:params:xml:ns:caldav"/></report></supported-report>` +
+					`<supported-report xmlns="DAV:"><report><calendar-multiget xmlns="urn:ietf:params:xml:ns:caldav"/></report></supported-report>`})
+	}
+	return newDAVResponse(href, append(props, extra...), names)
+}
+
+// davProp is a property with its value as XML
+type davProp struct {
+	XMLName  xml.Name
+	InnerXML string `xml:",innerxml"`
+}
+
+type davPropstat struct {
+	Prop struct {
+		Props []davProp
+	} `xml:"DAV: prop"`
+	Status string `xml:"DAV: status"`
+}
+
+func newDAVPropstat(props []davProp, status string) davPropstat {
+	propstat := davPropstat{Status: status}
+	propstat.Prop.Props = props
+	return propstat
+}
+
+type davResponse struct {
+	Href      string        `xml:"DAV: href"`
+	Propstats []davPropstat `xml:"DAV: propstat,omitempty"`
+	Status    string        `xml:"DAV: status,omitempty"`
+}
+
+// newDAVResponse returns the requested properties, the ones which the resource does not have
+// are reported as not found. All the properties are returned if none is requested.
+func newDAVResponse(href string, props []davProp, names []xml.Name) davResponse {
+	if names == nil {
+		return davResponse{Href: href, Propstats: []davPropstat{newDAVPropstat(props, "HTTP/1.1 200 OK")}}
+	}
+
+	var found, missing []davProp
+	for _, name := range names {
+		prop, ok := findProp(props, name)
+		if ok {
+			found = append(found, prop)
+		} else {
+			missing = append(missing, davProp{XMLName: name})
+		}
+	}
+
+	response := davResponse{Href: href}
+	if len(found) > 0 {
+		response.Propstats = append(response.Propstats, newDAVPropstat(found, "HTTP/1.1 200 OK"))
+	}
+	if len(missing) > 0 {
+		response.Propstats = append(response.Propstats, newDAVPropstat(missing, "HTTP/1.1 404 Not Found"))
+	}
+	return response
+}
+
+// hasPropName returns true if the property is asked, all the properties are asked without names
+func hasPropName(names []xml.Name, name xml.Name) bool {
+	if names == nil {
+		return true
+	}
+	for _, other := range names {
+		if other == name {
+			return true
+		}
+	}
+	return false
+}
+
+func findProp(props []davProp, name xml.Name) (davProp, bool) {
+	for _, prop := range props {
+		if prop.XMLName == name {
+			return prop, true
+		}
+	}
+	return davProp{}, false
+}
+
+// davPropNames is the prop element of the PROPFIND and REPORT requests
+type davPropNames struct {
+	Names []struct {
+		XMLName xml.Name
+	} `xml:",any"`
+}
+
+// names returns the requested property names, or nil if all the properties are requested
+func (p davPropNames) names() []xml.Name {
+	if len(p.Names) == 0 {
+		return nil
+	}
+	names := make([]xml.Name, len(p.Names))
+	for i, name := range p.Names {
+		names[i] = name.XMLName
+	}
+	return names
+}
+
+// readPropNames reads the property names of the PROPFIND request. An empty body or allprop
+// requests all the properties.
+func readPropNames(r *http.Request) []xml.Name {
+	var req struct {
+		Prop *davPropNames `xml:"DAV: prop"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil || req.Prop == nil {
+		return nil
+	}
+	return req.Prop.names()
+}
+
+func writeMultistatus(w http.ResponseWriter, responses []davResponse) error {
+	body, err := xml.Marshal(struct {
+		XMLName   xml.Name      `xml:"DAV: multistatus"`
+		Responses []davResponse `xml:"DAV: response"`
+	}{Responses: responses})
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	_, err = w.Write(append([]byte(xml.Header), body...))
+	return err
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
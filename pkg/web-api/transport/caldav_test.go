@@ -0,0 +1,441 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	context2 "github.com/3n0ugh/kalenderium/internal/context"
+	"github.com/3n0ugh/kalenderium/internal/ical"
+	repo "github.com/3n0ugh/kalenderium/pkg/account/repository"
+	calendar "github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/3n0ugh/kalenderium/pkg/web-api/endpoints"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const davUserId = 7
+
+// davStore keeps the events of the stub endpoints of the CalDAV handler
+type davStore struct {
+	mu      sync.Mutex
+	events  []calendar.Event
+	exports int
+}
+
+// master returns the event which is not an override and matches
+func (s *davStore) master(match func(calendar.Event) bool) ([]calendar.Event, error) {
+	for _, event := range s.events {
+		if event.RecurrenceId != nil || !match(event) {
+			continue
+		}
+		events := []calendar.Event{event}
+		for _, override := range s.events {
+			if override.RecurrenceId != nil && override.UID == event.UID {
+				events = append(events, override)
+			}
+		}
+		return events, nil
+	}
+	return nil, calendar.ErrRecordNotFound
+}
+
+func (s *davStore) endpoints() endpoints.Set {
+	return endpoints.Set{
+		ExportEventsEndpoint: func(ctx context.Context, request interface{}) (interface{}, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.exports++
+			return endpoints.ExportEventsResponse{Events: append([]calendar.Event(nil), s.events...)}, nil
+		},
+		GetEventByUIDEndpoint: func(ctx context.Context, request interface{}) (interface{}, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			uid := request.(endpoints.GetEventByUIDRequest).UID
+			events, err := s.master(func(e calendar.Event) bool { return e.UID == uid })
+			return endpoints.GetEventByUIDResponse{Events: events}, err
+		},
+		GetEventByResourceEndpoint: func(ctx context.Context, request interface{}) (interface{}, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			name := request.(endpoints.GetEventByResourceRequest).Name
+			events, err := s.master(func(e calendar.Event) bool {
+				return e.Resource == name || (e.Resource == "" && e.UID == name)
+			})
+			return endpoints.GetEventByResourceResponse{Events: events}, err
+		},
+		ImportEventsEndpoint: func(ctx context.Context, request interface{}) (interface{}, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			req := request.(endpoints.ImportEventsRequest)
+			var results []calendar.ImportResult
+			for _, e := range req.Events {
+				event := calendar.FromICal(e, req.UserId)
+				if event.RecurrenceId == nil {
+					event.Resource = req.Resource
+				}
+				results = append(results, calendar.ImportResult{UID: event.UID})
+				s.put(event)
+			}
+			return endpoints.ImportEventsResponse{Results: results}, nil
+		},
+		DeleteEventEndpoint: func(ctx context.Context, request interface{}) (interface{}, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			id := request.(endpoints.DeleteEventRequest).EventId
+			for i, event := range s.events {
+				if event.Id.Hex() == id {
+					s.events = append(s.events[:i], s.events[i+1:]...)
+					return endpoints.DeleteEventResponse{}, nil
+				}
+			}
+			return nil, calendar.ErrRecordNotFound
+		},
+	}
+}
+
+// put replaces the event with the same UID and recurrence id, the replaced event keeps its id
+// and resource name like the imported events of the calendar service
+func (s *davStore) put(event calendar.Event) {
+	for i, existing := range s.events {
+		if existing.UID != event.UID || (existing.RecurrenceId == nil) != (event.RecurrenceId == nil) ||
+			(event.RecurrenceId != nil && !existing.RecurrenceId.Equal(*event.RecurrenceId)) {
+			continue
+		}
+		event.Id, event.Resource = existing.Id, existing.Resource
+		s.events[i] = event
+		return
+	}
+	event.Id = primitive.NewObjectID()
+	s.events = append(s.events, event)
+}
+
+func newDAVStore() *davStore {
+	start := time.Date(2022, time.June, 6, 9, 0, 0, 0, time.UTC)
+	return &davStore{events: []calendar.Event{
+		{Id: primitive.NewObjectID(), UserId: davUserId, Name: "Standup", Start: start,
+			End: start.Add(15 * time.Minute), Color: "#0000FF", UID: "standup@example.com", Resource: "standup"},
+		{Id: primitive.NewObjectID(), UserId: davUserId, Name: "Lunch", Start: start.Add(3 * time.Hour),
+			End: start.Add(4 * time.Hour), Color: "#00FF00", UID: "lunch@example.com"},
+	}}
+}
+
+func davRequest(h http.Handler, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	for key, value := range header {
+		r.Header.Set(key, value)
+	}
+	r = context2.SetUser(r, &repo.User{UserID: davUserId})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// davObject returns the iCalendar object of a single event with the UID
+func davObject(t *testing.T, uid, summary string) string {
+	start := time.Date(2022, time.June, 7, 14, 0, 0, 0, time.UTC)
+	var b strings.Builder
+	if err := ical.Encode(&b, []ical.Event{{UID: uid, Stamp: start, Summary: summary, Start: start,
+		End: start.Add(time.Hour)}}); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestDAVHandler_Propfind(t *testing.T) {
+	tests := map[string]struct {
+		path     string
+		depth    string
+		body     string
+		contains []string
+		excludes []string
+		exports  int
+	}{
+		"Calendar_Members": {
+			path:  davCalendarPath,
+			depth: "1",
+			contains: []string{davCalendarPath + "standup.ics", davCalendarPath + "lunch@example.com.ics",
+				"getctag"},
+			exports: 1,
+		},
+		"Calendar_Without_Ctag": {
+			path:     davCalendarPath,
+			depth:    "0",
+			body:     `<propfind xmlns="DAV:"><prop><displayname/></prop></propfind>`,
+			contains: []string{"Calendar"},
+			excludes: []string{"standup.ics", "getctag"},
+		},
+		"Calendar_Ctag": {
+			path:     davCalendarPath,
+			depth:    "0",
+			body:     `<propfind xmlns="DAV:"><prop><getctag xmlns="http://calendarserver.org/ns/"/></prop></propfind>`,
+			contains: []string{"getctag"},
+			excludes: []string{"standup.ics"},
+			exports:  1,
+		},
+		"Principal": {
+			path:     davPrincipalPath,
+			depth:    "0",
+			contains: []string{davHomePath},
+			excludes: []string{davCalendarPath},
+		},
+		"Resource": {
+			path:     davCalendarPath + "standup.ics",
+			depth:    "0",
+			contains: []string{davCalendarPath + "standup.ics", "getetag"},
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			store := newDAVStore()
+			w := davRequest(newDAVHandler(store.endpoints()), "PROPFIND", tt.path, tt.body,
+				map[string]string{"Depth": tt.depth})
+
+			if w.Code != http.StatusMultiStatus {
+				t.Fatalf("Code -> Want: %d; Got: %d", http.StatusMultiStatus, w.Code)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(w.Body.String(), s) {
+					t.Errorf("Body -> Want: %q in it; Got: %s", s, w.Body.String())
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(w.Body.String(), s) {
+					t.Errorf("Body -> Want: no %q in it; Got: %s", s, w.Body.String())
+				}
+			}
+			if store.exports != tt.exports {
+				t.Errorf("Exports -> Want: %d; Got: %d", tt.exports, store.exports)
+			}
+		})
+	}
+}
+
+func TestDAVHandler_Report(t *testing.T) {
+	tests := map[string]struct {
+		body     string
+		code     int
+		contains []string
+		excludes []string
+	}{
+		"Calendar_Query": {
+			body: `<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">` +
+				`<D:prop><D:getetag/><C:calendar-data/></D:prop>` +
+				`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VEVENT"/></C:comp-filter></C:filter>` +
+				`</C:calendar-query>`,
+			code: http.StatusMultiStatus,
+			contains: []string{davCalendarPath + "standup.ics", davCalendarPath + "lunch@example.com.ics",
+				"UID:standup@example.com"},
+		},
+		"Calendar_Query_Not_Events": {
+			body: `<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">` +
+				`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VTODO"/></C:comp-filter></C:filter>` +
+				`</C:calendar-query>`,
+			code:     http.StatusMultiStatus,
+			excludes: []string{".ics"},
+		},
+		"Calendar_Multiget": {
+			body: `<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">` +
+				`<D:prop><C:calendar-data/></D:prop>` +
+				`<D:href>` + davCalendarPath + `standup.ics</D:href>` +
+				`<D:href>` + davCalendarPath + `missing.ics</D:href>` +
+				`</C:calendar-multiget>`,
+			code:     http.StatusMultiStatus,
+			contains: []string{"UID:standup@example.com", "HTTP/1.1 404 Not Found"},
+			excludes: []string{"lunch@example.com"},
+		},
+		"Unsupported": {
+			body: `<D:sync-collection xmlns:D="DAV:"/>`,
+			code: http.StatusForbidden,
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			w := davRequest(newDAVHandler(newDAVStore().endpoints()), "REPORT", davCalendarPath, tt.body, nil)
+
+			if w.Code != tt.code {
+				t.Fatalf("Code -> Want: %d; Got: %d", tt.code, w.Code)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(w.Body.String(), s) {
+					t.Errorf("Body -> Want: %q in it; Got: %s", s, w.Body.String())
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(w.Body.String(), s) {
+					t.Errorf("Body -> Want: no %q in it; Got: %s", s, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestDAVHandler_Put(t *testing.T) {
+	store := newDAVStore()
+	h := newDAVHandler(store.endpoints())
+	etag := davRequest(h, http.MethodGet, davCalendarPath+"standup.ics", "", nil).Header().Get("ETag")
+
+	tests := map[string]struct {
+		path     string
+		body     string
+		header   map[string]string
+		code     int
+		contains string
+	}{
+		"Name_Other_Than_UID": {
+			path: davCalendarPath + "client-name.ics",
+			body: davObject(t, "review@example.com", "Review"),
+			code: http.StatusCreated,
+		},
+		"If_None_Match_Existing": {
+			path:   davCalendarPath + "standup.ics",
+			body:   davObject(t, "standup@example.com", "Standup"),
+			header: map[string]string{"If-None-Match": "*"},
+			code:   http.StatusPreconditionFailed,
+		},
+		"If_Match_Changed": {
+			path:   davCalendarPath + "standup.ics",
+			body:   davObject(t, "standup@example.com", "Standup"),
+			header: map[string]string{"If-Match": `"changed"`},
+			code:   http.StatusPreconditionFailed,
+		},
+		"If_Match_Missing": {
+			path:   davCalendarPath + "missing.ics",
+			body:   davObject(t, "missing@example.com", "Missing"),
+			header: map[string]string{"If-Match": "*"},
+			code:   http.StatusPreconditionFailed,
+		},
+		"UID_In_Other_Resource": {
+			path:     davCalendarPath + "other.ics",
+			body:     davObject(t, "standup@example.com", "Standup"),
+			code:     http.StatusConflict,
+			contains: davCalendarPath + "standup.ics",
+		},
+		"UID_Changed": {
+			path:     davCalendarPath + "standup.ics",
+			body:     davObject(t, "retro@example.com", "Retro"),
+			code:     http.StatusConflict,
+			contains: "no-uid-conflict",
+		},
+		"Invalid_Object": {
+			path: davCalendarPath + "invalid.ics",
+			body: "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n",
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			w := davRequest(h, http.MethodPut, tt.path, tt.body, tt.header)
+
+			if w.Code != tt.code {
+				t.Fatalf("Code -> Want: %d; Got: %d %s", tt.code, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("Body -> Want: %q in it; Got: %s", tt.contains, w.Body.String())
+			}
+		})
+	}
+
+	// The resource is found by its name, not by its UID
+	w := davRequest(h, http.MethodGet, davCalendarPath+"client-name.ics", "", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "UID:review@example.com") {
+		t.Errorf("Get -> Want: %d with the UID; Got: %d %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w = davRequest(h, http.MethodGet, davCalendarPath+"review@example.com.ics", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Get by UID -> Want: %d; Got: %d", http.StatusNotFound, w.Code)
+	}
+
+	// The resource is replaced if it is not changed after it is read
+	w = davRequest(h, http.MethodPut, davCalendarPath+"standup.ics", davObject(t, "standup@example.com", "Daily"),
+		map[string]string{"If-Match": etag})
+	if w.Code != http.StatusNoContent || w.Header().Get("ETag") == etag {
+		t.Errorf("Put -> Want: %d with a new ETag; Got: %d %q", http.StatusNoContent, w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestDAVHandler_Delete(t *testing.T) {
+	store := newDAVStore()
+	h := newDAVHandler(store.endpoints())
+	etag := davRequest(h, http.MethodGet, davCalendarPath+"standup.ics", "", nil).Header().Get("ETag")
+
+	tests := []struct {
+		scenario string
+		path     string
+		header   map[string]string
+		code     int
+	}{
+		{"If_Match_Changed", davCalendarPath + "standup.ics", map[string]string{"If-Match": `"changed"`},
+			http.StatusPreconditionFailed},
+		{"Missing", davCalendarPath + "missing.ics", nil, http.StatusNotFound},
+		{"If_Match", davCalendarPath + "standup.ics", map[string]string{"If-Match": etag}, http.StatusNoContent},
+		{"Deleted", davCalendarPath + "standup.ics", nil, http.StatusNotFound},
+	}
+
+	// The scenarios run in order, the resource is deleted by the third one
+	for _, tt := range tests {
+		t.Run(tt.scenario, func(t *testing.T) {
+			w := davRequest(h, http.MethodDelete, tt.path, "", tt.header)
+			if w.Code != tt.code {
+				t.Errorf("Code -> Want: %d; Got: %d", tt.code, w.Code)
+			}
+		})
+	}
+
+	if len(store.events) != 1 || store.events[0].UID != "lunch@example.com" {
+		t.Errorf("Events -> Want: only the lunch; Got: %v", store.events)
+	}
+}
+
+func TestBasicAuthentication(t *testing.T) {
+	check := checkPassword
+	defer func() { checkPassword = check }()
+	checkPassword = func(email, password string) (uint64, error) {
+		if email != "jon@example.com" || password != "winter" {
+			return 0, errors.New("invalid credentials")
+		}
+		return davUserId, nil
+	}
+
+	var got uint64
+	h := authentication(requireDAVUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = context2.GetUser(r).UserID
+	})))
+
+	tests := map[string]struct {
+		email, password string
+		basic           bool
+		code            int
+		userId          uint64
+	}{
+		"Valid":          {email: "jon@example.com", password: "winter", basic: true, code: http.StatusOK, userId: davUserId},
+		"Wrong_Password": {email: "jon@example.com", password: "summer", basic: true, code: http.StatusUnauthorized},
+		"Anonymous":      {code: http.StatusUnauthorized},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			got = 0
+			r := httptest.NewRequest("PROPFIND", davCalendarPath, nil)
+			if tt.basic {
+				r.SetBasicAuth(tt.email, tt.password)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.code || got != tt.userId {
+				t.Errorf("Out -> Want: %d for user %d; Got: %d for user %d", tt.code, tt.userId, w.Code, got)
+			}
+			if tt.code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("WWW-Authenticate -> Want: the Basic challenge; Got: none")
+			}
+		})
+	}
+}
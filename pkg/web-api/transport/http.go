@@ -3,19 +3,67 @@ package transport
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"github.com/3n0ugh/kalenderium/internal/config"
 	context2 "github.com/3n0ugh/kalenderium/internal/context"
 	errs "github.com/3n0ugh/kalenderium/internal/err"
+	"github.com/3n0ugh/kalenderium/internal/ical"
 	"github.com/3n0ugh/kalenderium/pkg/account/repository"
+	calendar "github.com/3n0ugh/kalenderium/pkg/calendar/repository"
 	"github.com/3n0ugh/kalenderium/pkg/web-api/endpoints"
 	httpTransport "github.com/go-kit/kit/transport/http"
 	"github.com/go-kit/log"
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"mime"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func NewHTTPHandler(ep endpoints.Set) http.Handler {
+// errBadRequest is wrapped by the decoders when the request is malformed
+var errBadRequest = errors.New("bad request")
+
+// errPreconditionRequired is returned by the decoders of the event changes without If-Match
+var errPreconditionRequired = errors.New("the If-Match header with the ETag of the event must be provided")
+
+// errPayloadTooLarge and errUnsupportedType are returned by the decoder of the attachments which
+// are over the size limit or are not of an allowed type
+var (
+	errPayloadTooLarge = errors.New("the attachment must not be more than 10 MiB")
+	errUnsupportedType = errors.New("the attachment must be a document or an image")
+)
+
+// defaultMaxBatchSize is used when the max batch size is not configured
+const defaultMaxBatchSize = 100
+
+func NewHTTPHandler(ep endpoints.Set, cfg config.WebApiServiceConfigurations) http.Handler {
+	// The calendar service does not take the batches larger than its own limit
+	maxBatchSize := cfg.MaxBatchSize
+	switch {
+	case maxBatchSize <= 0:
+		maxBatchSize = defaultMaxBatchSize
+	case maxBatchSize > calendar.MaxBatchSize:
+		logger.Log("msg", "max batch size is over the limit of the calendar service, the limit is used",
+			"max_batch_size", maxBatchSize, "limit", calendar.MaxBatchSize)
+		maxBatchSize = calendar.MaxBatchSize
+	}
+
+	var streamHeartbeat time.Duration
+	if cfg.StreamHeartbeat != "" {
+		var err error
+		if streamHeartbeat, err = time.ParseDuration(cfg.StreamHeartbeat); err != nil {
+			logger.Log("msg", "invalid stream heartbeat, the default is used", "err", err)
+		}
+	}
+
 	r := mux.NewRouter()
 
 	r.MethodNotAllowedHandler = http.HandlerFunc(errs.MethodNotAllowedResponse)
@@ -23,36 +71,215 @@ func NewHTTPHandler(ep endpoints.Set) http.Handler {
 
 	r.Use(authentication, rateLimit, secureHeaders, enableCORS, recoverPanic, prometheusMiddleware)
 
+	options := []httpTransport.ServerOption{
+		httpTransport.ServerErrorEncoder(encodeError),
+	}
+
 	r.Handle("/v1/metrics", promhttp.Handler())
 	r.Handle("/v1/calendar", requireAuthenticatedUser(httpTransport.NewServer(
 		ep.AddEventEndpoint,
 		decodeHTTPAddEventRequest,
-		encodeResponse))).Methods(http.MethodPost)
+		encodeResponse,
+		options...))).Methods(http.MethodPost)
 
 	r.Handle("/v1/calendar", requireAuthenticatedUser(httpTransport.NewServer(
 		ep.ListEventEndpoint,
 		decodeHTTPListEventRequest,
-		encodeResponse))).Methods(http.MethodGet)
+		encodeResponse,
+		options...))).Methods(http.MethodGet)
+
+	r.Handle("/v1/calendar.ics", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.ExportEventsEndpoint,
+		decodeHTTPExportEventsRequest,
+		encodeICSResponse,
+		options...))).Methods(http.MethodGet)
+
+	r.Handle("/v1/calendar/import", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.ImportEventsEndpoint,
+		decodeHTTPImportEventsRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.Handle("/v1/calendar/batch", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.BatchCreateEventsEndpoint,
+		decodeHTTPBatchCreateEventsRequest(maxBatchSize),
+		encodeResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.Handle("/v1/calendar/batch/delete", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.BatchDeleteEventsEndpoint,
+		decodeHTTPBatchDeleteEventsRequest(maxBatchSize),
+		encodeResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.Handle("/v1/calendar/stream", requireAuthenticatedUser(
+		newStreamHandler(ep.WatchEventsEndpoint, streamHeartbeat, cfg.MaxStreamsPerUser))).Methods(http.MethodGet)
+
+	r.Handle("/v1/calendar/search", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.SearchEventsEndpoint,
+		decodeHTTPSearchEventsRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodGet)
+
+	r.Handle("/v1/calendar/{id}", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.GetEventEndpoint,
+		decodeHTTPGetEventRequest,
+		encodeEventResponse,
+		options...))).Methods(http.MethodGet)
+
+	r.Handle("/v1/calendar/{id}", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.UpdateEventEndpoint,
+		decodeHTTPUpdateEventRequest,
+		encodeEventResponse,
+		options...))).Methods(http.MethodPatch)
 
 	r.Handle("/v1/calendar/{id}", requireAuthenticatedUser(httpTransport.NewServer(
 		ep.DeleteEventEndpoint,
 		decodeHTTPDeleteEventRequest,
-		encodeResponse))).Methods(http.MethodDelete)
+		encodeResponse,
+		options...))).Methods(http.MethodDelete)
+
+	r.Handle("/v1/calendar/{id}/attendees", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.InviteAttendeesEndpoint,
+		decodeHTTPInviteAttendeesRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.Handle("/v1/calendar/{id}/rsvp", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.RespondEventEndpoint,
+		decodeHTTPRespondEventRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.Handle("/v1/calendar/{id}/history", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.ListHistoryEndpoint,
+		decodeHTTPListHistoryRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodGet)
+
+	r.Handle("/v1/calendar/{id}/history/{entry_id}/revert", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.RevertEventEndpoint,
+		decodeHTTPRevertEventRequest,
+		encodeEventResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.Handle("/v1/calendar/{id}/attachments", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.AddAttachmentEndpoint,
+		decodeHTTPAddAttachmentRequest,
+		encodeEventResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.Handle("/v1/calendar/{id}/attachments/{attachment_id}", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.GetAttachmentEndpoint,
+		decodeHTTPGetAttachmentRequest,
+		encodeAttachmentResponse,
+		options...))).Methods(http.MethodGet)
+
+	r.Handle("/v1/calendar/{id}/attachments/{attachment_id}", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.DeleteAttachmentEndpoint,
+		decodeHTTPDeleteAttachmentRequest,
+		encodeEventResponse,
+		options...))).Methods(http.MethodDelete)
+
+	r.Handle("/v1/trash", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.ListTrashEndpoint,
+		decodeHTTPListTrashRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodGet)
+
+	r.Handle("/v1/trash/{id}/restore", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.RestoreEventEndpoint,
+		decodeHTTPRestoreEventRequest,
+		encodeEventResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.Handle("/v1/trash/{id}", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.PurgeEventEndpoint,
+		decodeHTTPPurgeEventRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodDelete)
+
+	r.Handle("/v1/tags", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.ListTagsEndpoint,
+		decodeHTTPListTagsRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodGet)
+
+	r.Handle("/v1/calendars", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.CreateCalendarEndpoint,
+		decodeHTTPCreateCalendarRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.Handle("/v1/calendars", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.ListCalendarsEndpoint,
+		decodeHTTPListCalendarsRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodGet)
+
+	r.Handle("/v1/calendars/{id}", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.UpdateCalendarEndpoint,
+		decodeHTTPUpdateCalendarRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodPatch)
+
+	r.Handle("/v1/calendars/{id}", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.DeleteCalendarEndpoint,
+		decodeHTTPDeleteCalendarRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodDelete)
+
+	r.Handle("/v1/calendars/{id}/acl", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.GrantAccessEndpoint,
+		decodeHTTPGrantAccessRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.Handle("/v1/calendars/{id}/acl", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.ListGrantsEndpoint,
+		decodeHTTPListGrantsRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodGet)
+
+	r.Handle("/v1/calendars/{id}/acl/{user_id}", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.RevokeAccessEndpoint,
+		decodeHTTPRevokeAccessRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodDelete)
+
+	r.Handle("/v1/freebusy", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.FreeBusyEndpoint,
+		decodeHTTPFreeBusyRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.Handle("/v1/freebusy/slots", requireAuthenticatedUser(httpTransport.NewServer(
+		ep.FindMeetingSlotsEndpoint,
+		decodeHTTPFindMeetingSlotsRequest,
+		encodeResponse,
+		options...))).Methods(http.MethodPost)
+
+	r.PathPrefix(davPrincipalPath).Handler(requireDAVUser(newDAVHandler(ep)))
+	r.Handle(strings.TrimSuffix(davPrincipalPath, "/"), http.RedirectHandler(davPrincipalPath, http.StatusMovedPermanently))
+	r.Handle("/.well-known/caldav", http.RedirectHandler(davPrincipalPath, http.StatusMovedPermanently))
 
 	r.Handle("/v1/signup", httpTransport.NewServer(
 		ep.SignUpEndpoint,
 		decodeSignUpRequest,
-		encodeResponse)).Methods(http.MethodPost)
+		encodeResponse,
+		options...)).Methods(http.MethodPost)
 
 	r.Handle("/v1/login", httpTransport.NewServer(
 		ep.LoginEndpoint,
 		decodeLoginRequest,
-		encodeResponse)).Methods(http.MethodPost)
+		encodeResponse,
+		options...)).Methods(http.MethodPost)
 
 	r.Handle("/v1/logout", httpTransport.NewServer(
 		ep.LogoutEndpoint,
 		decodeLogoutRequest,
-		encodeResponse)).Methods(http.MethodPost)
+		encodeResponse,
+		options...)).Methods(http.MethodPost)
 
 	return r
 }
@@ -60,10 +287,186 @@ func NewHTTPHandler(ep endpoints.Set) http.Handler {
 func decodeHTTPListEventRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req endpoints.ListEventRequest
 
+	var err error
+	req.Filter.From, req.Filter.To, err = readTimeWindow(r)
+	if err != nil {
+		return nil, err
+	}
+	req.Page, err = readPage(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range r.URL.Query()["calendar_id"] {
+		calendarId, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return nil, errors.Wrap(errBadRequest, "invalid calendar id")
+		}
+		req.Filter.CalendarIds = append(req.Filter.CalendarIds, calendarId)
+	}
+	req.Filter.Tags = r.URL.Query()["tag"]
+
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPExportEventsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.ExportEventsRequest
+
+	var err error
+	req.Filter.From, req.Filter.To, err = readTimeWindow(r)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Filter.From.IsZero() && !req.Filter.To.IsZero() && !req.Filter.To.After(req.Filter.From) {
+		return nil, errors.Wrap(errBadRequest, "to must be after from")
+	}
+
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+// maxImportBytes limits the size of the imported iCalendar files
+const maxImportBytes = 10 << 20
+
+// decodeHTTPImportEventsRequest reads the iCalendar file from the body, or from the file
+// field of a multipart form
+func decodeHTTPImportEventsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.ImportEventsRequest
+
+	r.Body = http.MaxBytesReader(nil, r.Body, maxImportBytes)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, errors.Wrap(errBadRequest, "file must be provided")
+		}
+		defer file.Close()
+		body = file
+	}
+
+	var err error
+	req.Events, err = ical.Decode(body)
+	if err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+	if len(req.Events) == 0 {
+		return nil, errors.Wrap(errBadRequest, "no event to import")
+	}
+
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+// decodeHTTPBatchCreateEventsRequest reads the events to create, the batch must not be larger
+// than the max batch size
+func decodeHTTPBatchCreateEventsRequest(maxBatchSize int) httpTransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req endpoints.BatchCreateEventsRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return nil, errors.Wrap(errBadRequest, err.Error())
+		}
+		if err = checkBatchSize("events", len(req.Events), maxBatchSize); err != nil {
+			return nil, err
+		}
+
+		req.UserId = context2.GetUser(r).UserID
+		return req, nil
+	}
+}
+
+// decodeHTTPBatchDeleteEventsRequest reads the ids of the events to delete, the batch must not be
+// larger than the max batch size
+func decodeHTTPBatchDeleteEventsRequest(maxBatchSize int) httpTransport.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		var req endpoints.BatchDeleteEventsRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			return nil, errors.Wrap(errBadRequest, err.Error())
+		}
+		if err = checkBatchSize("event_ids", len(req.EventIds), maxBatchSize); err != nil {
+			return nil, err
+		}
+
+		req.UserId = context2.GetUser(r).UserID
+		return req, nil
+	}
+}
+
+func checkBatchSize(key string, size, maxBatchSize int) error {
+	if size == 0 {
+		return errors.Wrap(errBadRequest, key+" must be provided")
+	}
+	if size > maxBatchSize {
+		return errors.Wrap(errBadRequest, fmt.Sprintf("%s must not have more than %d items", key, maxBatchSize))
+	}
+	return nil
+}
+
+func decodeHTTPSearchEventsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.SearchEventsRequest
+
+	req.Query = strings.TrimSpace(r.URL.Query().Get("q"))
+	if req.Query == "" {
+		return nil, errors.Wrap(errBadRequest, "q must be provided")
+	}
+
+	var err error
+	req.Filter.From, req.Filter.To, err = readTimeWindow(r)
+	if err != nil {
+		return nil, err
+	}
+	req.Page, err = readPage(r)
+	if err != nil {
+		return nil, err
+	}
+	req.Filter.Tags = r.URL.Query()["tag"]
+
 	req.UserId = context2.GetUser(r).UserID
 	return req, nil
 }
 
+func decodeHTTPListTagsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.ListTagsRequest
+
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+// readPage reads the optional page_size and page_token query parameters
+func readPage(r *http.Request) (calendar.Page, error) {
+	var page calendar.Page
+
+	if value := r.URL.Query().Get("page_size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size < 0 {
+			return page, errors.Wrap(errBadRequest, "page_size must be a positive integer")
+		}
+		page.Size = size
+	}
+	page.Token = r.URL.Query().Get("page_token")
+
+	return page, nil
+}
+
+// readTimeWindow reads the optional from and to query parameters in RFC 3339 format
+func readTimeWindow(r *http.Request) (time.Time, time.Time, error) {
+	var window [2]time.Time
+	for i, key := range []string{"from", "to"} {
+		value := r.URL.Query().Get(key)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.Wrapf(errBadRequest, "%s must be in RFC 3339 format", key)
+		}
+		window[i] = t
+	}
+	return window[0], window[1], nil
+}
+
 func decodeHTTPAddEventRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req endpoints.AddEventRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
@@ -74,19 +477,386 @@ func decodeHTTPAddEventRequest(_ context.Context, r *http.Request) (interface{},
 	return req, nil
 }
 
+func decodeHTTPGetEventRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.GetEventRequest
+
+	req.EventId = mux.Vars(r)["id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPUpdateEventRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var body struct {
+		Event json.RawMessage `json:"event"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+
+	var req endpoints.UpdateEventRequest
+	if err = json.Unmarshal(body.Event, &req.Event); err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+
+	// Only the fields given in the body are updated
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(body.Event, &fields); err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+	for field := range fields {
+		if field == "id" || field == "user_id" || field == "recurring_event_id" || field == "recurrence_id" ||
+			field == "version" {
+			continue
+		}
+		req.Fields = append(req.Fields, field)
+	}
+	if len(req.Fields) == 0 {
+		return nil, errors.Wrap(errBadRequest, "no event field to update")
+	}
+	sort.Strings(req.Fields)
+
+	req.Event.Id, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, errors.Wrap(errBadRequest, "invalid event id")
+	}
+	req.Target, err = readTarget(r)
+	if err != nil {
+		return nil, err
+	}
+	req.Event.Version, err = readVersion(r)
+	if err != nil {
+		return nil, err
+	}
+	req.Event.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
 func decodeHTTPDeleteEventRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req endpoints.DeleteEventRequest
 
 	var err error
 	req.EventId = mux.Vars(r)["id"]
+	req.Target, err = readTarget(r)
 	if err != nil {
 		logger.Log("err", err)
 		return nil, err
 	}
+	req.Version, err = readVersion(r)
+	if err != nil {
+		return nil, err
+	}
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPListTrashRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.ListTrashRequest
+
+	var err error
+	req.Page, err = readPage(r)
+	if err != nil {
+		return nil, err
+	}
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPRestoreEventRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.RestoreEventRequest
+
+	req.EventId = mux.Vars(r)["id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPPurgeEventRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.PurgeEventRequest
+
+	req.EventId = mux.Vars(r)["id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPListHistoryRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.ListHistoryRequest
+
+	var err error
+	req.Page, err = readPage(r)
+	if err != nil {
+		return nil, err
+	}
+	req.EventId = mux.Vars(r)["id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPRevertEventRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.RevertEventRequest
+
+	req.EventId = mux.Vars(r)["id"]
+	req.EntryId = mux.Vars(r)["entry_id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+// maxAttachmentFormBytes limits the size of the forms of the attachments, the form has the
+// boundaries and the headers of its part besides the file
+const maxAttachmentFormBytes = calendar.MaxAttachmentSize + 64<<10
+
+// decodeHTTPAddAttachmentRequest reads the attachment from the file field of a multipart form.
+// The type of the file is taken from its part, or from its extension if the part does not have one.
+func decodeHTTPAddAttachmentRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.AddAttachmentRequest
+
+	if r.ContentLength > maxAttachmentFormBytes {
+		return nil, errPayloadTooLarge
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, maxAttachmentFormBytes)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, errors.Wrap(errBadRequest, "file must be provided")
+	}
+	defer file.Close()
+
+	if header.Size > calendar.MaxAttachmentSize {
+		return nil, errPayloadTooLarge
+	}
+	req.Attachment.Name = filepath.Base(header.Filename)
+
+	contentType := header.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = mime.TypeByExtension(filepath.Ext(req.Attachment.Name))
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !calendar.AttachmentTypes[mediaType] {
+		return nil, errUnsupportedType
+	}
+	req.Attachment.ContentType = mediaType
+
+	if req.Content, err = io.ReadAll(file); err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+
+	req.EventId = mux.Vars(r)["id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPGetAttachmentRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.GetAttachmentRequest
+
+	req.EventId = mux.Vars(r)["id"]
+	req.AttachmentId = mux.Vars(r)["attachment_id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPDeleteAttachmentRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.DeleteAttachmentRequest
+
+	req.EventId = mux.Vars(r)["id"]
+	req.AttachmentId = mux.Vars(r)["attachment_id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPInviteAttendeesRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.InviteAttendeesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+
+	req.EventId = mux.Vars(r)["id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPRespondEventRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.RespondEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+
+	req.EventId = mux.Vars(r)["id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPCreateCalendarRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.CreateCalendarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+
+	req.Calendar.Id, req.Calendar.Default = primitive.NilObjectID, false
+	req.Calendar.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPListCalendarsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.ListCalendarsRequest
+
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPUpdateCalendarRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var body struct {
+		Calendar json.RawMessage `json:"calendar"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+
+	var req endpoints.UpdateCalendarRequest
+	if err = json.Unmarshal(body.Calendar, &req.Calendar); err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+
+	// Only the fields given in the body are updated
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(body.Calendar, &fields); err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+	for field := range fields {
+		if field == "id" || field == "user_id" {
+			continue
+		}
+		req.Fields = append(req.Fields, field)
+	}
+	if len(req.Fields) == 0 {
+		return nil, errors.Wrap(errBadRequest, "no calendar field to update")
+	}
+	sort.Strings(req.Fields)
+
+	req.Calendar.Id, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, errors.Wrap(errBadRequest, "invalid calendar id")
+	}
+	req.Calendar.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPDeleteCalendarRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.DeleteCalendarRequest
+
+	req.CalendarId = mux.Vars(r)["id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPGrantAccessRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.GrantAccessRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+
+	req.Grant.CalendarId, err = primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		return nil, errors.Wrap(errBadRequest, "invalid calendar id")
+	}
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPListGrantsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.ListGrantsRequest
+
+	req.CalendarId = mux.Vars(r)["id"]
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPRevokeAccessRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.RevokeAccessRequest
+
+	granteeId, err := strconv.ParseUint(mux.Vars(r)["user_id"], 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(errBadRequest, "invalid user id")
+	}
+	req.CalendarId = mux.Vars(r)["id"]
+	req.GranteeId = granteeId
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+func decodeHTTPFreeBusyRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.FreeBusyRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+
 	req.UserId = context2.GetUser(r).UserID
 	return req, nil
 }
 
+func decodeHTTPFindMeetingSlotsRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.FindMeetingSlotsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, errors.Wrap(errBadRequest, err.Error())
+	}
+
+	req.UserId = context2.GetUser(r).UserID
+	return req, nil
+}
+
+// readTarget reads the optional scope and recurrence_id query parameters which select
+// the occurrences of a recurring event
+func readTarget(r *http.Request) (calendar.Target, error) {
+	var target calendar.Target
+
+	switch r.URL.Query().Get("scope") {
+	case "", "all":
+		return target, nil
+	case "this":
+		target.Scope = calendar.ThisOccurrence
+	case "following":
+		target.Scope = calendar.ThisAndFollowing
+	default:
+		return target, errors.Wrap(errBadRequest, "scope must be one of all, this or following")
+	}
+
+	recurrenceId, err := time.Parse(time.RFC3339, r.URL.Query().Get("recurrence_id"))
+	if err != nil {
+		return target, errors.Wrap(errBadRequest, "recurrence_id must be in RFC 3339 format")
+	}
+	target.RecurrenceId = recurrenceId
+	return target, nil
+}
+
+// readVersion reads the required If-Match header which holds the ETag of the event version that
+// the change is based on. The wildcard matches any version, so does the version of the events
+// which have not been changed since the versions are added.
+func readVersion(r *http.Request) (int64, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" {
+		return 0, errPreconditionRequired
+	}
+	if value == "*" {
+		return 0, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, errors.Wrap(errBadRequest, "If-Match must be the ETag of the event")
+	}
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version < 0 {
+		return 0, errors.Wrap(errBadRequest, "If-Match must be the ETag of the event")
+	}
+	return version, nil
+}
+
+// eventETag is the entity tag of the version of the event
+func eventETag(event *calendar.Event) string {
+	return `"` + strconv.FormatInt(event.Version, 10) + `"`
+}
+
 func decodeSignUpRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	var req endpoints.SignUpRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
@@ -123,19 +893,97 @@ func encodeResponse(ctx context.Context, w http.ResponseWriter, response interfa
 	return json.NewEncoder(w).Encode(response)
 }
 
+// encodeEventResponse writes the event with the ETag of its version, the changes of the event
+// give it back in the If-Match header
+func encodeEventResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	var event *calendar.Event
+	switch resp := response.(type) {
+	case endpoints.GetEventResponse:
+		event = resp.Event
+	case endpoints.UpdateEventResponse:
+		event = resp.Event
+	case endpoints.RestoreEventResponse:
+		event = resp.Event
+	case endpoints.RevertEventResponse:
+		event = resp.Event
+	case endpoints.AddAttachmentResponse:
+		event = resp.Event
+	case endpoints.DeleteAttachmentResponse:
+		event = resp.Event
+	}
+	if event != nil {
+		w.Header().Set("ETag", eventETag(event))
+	}
+	return encodeResponse(ctx, w, response)
+}
+
+// encodeAttachmentResponse writes the content of the attachment as a download. The browsers must
+// not guess another type for the content, so an uploaded file is never run as a page.
+func encodeAttachmentResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(endpoints.GetAttachmentResponse)
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": resp.Attachment.Name})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+
+	w.Header().Set("Content-Type", resp.Attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(resp.Content)))
+	w.Header().Set("Content-Disposition", disposition)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, err := w.Write(resp.Content)
+	return err
+}
+
+// encodeICSResponse writes the exported events as an iCalendar file
+func encodeICSResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	resp := response.(endpoints.ExportEventsResponse)
+
+	stamp := time.Now()
+	events := make([]ical.Event, len(resp.Events))
+	for i, event := range resp.Events {
+		events[i] = calendar.ToICal(event, stamp)
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="calendar.ics"`)
+	return ical.Encode(w, events)
+}
+
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 
-	switch err {
-	case repository.ErrRecordNotFound:
+	switch {
+	case errors.Is(err, repository.ErrRecordNotFound), errors.Is(err, calendar.ErrRecordNotFound):
 		w.WriteHeader(http.StatusNotFound)
-	case repository.ErrDuplicateEmail:
+	case errors.Is(err, repository.ErrDuplicateEmail), errors.Is(err, errBadRequest),
+		errors.Is(err, calendar.ErrInvalidPageToken), errors.Is(err, calendar.ErrNotOccurrence),
+		errors.Is(err, calendar.ErrValidation):
 		w.WriteHeader(http.StatusBadRequest)
+	case errors.Is(err, calendar.ErrNotOrganizer), errors.Is(err, calendar.ErrAccessDenied):
+		w.WriteHeader(http.StatusForbidden)
+	case errors.Is(err, calendar.ErrDefaultCalendar), errors.Is(err, calendar.ErrConflict):
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, calendar.ErrVersionMismatch):
+		w.WriteHeader(http.StatusPreconditionFailed)
+	case errors.Is(err, errPreconditionRequired):
+		w.WriteHeader(http.StatusPreconditionRequired)
+	case errors.Is(err, errTooManyStreams):
+		w.WriteHeader(http.StatusTooManyRequests)
+	case errors.Is(err, errPayloadTooLarge):
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	case errors.Is(err, errUnsupportedType):
+		w.WriteHeader(http.StatusUnsupportedMediaType)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{"error": err})
+	body := map[string]interface{}{"err": err.Error()}
+	var conflict *calendar.ConflictError
+	if errors.As(err, &conflict) {
+		body["conflicts"] = conflict.Conflicts
+	}
+	json.NewEncoder(w).Encode(body)
 }
 
 var logger log.Logger
@@ -10,7 +10,6 @@ import (
 	"github.com/3n0ugh/kalenderium/internal/validator"
 	"github.com/3n0ugh/kalenderium/pkg/account/pb"
 	repo "github.com/3n0ugh/kalenderium/pkg/account/repository"
-	httpTransport "github.com/go-kit/kit/transport/http"
 	"github.com/tomasen/realip"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
@@ -96,6 +95,12 @@ func authentication(next http.Handler) http.Handler {
 
 		headerParts := strings.Split(authorizationHeader, " ")
 
+		// The CalDAV clients can authenticate with the email and password of the user
+		if len(headerParts) == 2 && headerParts[0] == "Basic" && strings.HasPrefix(r.URL.Path, davPrincipalPath) {
+			basicAuthentication(next, w, r)
+			return
+		}
+
 		if len(headerParts) != 2 || headerParts[0] != "Bearer" {
 			w.Write([]byte(fmt.Sprintf("%s\n", headerParts)))
 			errs.InvalidAuthenticationTokenResponse(w)
@@ -111,17 +116,7 @@ func authentication(next http.Handler) http.Handler {
 			return
 		}
 
-		var cfg config.WebApiServiceConfigurations
-		err := config.GetConfigByKey("web_api_service", &cfg)
-		if err != nil {
-			logger.Log("msg", "failed to get config", "err", err)
-		}
-
-		var grpcAddr = net.JoinHostPort(cfg.AccountServiceHost, cfg.AccountServicePort) // account service
-		conn, err := grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-		if err != nil {
-			log.Fatalf("fail to dial: %v", err)
-		}
+		conn := dialAccountService()
 		defer conn.Close()
 
 		client := pb.NewAccountClient(conn)
@@ -154,6 +149,63 @@ func authentication(next http.Handler) http.Handler {
 	})
 }
 
+// basicAuthentication checks the email and password of the user with the account service
+func basicAuthentication(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	email, password, ok := r.BasicAuth()
+	if !ok {
+		errs.InvalidCredentialsResponse(w)
+		return
+	}
+
+	userId, err := checkPassword(email, password)
+	if err != nil {
+		errs.InvalidCredentialsResponse(w)
+		return
+	}
+
+	usr := &repo.User{
+		UserID: userId,
+		Email:  email,
+	}
+	r = contx.SetUser(r, usr)
+
+	next.ServeHTTP(w, r)
+}
+
+// checkPassword returns the id of the user with the email and password from the account service
+var checkPassword = func(email, password string) (uint64, error) {
+	conn := dialAccountService()
+	defer conn.Close()
+
+	client := pb.NewAccountClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	resp, err := client.CheckPassword(ctx, &pb.CheckPasswordRequest{
+		User: &pb.User{Email: email, Password: password},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return resp.UserId, nil
+}
+
+// dialAccountService connects to the account service
+func dialAccountService() *grpc.ClientConn {
+	var cfg config.WebApiServiceConfigurations
+	err := config.GetConfigByKey("web_api_service", &cfg)
+	if err != nil {
+		logger.Log("msg", "failed to get config", "err", err)
+	}
+
+	var grpcAddr = net.JoinHostPort(cfg.AccountServiceHost, cfg.AccountServicePort) // account service
+	conn, err := grpc.Dial(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("fail to dial: %v", err)
+	}
+	return conn
+}
+
 func secureHeaders(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("X-XSS-Protection", "1; mode=block")
@@ -163,7 +215,7 @@ func secureHeaders(next http.Handler) http.Handler {
 	})
 }
 
-func requireAuthenticatedUser(next *httpTransport.Server) http.HandlerFunc {
+func requireAuthenticatedUser(next http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		user := contx.GetUser(r)
 
@@ -176,6 +228,20 @@ func requireAuthenticatedUser(next *httpTransport.Server) http.HandlerFunc {
 	}
 }
 
+// requireDAVUser asks the CalDAV clients for the credentials of the user
+func requireDAVUser(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := contx.GetUser(r)
+
+		if user.IsAnonymous() {
+			errs.InvalidCredentialsResponse(w)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
 func enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Vary", "Origin")
@@ -186,13 +252,14 @@ func enableCORS(next http.Handler) http.Handler {
 
 		if origin == "localhost:8080" {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Expose-Headers", "ETag")
 
 			if r.Method == http.MethodOptions &&
 				w.Header().Get("Access-Control-Request-Method") != "" {
 				w.Header().Set("Access-Control-Allow-Methods",
 					"OPTIONS, PUT, PATCH, DELETE")
 				w.Header().Set("Access-Control-Allow-Headers",
-					"Authorization, Content-Type")
+					"Authorization, Content-Type, If-Match, Last-Event-ID")
 
 				w.WriteHeader(http.StatusOK)
 				return
@@ -0,0 +1,169 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	context2 "github.com/3n0ugh/kalenderium/internal/context"
+	calendar "github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/3n0ugh/kalenderium/pkg/web-api/endpoints"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/pkg/errors"
+)
+
+// The defaults of the event streams which are used when they are not configured
+const (
+	defaultStreamHeartbeat   = 15 * time.Second
+	defaultMaxStreamsPerUser = 5
+)
+
+// streamRetry is the time in milliseconds which the clients wait before they reconnect
+const streamRetry = 3000
+
+// errTooManyStreams is returned when the user already has the max number of open streams
+var errTooManyStreams = errors.New("too many open event streams")
+
+// streamHandler streams the changes of the events of the authenticated user as Server-Sent
+// Events. The id of an event is the resume token of the change, so the clients which reconnect
+// with the Last-Event-ID header get the changes which they missed. The number of the open
+// streams of a user is limited in each web-api instance.
+type streamHandler struct {
+	ep         endpoint.Endpoint
+	heartbeat  time.Duration
+	maxStreams int
+
+	mu      sync.Mutex
+	streams map[uint64]int
+}
+
+func newStreamHandler(ep endpoint.Endpoint, heartbeat time.Duration, maxStreams int) *streamHandler {
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+	if maxStreams <= 0 {
+		maxStreams = defaultMaxStreamsPerUser
+	}
+	return &streamHandler{
+		ep:         ep,
+		heartbeat:  heartbeat,
+		maxStreams: maxStreams,
+		streams:    make(map[uint64]int),
+	}
+}
+
+func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		encodeError(r.Context(), errors.New("streaming is not supported"), w)
+		return
+	}
+
+	resumeToken := r.Header.Get("Last-Event-ID")
+	if resumeToken != "" {
+		if _, err := calendar.DecodeResumeToken(resumeToken); err != nil {
+			encodeError(r.Context(), err, w)
+			return
+		}
+	}
+
+	userId := context2.GetUser(r).UserID
+	if !h.acquire(userId) {
+		encodeError(r.Context(), errTooManyStreams, w)
+		return
+	}
+	defer h.release(userId)
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// The watch runs until the client goes away, its notifications are written here with the
+	// heartbeats so the writes do not race
+	notifications := make(chan calendar.Notification)
+	done := make(chan error, 1)
+	go func() {
+		_, err := h.ep(ctx, endpoints.WatchEventsRequest{
+			UserId:      userId,
+			ResumeToken: resumeToken,
+			Send: func(notification calendar.Notification) error {
+				select {
+				case notifications <- notification:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			},
+		})
+		done <- err
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// The proxies must not buffer the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case notification := <-notifications:
+			err = writeNotification(w, notification)
+		case <-heartbeat.C:
+			// The comments keep the idle connections open through the proxies
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		case err = <-done:
+			// The client reconnects with the last event id when the watch ends
+			if err != nil && ctx.Err() == nil {
+				logger.Log("msg", "event stream is ended", "user_id", userId, "err", err)
+			}
+			return
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeNotification writes the notification as an event of the type of the change
+func writeNotification(w io.Writer, notification calendar.Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", notification.Token, notification.Type, data)
+	return err
+}
+
+// acquire counts the new stream of the user, it is false if the user has the max number of streams
+func (h *streamHandler) acquire(userId uint64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.streams[userId] >= h.maxStreams {
+		return false
+	}
+	h.streams[userId]++
+	return true
+}
+
+// release counts the closed stream of the user
+func (h *streamHandler) release(userId uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.streams[userId]--; h.streams[userId] <= 0 {
+		delete(h.streams, userId)
+	}
+}
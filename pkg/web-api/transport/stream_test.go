@@ -0,0 +1,254 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	context2 "github.com/3n0ugh/kalenderium/internal/context"
+	repo "github.com/3n0ugh/kalenderium/pkg/account/repository"
+	calendar "github.com/3n0ugh/kalenderium/pkg/calendar/repository"
+	"github.com/3n0ugh/kalenderium/pkg/web-api/endpoints"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// stubWatch is the WatchEvents endpoint of the stream tests. It reports the requests of the
+// watches, sends the notifications which are given to it, and reports the ends of the watches.
+type stubWatch struct {
+	requests      chan endpoints.WatchEventsRequest
+	notifications chan calendar.Notification
+	ended         chan struct{}
+}
+
+func newStubWatch() *stubWatch {
+	return &stubWatch{
+		requests:      make(chan endpoints.WatchEventsRequest, 10),
+		notifications: make(chan calendar.Notification),
+		ended:         make(chan struct{}, 10),
+	}
+}
+
+func (s *stubWatch) endpoint(ctx context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.WatchEventsRequest)
+	s.requests <- req
+	defer func() { s.ended <- struct{}{} }()
+
+	for {
+		select {
+		case notification := <-s.notifications:
+			if err := req.Send(notification); err != nil {
+				return endpoints.WatchEventsResponse{Err: err.Error()}, nil
+			}
+		case <-ctx.Done():
+			return endpoints.WatchEventsResponse{}, nil
+		}
+	}
+}
+
+// streamServer serves the stream handler to the user of the X-User-Id header
+func streamServer(h http.Handler) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId, _ := strconv.ParseUint(r.Header.Get("X-User-Id"), 10, 64)
+		h.ServeHTTP(w, context2.SetUser(r, &repo.User{UserID: userId}))
+	}))
+}
+
+// openStream opens the stream of the user, the stream is closed when the context is cancelled
+func openStream(ctx context.Context, t *testing.T, url string, userId uint64, lastEventId string) *http.Response {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("X-User-Id", strconv.FormatUint(userId, 10))
+	if lastEventId != "" {
+		r.Header.Set("Last-Event-ID", lastEventId)
+	}
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// readUntil reads the lines of the stream until the line which has the prefix
+func readUntil(t *testing.T, lines *bufio.Scanner, prefix string) string {
+	for lines.Scan() {
+		if strings.HasPrefix(lines.Text(), prefix) {
+			return lines.Text()
+		}
+	}
+	t.Fatalf("the stream is ended before %q: %v", prefix, lines.Err())
+	return ""
+}
+
+// watched waits for the next watch which the stub is called with
+func (s *stubWatch) watched(t *testing.T) endpoints.WatchEventsRequest {
+	select {
+	case req := <-s.requests:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("the watch is not started")
+	}
+	return endpoints.WatchEventsRequest{}
+}
+
+func TestStreamHandler_Heartbeat(t *testing.T) {
+	stub := newStubWatch()
+	server := streamServer(newStreamHandler(stub.endpoint, 10*time.Millisecond, 1))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resp := openStream(ctx, t, server.URL, 1, "")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Out -> Want: %d text/event-stream; Got: %d %s", http.StatusOK, resp.StatusCode,
+			resp.Header.Get("Content-Type"))
+	}
+	lines := bufio.NewScanner(resp.Body)
+	if line := readUntil(t, lines, "retry:"); line != "retry: 3000" {
+		t.Errorf("Retry -> Want: %q; Got: %q", "retry: 3000", line)
+	}
+	// The heartbeats are sent while there is no change
+	for i := 0; i < 2; i++ {
+		readUntil(t, lines, ": heartbeat")
+	}
+}
+
+func TestStreamHandler_LastEventId(t *testing.T) {
+	stub := newStubWatch()
+	server := streamServer(newStreamHandler(stub.endpoint, time.Hour, 1))
+	defer server.Close()
+
+	raw, _ := bson.Marshal(bson.M{"_data": "8262A1"})
+	lastEventId := calendar.EncodeResumeToken(raw)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resp := openStream(ctx, t, server.URL, 1, lastEventId)
+	defer resp.Body.Close()
+
+	// The watch is resumed after the last event which the client got
+	req := stub.watched(t)
+	if req.UserId != 1 || req.ResumeToken != lastEventId {
+		t.Errorf("Request -> Want: user 1 after %q; Got: user %d after %q", lastEventId, req.UserId, req.ResumeToken)
+	}
+
+	raw, _ = bson.Marshal(bson.M{"_data": "8262A2"})
+	notification := calendar.Notification{
+		Token: calendar.EncodeResumeToken(raw),
+		Type:  calendar.NotificationCreated,
+		Event: calendar.Event{Id: primitive.NewObjectID(), UserId: 1, Name: "Watched"},
+	}
+	stub.notifications <- notification
+
+	lines := bufio.NewScanner(resp.Body)
+	if line := readUntil(t, lines, "id:"); line != "id: "+notification.Token {
+		t.Errorf("Id -> Want: %q; Got: %q", "id: "+notification.Token, line)
+	}
+	if line := readUntil(t, lines, "event:"); line != "event: created" {
+		t.Errorf("Event -> Want: %q; Got: %q", "event: created", line)
+	}
+	if line := readUntil(t, lines, "data:"); !strings.Contains(line, `"name":"Watched"`) {
+		t.Errorf("Data -> Want: the event; Got: %q", line)
+	}
+}
+
+func TestStreamHandler_InvalidLastEventId(t *testing.T) {
+	stub := newStubWatch()
+	server := streamServer(newStreamHandler(stub.endpoint, time.Hour, 1))
+	defer server.Close()
+
+	resp := openStream(context.Background(), t, server.URL, 1, "invalid")
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Code -> Want: %d; Got: %d", http.StatusBadRequest, resp.StatusCode)
+	}
+	if len(stub.requests) != 0 {
+		t.Error("Watch -> Want: none; Got: the watch is started")
+	}
+}
+
+func TestStreamHandler_MaxStreams(t *testing.T) {
+	stub := newStubWatch()
+	server := streamServer(newStreamHandler(stub.endpoint, time.Hour, 1))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first := openStream(ctx, t, server.URL, 1, "")
+	defer first.Body.Close()
+	stub.watched(t)
+
+	tests := map[string]struct {
+		userId uint64
+		code   int
+	}{
+		"Same_User":  {userId: 1, code: http.StatusTooManyRequests},
+		"Other_User": {userId: 2, code: http.StatusOK},
+	}
+
+	for scenario, tt := range tests {
+		t.Run(scenario, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			resp := openStream(ctx, t, server.URL, tt.userId, "")
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.code {
+				t.Errorf("Code -> Want: %d; Got: %d", tt.code, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestStreamHandler_Disconnect(t *testing.T) {
+	stub := newStubWatch()
+	h := newStreamHandler(stub.endpoint, time.Hour, 1)
+	server := streamServer(h)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := openStream(ctx, t, server.URL, 1, "")
+	stub.watched(t)
+
+	// The watch is ended and the stream is not counted after the client goes away
+	cancel()
+	resp.Body.Close()
+	select {
+	case <-stub.ended:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the watch is not ended")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		h.mu.Lock()
+		streams := len(h.streams)
+		h.mu.Unlock()
+		if streams == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Streams -> Want: none; Got: %d", streams)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The user can open a new stream in place of it
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	resp = openStream(ctx, t, server.URL, 1, "")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Code -> Want: %d; Got: %d", http.StatusOK, resp.StatusCode)
+	}
+}
@@ -2,7 +2,9 @@ package web_api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/3n0ugh/kalenderium/internal/ical"
 	"github.com/3n0ugh/kalenderium/internal/token"
 	"github.com/3n0ugh/kalenderium/internal/validator"
 	pb2 "github.com/3n0ugh/kalenderium/pkg/account/pb"
@@ -11,7 +13,13 @@ import (
 	"github.com/3n0ugh/kalenderium/pkg/calendar/repository"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"io"
+	"sort"
+	"time"
 )
 
 type webApiService struct {
@@ -26,76 +34,939 @@ func NewWebApiService(calendarClient pb.CalendarClient, accountClient pb2.Accoun
 	}
 }
 
-func (w *webApiService) AddEvent(ctx context.Context, event repository.Event) (string, error) {
+// AddEvent adds the event, and returns the events of the organizer which it overlaps. The event
+// is not added with the reject policy if it overlaps any.
+func (w *webApiService) AddEvent(ctx context.Context, event repository.Event,
+	policy string) (string, []repository.Event, error) {
 	v := validator.New()
 	repository.ValidateEvent(v, event)
+	repository.ValidateConflictPolicy(v, policy)
 	if !v.Valid() {
-		return "", errors.New(fmt.Sprintf("failed to validate event: %v", v.Errors))
-	}
-
-	pEvent := &pb.Event{
-		Id:      event.Id.Hex(),
-		UserId:  event.UserId,
-		Name:    event.Name,
-		Details: event.Details,
-		Start:   timestamppb.New(event.Start),
-		End:     timestamppb.New(event.End),
-		Color:   event.Color,
+		return "", nil, repository.NewValidationError(fmt.Sprintf("failed to validate event: %v", v.Errors))
 	}
 
 	resp, err := w.calendarClient.CreateEvent(ctx, &pb.CreateEventRequest{
-		Event: pEvent,
+		Event:          toProtoEvent(event),
+		ConflictPolicy: policy,
 	})
 
 	if err != nil {
-		return resp.EventId, errors.New(fmt.Sprintf("failed to validate event: %v", v.Errors))
+		if status.Code(err) == codes.PermissionDenied {
+			return "", nil, repository.ErrAccessDenied
+		}
+		if status.Code(err) == codes.AlreadyExists {
+			return "", nil, conflictError(err)
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return "", nil, argumentError(err)
+		}
+		return "", nil, errors.Wrap(err, "failed to add event")
+	}
+
+	conflicts, err := fromProtoEvents(resp.Conflicts)
+	if err != nil {
+		return "", nil, err
 	}
+	return resp.EventId, conflicts, nil
+}
 
-	return resp.EventId, nil
+// conflictError converts the already exists error of the calendar service to the conflict error
+// with the conflicting events in its details
+func conflictError(err error) error {
+	conflict := &repository.ConflictError{}
+	for _, detail := range status.Convert(err).Details() {
+		if reply, ok := detail.(*pb.CreateEventReply); ok {
+			conflicts, err := fromProtoEvents(reply.Conflicts)
+			if err != nil {
+				return err
+			}
+			conflict.Conflicts = append(conflict.Conflicts, conflicts...)
+		}
+	}
+	return conflict
 }
 
-func (w *webApiService) ListEvent(ctx context.Context, userId uint64) ([]repository.Event, error) {
-	resp, err := w.calendarClient.ListEvent(ctx, &pb.ListEventRequest{
-		UserId: userId,
-	})
+func (w *webApiService) ListEvent(ctx context.Context, userId uint64, filter repository.EventFilter,
+	page repository.Page) ([]repository.Event, string, error) {
+	req := &pb.ListEventRequest{
+		UserId:    userId,
+		PageSize:  int32(page.Size),
+		PageToken: page.Token,
+	}
+	if !filter.From.IsZero() {
+		req.From = timestamppb.New(filter.From)
+	}
+	if !filter.To.IsZero() {
+		req.To = timestamppb.New(filter.To)
+	}
+	for _, calendarId := range filter.CalendarIds {
+		req.CalendarIds = append(req.CalendarIds, calendarId.Hex())
+	}
+	req.Tags, req.NoExpand = filter.Tags, filter.NoExpand
+
+	resp, err := w.calendarClient.ListEvent(ctx, req)
 	if err != nil {
-		return nil, errors.New("failed to list event")
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, "", argumentError(err)
+		}
+		if status.Code(err) == codes.NotFound {
+			return nil, "", repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return nil, "", repository.ErrAccessDenied
+		}
+		return nil, "", errors.New("failed to list event")
 	}
 
 	var events []repository.Event
 	for _, e := range resp.Events {
-		objId, err := primitive.ObjectIDFromHex(e.Id)
+		event, err := fromProtoEvent(e)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to convert object id")
-		}
-		event := repository.Event{
-			Id:      objId,
-			UserId:  e.UserId,
-			Name:    e.Name,
-			Details: e.Details,
-			Start:   e.Start.AsTime(),
-			End:     e.End.AsTime(),
-			Color:   e.Color,
+			return nil, "", err
 		}
 		events = append(events, event)
 	}
 
-	return events, nil
+	return events, resp.NextPageToken, nil
 }
 
-func (w *webApiService) DeleteEvent(ctx context.Context, eventId string, userId uint64) error {
-	_, err := w.calendarClient.DeleteEvent(ctx, &pb.DeleteEventRequest{
+func (w *webApiService) SearchEvents(ctx context.Context, userId uint64, query string, filter repository.EventFilter,
+	page repository.Page) ([]repository.Event, string, error) {
+	v := validator.New()
+	repository.ValidateSearchQuery(v, query)
+	if !v.Valid() {
+		return nil, "", repository.NewValidationError(fmt.Sprintf("failed to validate search query: %v", v.Errors))
+	}
+
+	req := &pb.SearchEventsRequest{
+		UserId:    userId,
+		Query:     query,
+		PageSize:  int32(page.Size),
+		PageToken: page.Token,
+		Tags:      filter.Tags,
+	}
+	if !filter.From.IsZero() {
+		req.From = timestamppb.New(filter.From)
+	}
+	if !filter.To.IsZero() {
+		req.To = timestamppb.New(filter.To)
+	}
+
+	resp, err := w.calendarClient.SearchEvents(ctx, req)
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, "", argumentError(err)
+		}
+		return nil, "", errors.New("failed to search events")
+	}
+
+	events, err := fromProtoEvents(resp.Events)
+	if err != nil {
+		return nil, "", err
+	}
+	return events, resp.NextPageToken, nil
+}
+
+func (w *webApiService) ListTags(ctx context.Context, userId uint64) ([]repository.TagCount, error) {
+	resp, err := w.calendarClient.ListTags(ctx, &pb.ListTagsRequest{UserId: userId})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list tags")
+	}
+
+	tags := make([]repository.TagCount, 0, len(resp.Tags))
+	for _, tag := range resp.Tags {
+		tags = append(tags, repository.TagCount{Tag: tag.Tag, Count: int(tag.Count)})
+	}
+	return tags, nil
+}
+
+func (w *webApiService) GetEvent(ctx context.Context, eventId string, userId uint64) (repository.Event, error) {
+	resp, err := w.calendarClient.GetEvent(ctx, &pb.GetEventRequest{
 		EventId: eventId,
 		UserId:  userId,
 	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		return repository.Event{}, errors.Wrap(err, "failed to get event")
+	}
+
+	return fromProtoEvent(resp.Event)
+}
 
+func (w *webApiService) UpdateEvent(ctx context.Context, event repository.Event, fields []string,
+	target repository.Target) (repository.Event, error) {
+	scope, recurrenceId := toProtoTarget(target)
+	resp, err := w.calendarClient.UpdateEvent(ctx, &pb.UpdateEventRequest{
+		Event:        toProtoEvent(event),
+		UpdateMask:   &fieldmaskpb.FieldMask{Paths: fields},
+		Scope:        scope,
+		RecurrenceId: recurrenceId,
+	})
 	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return repository.Event{}, permissionError(err)
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return repository.Event{}, argumentError(err)
+		}
+		if status.Code(err) == codes.FailedPrecondition {
+			return repository.Event{}, repository.ErrVersionMismatch
+		}
+		return repository.Event{}, errors.Wrap(err, "failed to update event")
+	}
+
+	return fromProtoEvent(resp.Event)
+}
+
+func (w *webApiService) DeleteEvent(ctx context.Context, eventId string, userId uint64, target repository.Target,
+	version int64) error {
+	scope, recurrenceId := toProtoTarget(target)
+	_, err := w.calendarClient.DeleteEvent(ctx, &pb.DeleteEventRequest{
+		EventId:      eventId,
+		UserId:       userId,
+		Scope:        scope,
+		RecurrenceId: recurrenceId,
+		Version:      version,
+	})
+
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return argumentError(err)
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return permissionError(err)
+		}
+		if status.Code(err) == codes.FailedPrecondition {
+			return repository.ErrVersionMismatch
+		}
 		return errors.New("failed to delete event")
 	}
 
 	return nil
 }
 
+// BatchCreateEvents adds the events of the user at once, and returns the result of each event in
+// the given order. The invalid events are not added, the others are added anyway.
+func (w *webApiService) BatchCreateEvents(ctx context.Context, userId uint64,
+	events []repository.Event) ([]repository.BatchResult, error) {
+	req := &pb.BatchCreateEventsRequest{UserId: userId}
+	for _, event := range events {
+		event.UserId = userId
+		req.Events = append(req.Events, toProtoEvent(event))
+	}
+
+	resp, err := w.calendarClient.BatchCreateEvents(ctx, req)
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, argumentError(err)
+		}
+		return nil, errors.Wrap(err, "failed to create events")
+	}
+	if len(resp.Results) != len(events) {
+		return nil, errors.New("failed to create events")
+	}
+	return fromProtoBatchResults(resp.Results), nil
+}
+
+// BatchDeleteEvents moves the events of the user to the trash at once, and returns the result of
+// each event in the given order
+func (w *webApiService) BatchDeleteEvents(ctx context.Context, userId uint64,
+	eventIds []string) ([]repository.BatchResult, error) {
+	resp, err := w.calendarClient.BatchDeleteEvents(ctx, &pb.BatchDeleteEventsRequest{
+		UserId:   userId,
+		EventIds: eventIds,
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, argumentError(err)
+		}
+		return nil, errors.Wrap(err, "failed to delete events")
+	}
+	if len(resp.Results) != len(eventIds) {
+		return nil, errors.New("failed to delete events")
+	}
+	return fromProtoBatchResults(resp.Results), nil
+}
+
+func fromProtoBatchResults(results []*pb.BatchResult) []repository.BatchResult {
+	batchResults := make([]repository.BatchResult, len(results))
+	for i, r := range results {
+		batchResults[i] = repository.BatchResult{EventId: r.EventId, Err: r.Err}
+	}
+	return batchResults
+}
+
+// ListTrash gets a page of the events which the user moved to the trash
+func (w *webApiService) ListTrash(ctx context.Context, userId uint64, page repository.Page) ([]repository.Event, string, error) {
+	resp, err := w.calendarClient.ListTrash(ctx, &pb.ListTrashRequest{
+		UserId:    userId,
+		PageSize:  int32(page.Size),
+		PageToken: page.Token,
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, "", argumentError(err)
+		}
+		return nil, "", errors.New("failed to list trash")
+	}
+
+	events, err := fromProtoEvents(resp.Events)
+	if err != nil {
+		return nil, "", err
+	}
+	return events, resp.NextPageToken, nil
+}
+
+// RestoreEvent takes the event of the user out of the trash
+func (w *webApiService) RestoreEvent(ctx context.Context, eventId string, userId uint64) (repository.Event, error) {
+	resp, err := w.calendarClient.RestoreEvent(ctx, &pb.RestoreEventRequest{
+		EventId: eventId,
+		UserId:  userId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		return repository.Event{}, errors.New("failed to restore event")
+	}
+
+	return fromProtoEvent(resp.Event)
+}
+
+// PurgeEvent permanently deletes the event of the user from the trash
+func (w *webApiService) PurgeEvent(ctx context.Context, eventId string, userId uint64) error {
+	_, err := w.calendarClient.PurgeEvent(ctx, &pb.PurgeEventRequest{
+		EventId: eventId,
+		UserId:  userId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.ErrRecordNotFound
+		}
+		return errors.New("failed to purge event")
+	}
+
+	return nil
+}
+
+// ListHistory gets a page of the changes of the event, the last change is the first
+func (w *webApiService) ListHistory(ctx context.Context, eventId string, userId uint64,
+	page repository.Page) ([]repository.HistoryEntry, string, error) {
+	resp, err := w.calendarClient.ListHistory(ctx, &pb.ListHistoryRequest{
+		EventId:   eventId,
+		UserId:    userId,
+		PageSize:  int32(page.Size),
+		PageToken: page.Token,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, "", repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, "", argumentError(err)
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return nil, "", permissionError(err)
+		}
+		return nil, "", errors.New("failed to list history")
+	}
+
+	entries := []repository.HistoryEntry{}
+	for _, e := range resp.Entries {
+		entry, err := fromProtoHistoryEntry(e)
+		if err != nil {
+			return nil, "", err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, resp.NextPageToken, nil
+}
+
+// RevertEvent sets the event back to its state after the change of the history entry
+func (w *webApiService) RevertEvent(ctx context.Context, eventId, entryId string, userId uint64) (repository.Event, error) {
+	resp, err := w.calendarClient.RevertEvent(ctx, &pb.RevertEventRequest{
+		EventId: eventId,
+		UserId:  userId,
+		EntryId: entryId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return repository.Event{}, permissionError(err)
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return repository.Event{}, argumentError(err)
+		}
+		return repository.Event{}, errors.Wrap(err, "failed to revert event")
+	}
+
+	return fromProtoEvent(resp.Event)
+}
+
+// WatchEvents sends the changes of the events which the user organizes or attends until the
+// context is done, starting after the resume token if it is given
+func (w *webApiService) WatchEvents(ctx context.Context, userId uint64, resumeToken string,
+	send func(repository.Notification) error) error {
+	stream, err := w.calendarClient.WatchEvents(ctx, &pb.WatchEventsRequest{
+		UserId:      userId,
+		ResumeToken: resumeToken,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to watch events")
+	}
+
+	for {
+		notification, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			if status.Code(err) == codes.InvalidArgument {
+				return argumentError(err)
+			}
+			return errors.Wrap(err, "failed to watch events")
+		}
+
+		event, err := fromProtoEvent(notification.Event)
+		if err != nil {
+			return err
+		}
+		err = send(repository.Notification{
+			Token:     notification.Token,
+			Type:      notification.Type,
+			UserId:    notification.UserId,
+			Event:     event,
+			CreatedAt: notification.CreatedAt.AsTime(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// InviteAttendees adds the attendees to the event which the user organizes
+func (w *webApiService) InviteAttendees(ctx context.Context, eventId string, userId uint64,
+	attendees []repository.Attendee) (repository.Event, error) {
+	v := validator.New()
+	v.Check(len(attendees) > 0, "attendees", "must be provided")
+	repository.ValidateAttendees(v, userId, attendees)
+	if !v.Valid() {
+		return repository.Event{}, repository.NewValidationError(fmt.Sprintf("failed to validate attendees: %v", v.Errors))
+	}
+
+	req := &pb.InviteAttendeesRequest{EventId: eventId, UserId: userId}
+	for _, attendee := range attendees {
+		req.Attendees = append(req.Attendees, &pb.Attendee{UserId: attendee.UserId, Email: attendee.Email})
+	}
+
+	resp, err := w.calendarClient.InviteAttendees(ctx, req)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return repository.Event{}, permissionError(err)
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return repository.Event{}, argumentError(err)
+		}
+		return repository.Event{}, errors.Wrap(err, "failed to invite attendees")
+	}
+
+	return fromProtoEvent(resp.Event)
+}
+
+// AddAttachment attaches the file to the event which the user organizes, or which is in a
+// calendar that the user can edit
+func (w *webApiService) AddAttachment(ctx context.Context, eventId string, userId uint64,
+	attachment repository.Attachment, content []byte) (repository.Event, error) {
+	attachment.Size = int64(len(content))
+
+	v := validator.New()
+	repository.ValidateAttachment(v, attachment)
+	if !v.Valid() {
+		return repository.Event{}, repository.NewValidationError(fmt.Sprintf("failed to validate attachment: %v", v.Errors))
+	}
+
+	resp, err := w.calendarClient.AddAttachment(ctx, &pb.AddAttachmentRequest{
+		EventId:    eventId,
+		UserId:     userId,
+		Attachment: &pb.Attachment{Name: attachment.Name, ContentType: attachment.ContentType},
+		Content:    content,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return repository.Event{}, permissionError(err)
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return repository.Event{}, argumentError(err)
+		}
+		return repository.Event{}, errors.Wrap(err, "failed to add attachment")
+	}
+
+	return fromProtoEvent(resp.Event)
+}
+
+// GetAttachment gets the attachment of the event which the user can see with its content
+func (w *webApiService) GetAttachment(ctx context.Context, eventId string, userId uint64,
+	attachmentId string) (repository.Attachment, []byte, error) {
+	resp, err := w.calendarClient.GetAttachment(ctx, &pb.GetAttachmentRequest{
+		EventId:      eventId,
+		UserId:       userId,
+		AttachmentId: attachmentId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.Attachment{}, nil, repository.ErrRecordNotFound
+		}
+		return repository.Attachment{}, nil, errors.Wrap(err, "failed to get attachment")
+	}
+
+	attachment, err := fromProtoAttachment(resp.Attachment)
+	if err != nil {
+		return repository.Attachment{}, nil, err
+	}
+	return attachment, resp.Content, nil
+}
+
+// DeleteAttachment removes the attachment from the event which the user organizes, or which is
+// in a calendar that the user can edit
+func (w *webApiService) DeleteAttachment(ctx context.Context, eventId string, userId uint64,
+	attachmentId string) (repository.Event, error) {
+	resp, err := w.calendarClient.DeleteAttachment(ctx, &pb.DeleteAttachmentRequest{
+		EventId:      eventId,
+		UserId:       userId,
+		AttachmentId: attachmentId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.Event{}, repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return repository.Event{}, permissionError(err)
+		}
+		return repository.Event{}, errors.Wrap(err, "failed to delete attachment")
+	}
+
+	return fromProtoEvent(resp.Event)
+}
+
+// RespondEvent sets the participation status of the user on the event which the user is invited to
+func (w *webApiService) RespondEvent(ctx context.Context, eventId string, userId uint64, rsvp string) error {
+	v := validator.New()
+	v.Check(validator.In(rsvp, repository.Responses...), "status", "must be accepted, declined or tentative")
+	if !v.Valid() {
+		return repository.NewValidationError(fmt.Sprintf("failed to validate response: %v", v.Errors))
+	}
+
+	_, err := w.calendarClient.RespondEvent(ctx, &pb.RespondEventRequest{
+		EventId: eventId,
+		UserId:  userId,
+		Status:  rsvp,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return argumentError(err)
+		}
+		return errors.Wrap(err, "failed to respond event")
+	}
+
+	return nil
+}
+
+// ExportEvents gets all the events of the user in the filter window. The recurring events are not
+// expanded, so they are followed by their overridden instances.
+func (w *webApiService) ExportEvents(ctx context.Context, userId uint64, filter repository.EventFilter) ([]repository.Event, error) {
+	filter.NoExpand = true
+
+	var events []repository.Event
+	var page repository.Page
+	for {
+		list, nextPageToken, err := w.ListEvent(ctx, userId, filter, page)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, list...)
+
+		if nextPageToken == "" {
+			return events, nil
+		}
+		page.Token = nextPageToken
+	}
+}
+
+// ImportEvents sends the decoded VEVENTs to the calendar service in batches, and returns the
+// result of each VEVENT in the given order. The malformed VEVENTs are not sent. The recurring and
+// single events are in the CalDAV resource, if it is given.
+func (w *webApiService) ImportEvents(ctx context.Context, userId uint64, events []ical.Event,
+	resource string) ([]repository.ImportResult, error) {
+	results := make([]repository.ImportResult, len(events))
+
+	var valid []int
+	for i, e := range events {
+		if e.Err != nil {
+			results[i] = repository.ImportResult{UID: e.UID, Err: e.Err.Error()}
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	// The recurring events have to be imported before their overridden instances
+	sort.SliceStable(valid, func(i, j int) bool {
+		return events[valid[i]].RecurrenceId.IsZero() && !events[valid[j]].RecurrenceId.IsZero()
+	})
+
+	for len(valid) > 0 {
+		n := len(valid)
+		if n > repository.MaxImportEvents {
+			n = repository.MaxImportEvents
+		}
+
+		req := &pb.ImportEventsRequest{UserId: userId}
+		for _, i := range valid[:n] {
+			event := repository.FromICal(events[i], userId)
+			if event.RecurrenceId == nil {
+				event.Resource = resource
+			}
+			req.Events = append(req.Events, toProtoEvent(event))
+		}
+
+		resp, err := w.calendarClient.ImportEvents(ctx, req)
+		if err != nil {
+			if status.Code(err) == codes.InvalidArgument {
+				return nil, argumentError(err)
+			}
+			return nil, errors.Wrap(err, "failed to import events")
+		}
+		if len(resp.Results) != n {
+			return nil, errors.New("failed to import events")
+		}
+
+		for j, i := range valid[:n] {
+			r := resp.Results[j]
+			results[i] = repository.ImportResult{UID: r.Uid, EventId: r.EventId, Created: r.Created, Err: r.Err}
+		}
+		valid = valid[n:]
+	}
+
+	for i, e := range events {
+		if !e.RecurrenceId.IsZero() {
+			recurrenceId := e.RecurrenceId
+			results[i].RecurrenceId = &recurrenceId
+		}
+	}
+	return results, nil
+}
+
+// GetEventByUID gets the event with the iCalendar UID, followed by its overridden instances
+func (w *webApiService) GetEventByUID(ctx context.Context, uid string, userId uint64) ([]repository.Event, error) {
+	resp, err := w.calendarClient.GetEventByUID(ctx, &pb.GetEventByUIDRequest{
+		Uid:    uid,
+		UserId: userId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, repository.ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get event")
+	}
+
+	return fromProtoEvents(append([]*pb.Event{resp.Event}, resp.Overrides...))
+}
+
+// GetEventByResource gets the event of the CalDAV resource, followed by its overridden instances
+func (w *webApiService) GetEventByResource(ctx context.Context, name string, userId uint64) ([]repository.Event, error) {
+	resp, err := w.calendarClient.GetEventByResource(ctx, &pb.GetEventByResourceRequest{
+		Name:   name,
+		UserId: userId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, repository.ErrRecordNotFound
+		}
+		return nil, errors.Wrap(err, "failed to get event")
+	}
+
+	return fromProtoEvents(append([]*pb.Event{resp.Event}, resp.Overrides...))
+}
+
+// CreateCalendar adds a new calendar for the user
+func (w *webApiService) CreateCalendar(ctx context.Context, calendar repository.Calendar) (repository.Calendar, error) {
+	if calendar.Visibility == "" {
+		calendar.Visibility = repository.VisibilityPrivate
+	}
+
+	v := validator.New()
+	repository.ValidateCalendar(v, calendar)
+	if !v.Valid() {
+		return repository.Calendar{}, repository.NewValidationError(fmt.Sprintf("failed to validate calendar: %v", v.Errors))
+	}
+
+	resp, err := w.calendarClient.CreateCalendar(ctx, &pb.CreateCalendarRequest{
+		Calendar: toProtoCalendar(calendar),
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return repository.Calendar{}, argumentError(err)
+		}
+		return repository.Calendar{}, errors.Wrap(err, "failed to create calendar")
+	}
+
+	return fromProtoCalendar(resp.Calendar)
+}
+
+// ListCalendars gets the calendars of the user, the default calendar is the first one
+func (w *webApiService) ListCalendars(ctx context.Context, userId uint64) ([]repository.Calendar, error) {
+	resp, err := w.calendarClient.ListCalendars(ctx, &pb.ListCalendarsRequest{UserId: userId})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list calendars")
+	}
+
+	var calendars []repository.Calendar
+	for _, c := range resp.Calendars {
+		calendar, err := fromProtoCalendar(c)
+		if err != nil {
+			return nil, err
+		}
+		calendars = append(calendars, calendar)
+	}
+
+	return calendars, nil
+}
+
+// UpdateCalendar renames the calendar or changes its color, visibility and time zone
+func (w *webApiService) UpdateCalendar(ctx context.Context, calendar repository.Calendar,
+	fields []string) (repository.Calendar, error) {
+	resp, err := w.calendarClient.UpdateCalendar(ctx, &pb.UpdateCalendarRequest{
+		Calendar:   toProtoCalendar(calendar),
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: fields},
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.Calendar{}, repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return repository.Calendar{}, repository.ErrAccessDenied
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return repository.Calendar{}, argumentError(err)
+		}
+		return repository.Calendar{}, errors.Wrap(err, "failed to update calendar")
+	}
+
+	return fromProtoCalendar(resp.Calendar)
+}
+
+// DeleteCalendar deletes the calendar and moves its events to the trash
+func (w *webApiService) DeleteCalendar(ctx context.Context, calendarId string, userId uint64) error {
+	_, err := w.calendarClient.DeleteCalendar(ctx, &pb.DeleteCalendarRequest{
+		CalendarId: calendarId,
+		UserId:     userId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.FailedPrecondition {
+			return repository.ErrDefaultCalendar
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return repository.ErrAccessDenied
+		}
+		return errors.Wrap(err, "failed to delete calendar")
+	}
+
+	return nil
+}
+
+// GrantAccess shares the calendar with the user of the grant, or changes the role of the user
+func (w *webApiService) GrantAccess(ctx context.Context, grant repository.Grant, userId uint64) (repository.Grant, error) {
+	v := validator.New()
+	repository.ValidateGrant(v, grant)
+	if !v.Valid() {
+		return repository.Grant{}, repository.NewValidationError(fmt.Sprintf("failed to validate grant: %v", v.Errors))
+	}
+
+	resp, err := w.calendarClient.GrantAccess(ctx, &pb.GrantAccessRequest{
+		Grant:  &pb.Grant{CalendarId: grant.CalendarId.Hex(), UserId: grant.UserId, Role: grant.Role},
+		UserId: userId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.Grant{}, repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return repository.Grant{}, repository.ErrAccessDenied
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return repository.Grant{}, argumentError(err)
+		}
+		return repository.Grant{}, errors.Wrap(err, "failed to grant access")
+	}
+
+	return fromProtoGrant(resp.Grant)
+}
+
+// ListGrants gets the users which the calendar is shared with
+func (w *webApiService) ListGrants(ctx context.Context, calendarId string, userId uint64) ([]repository.Grant, error) {
+	resp, err := w.calendarClient.ListGrants(ctx, &pb.ListGrantsRequest{
+		CalendarId: calendarId,
+		UserId:     userId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return nil, repository.ErrAccessDenied
+		}
+		return nil, errors.Wrap(err, "failed to list grants")
+	}
+
+	var grants []repository.Grant
+	for _, g := range resp.Grants {
+		grant, err := fromProtoGrant(g)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+// RevokeAccess stops sharing the calendar with the grantee
+func (w *webApiService) RevokeAccess(ctx context.Context, calendarId string, userId, granteeId uint64) error {
+	_, err := w.calendarClient.RevokeAccess(ctx, &pb.RevokeAccessRequest{
+		CalendarId: calendarId,
+		UserId:     userId,
+		GranteeId:  granteeId,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return repository.ErrRecordNotFound
+		}
+		if status.Code(err) == codes.PermissionDenied {
+			return repository.ErrAccessDenied
+		}
+		return errors.Wrap(err, "failed to revoke access")
+	}
+
+	return nil
+}
+
+// FreeBusy gets the merged busy intervals of the users in the window
+func (w *webApiService) FreeBusy(ctx context.Context, userId uint64, userIds []uint64,
+	from, to time.Time) ([]repository.FreeBusy, error) {
+	v := validator.New()
+	repository.ValidateFreeBusy(v, userIds, from, to)
+	if !v.Valid() {
+		return nil, repository.NewValidationError(fmt.Sprintf("failed to validate free/busy: %v", v.Errors))
+	}
+
+	resp, err := w.calendarClient.FreeBusy(ctx, &pb.FreeBusyRequest{
+		UserId:  userId,
+		UserIds: userIds,
+		From:    timestamppb.New(from),
+		To:      timestamppb.New(to),
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, argumentError(err)
+		}
+		return nil, errors.Wrap(err, "failed to get free/busy")
+	}
+
+	freeBusy := make([]repository.FreeBusy, 0, len(resp.Users))
+	for _, user := range resp.Users {
+		busy := make([]repository.Interval, 0, len(user.Busy))
+		for _, interval := range user.Busy {
+			busy = append(busy, repository.Interval{Start: interval.Start.AsTime(), End: interval.End.AsTime()})
+		}
+		freeBusy = append(freeBusy, repository.FreeBusy{UserId: user.UserId, Busy: busy, Err: user.Err})
+	}
+	return freeBusy, nil
+}
+
+// FindMeetingSlots gets the ranked slots which the user and the other users are all free in
+func (w *webApiService) FindMeetingSlots(ctx context.Context, userId uint64,
+	query repository.SlotQuery) ([]repository.Interval, error) {
+	v := validator.New()
+	repository.ValidateSlotQuery(v, query)
+	if !v.Valid() {
+		return nil, repository.NewValidationError(fmt.Sprintf("failed to validate slot query: %v", v.Errors))
+	}
+
+	req := &pb.FindMeetingSlotsRequest{
+		UserId:     userId,
+		UserIds:    query.UserIds,
+		Duration:   int32(query.Duration),
+		From:       timestamppb.New(query.From),
+		To:         timestamppb.New(query.To),
+		MaxResults: int32(query.MaxResults),
+	}
+	if hours := query.WorkingHours; hours != nil {
+		req.WorkingHours = &pb.WorkingHours{Start: hours.Start, End: hours.End}
+		for _, day := range hours.Days {
+			req.WorkingHours.Days = append(req.WorkingHours.Days, int32(day))
+		}
+	}
+
+	resp, err := w.calendarClient.FindMeetingSlots(ctx, req)
+	if err != nil {
+		if status.Code(err) == codes.PermissionDenied {
+			return nil, repository.ErrAccessDenied
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, argumentError(err)
+		}
+		return nil, errors.Wrap(err, "failed to find meeting slots")
+	}
+
+	slots := make([]repository.Interval, 0, len(resp.Slots))
+	for _, slot := range resp.Slots {
+		slots = append(slots, repository.Interval{Start: slot.Start.AsTime(), End: slot.End.AsTime()})
+	}
+	return slots, nil
+}
+
+// argumentError converts the invalid argument error of the calendar service. The invalid page
+// tokens and recurrence ids have their own errors, the others are the validation errors.
+func argumentError(err error) error {
+	switch message := status.Convert(err).Message(); message {
+	case repository.ErrInvalidPageToken.Error():
+		return repository.ErrInvalidPageToken
+	case repository.ErrNotOccurrence.Error():
+		return repository.ErrNotOccurrence
+	default:
+		return repository.NewValidationError(message)
+	}
+}
+
+// permissionError converts the permission denied error of the calendar service. The attendees
+// are not the organizer, and the other users do not have the access to the calendar.
+func permissionError(err error) error {
+	if status.Convert(err).Message() == repository.ErrNotOrganizer.Error() {
+		return repository.ErrNotOrganizer
+	}
+	return repository.ErrAccessDenied
+}
+
 func (w *webApiService) SignUp(ctx context.Context, user repo.User) (uint64, token.Token, error) {
 	err := user.Set(user.Password)
 	if err != nil {
@@ -184,3 +1055,232 @@ func (w *webApiService) Logout(ctx context.Context, sToken token.Token) error {
 
 	return nil
 }
+
+// toProtoEvent converts the repository event to the calendar service event message
+func toProtoEvent(event repository.Event) *pb.Event {
+	e := &pb.Event{
+		Id:       event.Id.Hex(),
+		UserId:   event.UserId,
+		Name:     event.Name,
+		Details:  event.Details,
+		Start:    timestamppb.New(event.Start),
+		End:      timestamppb.New(event.End),
+		Color:    event.Color,
+		TimeZone: event.TimeZone,
+		AllDay:   event.AllDay,
+		Uid:      event.UID,
+		Resource: event.Resource,
+
+		Transparent: event.Transparent,
+		Tags:        event.Tags,
+		Version:     event.Version,
+	}
+	if !event.CalendarId.IsZero() {
+		e.CalendarId = event.CalendarId.Hex()
+	}
+	if event.RecurrenceId != nil {
+		e.RecurrenceId = timestamppb.New(*event.RecurrenceId)
+	}
+
+	for _, reminder := range event.Reminders {
+		e.Reminders = append(e.Reminders, &pb.Reminder{Minutes: int32(reminder.Minutes), Method: reminder.Method})
+	}
+	for _, attendee := range event.Attendees {
+		e.Attendees = append(e.Attendees, &pb.Attendee{UserId: attendee.UserId, Email: attendee.Email, Status: attendee.Status})
+	}
+
+	if event.Recurrence != nil {
+		e.Recurrence = &pb.Recurrence{Rrule: event.Recurrence.RRule}
+		for _, exdate := range event.Recurrence.ExDates {
+			e.Recurrence.Exdates = append(e.Recurrence.Exdates, timestamppb.New(exdate))
+		}
+		for _, rdate := range event.Recurrence.RDates {
+			e.Recurrence.Rdates = append(e.Recurrence.Rdates, timestamppb.New(rdate))
+		}
+	}
+	return e
+}
+
+// toProtoTarget converts the repository target to the calendar service recurrence scope
+func toProtoTarget(target repository.Target) (pb.RecurrenceScope, *timestamppb.Timestamp) {
+	if target.Scope == repository.AllOccurrences {
+		return pb.RecurrenceScope_SCOPE_ALL, nil
+	}
+	return pb.RecurrenceScope(target.Scope), timestamppb.New(target.RecurrenceId)
+}
+
+// fromProtoEvent converts the calendar service event message to the repository event
+func fromProtoEvent(e *pb.Event) (repository.Event, error) {
+	event := repository.Event{
+		UserId:   e.UserId,
+		Name:     e.Name,
+		Details:  e.Details,
+		Start:    e.Start.AsTime(),
+		End:      e.End.AsTime(),
+		Color:    e.Color,
+		TimeZone: e.TimeZone,
+		AllDay:   e.AllDay,
+		UID:      e.Uid,
+		Resource: e.Resource,
+
+		Transparent: e.Transparent,
+		Tags:        e.Tags,
+		Version:     e.Version,
+	}
+	// The unmodified occurrences of the recurring events have no id
+	var err error
+	if e.Id != "" {
+		event.Id, err = primitive.ObjectIDFromHex(e.Id)
+		if err != nil {
+			return repository.Event{}, errors.Wrap(err, "failed to convert object id")
+		}
+	}
+	if e.CalendarId != "" {
+		event.CalendarId, err = primitive.ObjectIDFromHex(e.CalendarId)
+		if err != nil {
+			return repository.Event{}, errors.Wrap(err, "failed to convert object id")
+		}
+	}
+
+	for _, reminder := range e.Reminders {
+		event.Reminders = append(event.Reminders, repository.Reminder{Minutes: int(reminder.Minutes), Method: reminder.Method})
+	}
+	for _, attendee := range e.Attendees {
+		event.Attendees = append(event.Attendees, repository.Attendee{UserId: attendee.UserId, Email: attendee.Email, Status: attendee.Status})
+	}
+	for _, a := range e.Attachments {
+		attachment, err := fromProtoAttachment(a)
+		if err != nil {
+			return repository.Event{}, err
+		}
+		event.Attachments = append(event.Attachments, attachment)
+	}
+
+	if e.Recurrence != nil {
+		event.Recurrence = &repository.Recurrence{RRule: e.Recurrence.Rrule}
+		for _, exdate := range e.Recurrence.Exdates {
+			event.Recurrence.ExDates = append(event.Recurrence.ExDates, exdate.AsTime())
+		}
+		for _, rdate := range e.Recurrence.Rdates {
+			event.Recurrence.RDates = append(event.Recurrence.RDates, rdate.AsTime())
+		}
+	}
+	if e.RecurringEventId != "" {
+		recurringEventId, err := primitive.ObjectIDFromHex(e.RecurringEventId)
+		if err != nil {
+			return repository.Event{}, errors.Wrap(err, "failed to convert object id")
+		}
+		recurrenceId := e.RecurrenceId.AsTime()
+		event.RecurringEventId, event.RecurrenceId = &recurringEventId, &recurrenceId
+	}
+	if e.DeletedAt != nil {
+		deletedAt := e.DeletedAt.AsTime()
+		event.DeletedAt = &deletedAt
+	}
+	return event, nil
+}
+
+// fromProtoAttachment converts the calendar service attachment message to the repository attachment
+func fromProtoAttachment(a *pb.Attachment) (repository.Attachment, error) {
+	id, err := primitive.ObjectIDFromHex(a.Id)
+	if err != nil {
+		return repository.Attachment{}, errors.Wrap(err, "failed to convert object id")
+	}
+	return repository.Attachment{
+		Id:          id,
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		CreatedAt:   a.CreatedAt.AsTime(),
+	}, nil
+}
+
+// fromProtoHistoryEntry converts the calendar service history entry message to the repository entry
+func fromProtoHistoryEntry(e *pb.HistoryEntry) (repository.HistoryEntry, error) {
+	id, err := primitive.ObjectIDFromHex(e.Id)
+	if err != nil {
+		return repository.HistoryEntry{}, errors.Wrap(err, "failed to convert object id")
+	}
+	eventId, err := primitive.ObjectIDFromHex(e.EventId)
+	if err != nil {
+		return repository.HistoryEntry{}, errors.Wrap(err, "failed to convert object id")
+	}
+	event, err := fromProtoEvent(e.Event)
+	if err != nil {
+		return repository.HistoryEntry{}, err
+	}
+
+	entry := repository.HistoryEntry{
+		Id:        id,
+		EventId:   eventId,
+		UserId:    e.UserId,
+		Action:    e.Action,
+		Event:     event,
+		CreatedAt: e.CreatedAt.AsTime(),
+	}
+	for _, change := range e.Changes {
+		c := repository.Change{Field: change.Field}
+		if change.Before != "" {
+			c.Before = json.RawMessage(change.Before)
+		}
+		if change.After != "" {
+			c.After = json.RawMessage(change.After)
+		}
+		entry.Changes = append(entry.Changes, c)
+	}
+	return entry, nil
+}
+
+// fromProtoEvents converts the calendar service event messages to the repository events
+func fromProtoEvents(events []*pb.Event) ([]repository.Event, error) {
+	var converted []repository.Event
+	for _, e := range events {
+		event, err := fromProtoEvent(e)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, event)
+	}
+	return converted, nil
+}
+
+// toProtoCalendar converts the repository calendar to the calendar service calendar message
+func toProtoCalendar(calendar repository.Calendar) *pb.UserCalendar {
+	return &pb.UserCalendar{
+		Id:         calendar.Id.Hex(),
+		UserId:     calendar.UserId,
+		Name:       calendar.Name,
+		Color:      calendar.Color,
+		Visibility: calendar.Visibility,
+		IsDefault:  calendar.Default,
+		TimeZone:   calendar.TimeZone,
+	}
+}
+
+// fromProtoGrant converts the calendar service grant message to the repository grant
+func fromProtoGrant(g *pb.Grant) (repository.Grant, error) {
+	calendarId, err := primitive.ObjectIDFromHex(g.CalendarId)
+	if err != nil {
+		return repository.Grant{}, errors.Wrap(err, "failed to convert object id")
+	}
+	return repository.Grant{CalendarId: calendarId, UserId: g.UserId, Role: g.Role}, nil
+}
+
+// fromProtoCalendar converts the calendar service calendar message to the repository calendar
+func fromProtoCalendar(c *pb.UserCalendar) (repository.Calendar, error) {
+	objId, err := primitive.ObjectIDFromHex(c.Id)
+	if err != nil {
+		return repository.Calendar{}, errors.Wrap(err, "failed to convert object id")
+	}
+
+	return repository.Calendar{
+		Id:         objId,
+		UserId:     c.UserId,
+		Name:       c.Name,
+		Color:      c.Color,
+		Visibility: c.Visibility,
+		Default:    c.IsDefault,
+		Role:       c.Role,
+		TimeZone:   c.TimeZone,
+	}, nil
+}